@@ -70,6 +70,16 @@ func main() {
 		g.GenerateModel("review_info"),
 		g.GenerateModel("review_reply_info"),
 		g.GenerateModel("review_appeal_info"),
+		g.GenerateModel("review_reply_history"),
+		g.GenerateModel("review_report_info"),
+		g.GenerateModel("review_dimension"),
+		g.GenerateModel("review_export_job"),
+		g.GenerateModel("review_event"),
+		g.GenerateModel("review_vote_info"),
+		g.GenerateModel("review_slot_info"),
+		g.GenerateModel("review_import_info"),
+		g.GenerateModel("store_webhook"),
+		g.GenerateModel("webhook_dead_letter"),
 	)
 	g.Execute()
 }
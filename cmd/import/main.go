@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"review-service/internal/biz"
+	"review-service/internal/conf"
+	"review-service/internal/data"
+	"review-service/internal/logging"
+	"review-service/internal/service"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/file"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// 评价导入工具
+// 把其它平台的历史评价导入到评价表，文件的列见 internal/biz/import.go
+// 同一店铺、同一来源的订单号只导入一次，中断后可以直接重新执行
+// 例: 先只校验，再导入，重复和失败的行写入report.csv
+//
+//	import -conf ../../configs -source meituan -file reviews.csv -dry-run
+//	import -conf ../../configs -source meituan -file reviews.csv -report report.csv
+
+var (
+	flagconf       string
+	flagFile       string
+	flagFormat     string
+	flagSource     string
+	flagStatus     int
+	flagBatch      int
+	flagDryRun     bool
+	flagOpUser     string
+	flagReport     string
+	flagNoAnalysis bool
+)
+
+func init() {
+	flag.StringVar(&flagconf, "conf", "../../configs", "config path, eg: -conf config.yaml")
+	flag.StringVar(&flagFile, "file", "", "file to import, - for stdin")
+	flag.StringVar(&flagFormat, "format", "", "csv or jsonl, detected from the file extension if empty")
+	flag.StringVar(&flagSource, "source", "", "source platform of the reviews")
+	flag.IntVar(&flagStatus, "status", 20, "status of imported reviews, 10 pending or 20 approved")
+	flag.IntVar(&flagBatch, "batch", biz.DefaultImportBatchSize, "reviews per transaction")
+	flag.BoolVar(&flagDryRun, "dry-run", false, "validate only, do not write")
+	flag.StringVar(&flagOpUser, "op-user", "import", "operator recorded in create_by")
+	flag.StringVar(&flagReport, "report", "", "write duplicated and failed rows to this csv file, stdout if empty")
+	flag.BoolVar(&flagNoAnalysis, "no-analysis", false, "do not analyze sentiment and keywords of the content")
+}
+
+func main() {
+	flag.Parse()
+	if flagFile == "" || flagSource == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	// 退出前需要执行清理 释放ID生成器的机器号
+	os.Exit(run())
+}
+
+// run 执行导入 有失败的行时返回1
+func run() int {
+	format := flagFormat
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(flagFile)), ".")
+	}
+	c := config.New(
+		config.WithSource(
+			file.NewSource(flagconf),
+		),
+	)
+	defer c.Close()
+	if err := c.Load(); err != nil {
+		panic(err)
+	}
+	var bc conf.Bootstrap
+	if err := c.Scan(&bc); err != nil {
+		panic(err)
+	}
+	if err := bc.Validate(); err != nil {
+		panic(err)
+	}
+	logger := logging.NewLogger(bc.Log, os.Stderr)
+
+	importer, cleanup, err := newImportUsecase(&bc, logger)
+	if err != nil {
+		panic(err)
+	}
+	defer cleanup()
+
+	var in io.Reader = os.Stdin
+	if flagFile != "-" {
+		f, err := os.Open(flagFile)
+		if err != nil {
+			panic(err)
+		}
+		defer f.Close()
+		in = f
+	}
+	report, err := importer.ImportReviews(context.Background(), format, in, &biz.ImportOptions{
+		Source:    flagSource,
+		Status:    int32(flagStatus),
+		BatchSize: flagBatch,
+		DryRun:    flagDryRun,
+		OpUser:    flagOpUser,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, service.ErrorMessage(err, "en"))
+		return 1
+	}
+	if err := writeReport(report); err != nil {
+		panic(err)
+	}
+	fmt.Fprintf(os.Stderr, "total=%d imported=%d duplicated=%d failed=%d dry_run=%t\n",
+		report.Total, report.Imported, report.Duplicated, report.Failed, flagDryRun)
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// newImportUsecase 导入只需要评价表、评分维度和ID生成器，不启动服务
+// 默认在写入前分析评价内容的情感和关键词，-no-analysis 时跳过
+func newImportUsecase(bc *conf.Bootstrap, logger log.Logger) (*biz.ImportUsecase, func(), error) {
+	var cleanups []func()
+	cleanup := func() {
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cleanups[i]()
+		}
+	}
+	db, dbCleanup, err := data.NewDB(bc.Data, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanups = append(cleanups, dbCleanup)
+	rdb, rdbCleanup, err := data.NewRedisClient(bc.Data, logger)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	cleanups = append(cleanups, rdbCleanup)
+	es, esCleanup, err := data.NewESClient(bc.Elasticsearch)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	cleanups = append(cleanups, esCleanup)
+	idgen, idCleanup, err := data.NewIDGenerator(bc.Snowflake, rdb, logger)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	cleanups = append(cleanups, idCleanup)
+	d, dataCleanup, err := data.NewData(bc.Data, db, es, rdb, idgen, logger)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	cleanups = append(cleanups, dataCleanup)
+	rt := biz.NewRuntime()
+	if flagNoAnalysis {
+		rc := biz.DefaultRuntimeConfig()
+		rc.Features[biz.FeatureAnalysis] = false
+		rt.Store(rc)
+	}
+	repo := data.NewReviewRepo(d, rt, logger)
+	analysis := biz.NewAnalysisUsecase(repo, biz.NewContentAnalyzer(), rt, logger)
+	return biz.NewImportUsecase(repo, data.NewDimensionRepo(d, logger), analysis, logger), cleanup, nil
+}
+
+// writeReport 重复和失败的行 line,external_order_id,result,error
+func writeReport(report *biz.ImportReport) error {
+	var out io.Writer = os.Stdout
+	var f *os.File
+	if flagReport != "" {
+		var err error
+		if f, err = os.Create(flagReport); err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	w := csv.NewWriter(out)
+	if err := w.Write([]string{"line", "external_order_id", "result", "error"}); err != nil {
+		return err
+	}
+	for _, row := range report.Rows {
+		if err := w.Write([]string{strconv.Itoa(row.Line), row.ExternalOrderID, row.Result, service.ErrorMessage(row.Err, "en")}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	if f != nil {
+		return f.Close()
+	}
+	return nil
+}
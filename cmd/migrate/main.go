@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"review-service/internal/conf"
+	"review-service/internal/data"
+	"review-service/internal/data/migrate"
+	"review-service/internal/logging"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/file"
+)
+
+// 表结构迁移工具
+//
+//	migrate -conf ../../configs up          执行到最新版本
+//	migrate -conf ../../configs -to 2 up    执行到指定版本
+//	migrate -conf ../../configs down        回滚最近一个版本
+//	migrate -conf ../../configs -steps 2 down
+//	migrate -conf ../../configs status      查看各版本的执行状态
+
+var (
+	flagconf  string
+	flagTo    int64
+	flagSteps int
+)
+
+func init() {
+	flag.StringVar(&flagconf, "conf", "../../configs", "config path, eg: -conf config.yaml")
+	flag.Int64Var(&flagTo, "to", 0, "target version for up, 0 means latest")
+	flag.IntVar(&flagSteps, "steps", 1, "number of versions to roll back for down")
+}
+
+func main() {
+	flag.Parse()
+	c := config.New(
+		config.WithSource(
+			file.NewSource(flagconf),
+		),
+	)
+	defer c.Close()
+	if err := c.Load(); err != nil {
+		panic(err)
+	}
+	var bc conf.Bootstrap
+	if err := c.Scan(&bc); err != nil {
+		panic(err)
+	}
+	if err := bc.Data.Validate(); err != nil {
+		panic(err)
+	}
+	// 由这里执行迁移，不需要再自动迁移；迁移只在主库上执行
+	bc.Data.Database.AutoMigrate = false
+	bc.Data.Database.Replicas = nil
+
+	logger := logging.NewLogger(bc.Log, os.Stdout)
+	db, cleanup, err := data.NewDB(bc.Data, logger)
+	if err != nil {
+		panic(err)
+	}
+	defer cleanup()
+	m, err := migrate.New(db, logger)
+	if err != nil {
+		panic(err)
+	}
+
+	ctx := context.Background()
+	switch flag.Arg(0) {
+	case "up":
+		err = m.Up(ctx, flagTo)
+	case "down":
+		err = m.Down(ctx, flagSteps)
+	case "status":
+		var list []*migrate.Status
+		list, err = m.Status(ctx)
+		for _, s := range list {
+			applied := "pending"
+			if s.Applied {
+				applied = "applied at " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d %-32s %s\n", s.Version, s.Name, applied)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, "usage: migrate [-conf path] [-to version] [-steps n] up|down|status")
+		os.Exit(2)
+	}
+	if errors.Is(err, migrate.ErrNoChange) {
+		fmt.Println("no change")
+		return
+	}
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"review-service/internal/conf"
+	"review-service/internal/data"
+	"review-service/internal/logging"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/file"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// 评价表分表迁移工具
+// 调整分表数时把数据迁到新的分表，迁移期间需要停止写入评价
+// 例: 从不分表迁到8张分表
+//
+//	reshard -conf ../../configs -from 1 -to 8
+//
+// 迁移完成后把配置中的 data.sharding.tables 改为8，从不分表迁移时按输出设置 legacy_max_id
+//
+// 升级前已经完成迁移的部署，需要按配置中的分表数和 legacy_max_id 补写旧评价的槽位
+//
+//	reshard -conf ../../configs -backfill
+
+var (
+	flagconf  string
+	flagFrom  int64
+	flagTo    int64
+	flagBatch int
+	flagFill  bool
+)
+
+func init() {
+	flag.StringVar(&flagconf, "conf", "../../configs", "config path, eg: -conf config.yaml")
+	flag.Int64Var(&flagFrom, "from", 1, "current number of review tables, 1 means not sharded")
+	flag.Int64Var(&flagTo, "to", 1, "target number of review tables, must be a power of 2")
+	flag.IntVar(&flagBatch, "batch", 500, "rows per batch")
+	flag.BoolVar(&flagFill, "backfill", false, "record slots of reviews created before sharding, using the configured tables and legacy_max_id")
+}
+
+func main() {
+	flag.Parse()
+	c := config.New(
+		config.WithSource(
+			file.NewSource(flagconf),
+		),
+	)
+	defer c.Close()
+	if err := c.Load(); err != nil {
+		panic(err)
+	}
+	var bc conf.Bootstrap
+	if err := c.Scan(&bc); err != nil {
+		panic(err)
+	}
+	// 用目标分表数校验配置
+	if bc.Data.Sharding == nil {
+		bc.Data.Sharding = &conf.Data_Sharding{}
+	}
+	if !flagFill {
+		bc.Data.Sharding.Tables = int32(flagTo)
+	}
+	if err := bc.Data.Validate(); err != nil {
+		panic(err)
+	}
+	if flagFrom < 1 || flagFrom&(flagFrom-1) != 0 {
+		panic(fmt.Errorf("invalid from %d", flagFrom))
+	}
+	if flagBatch <= 0 {
+		panic(fmt.Errorf("invalid batch %d", flagBatch))
+	}
+	key := bc.Data.Sharding.GetKey()
+	if key == "" {
+		key = data.ShardKeyUserID
+	}
+
+	logger := logging.NewLogger(bc.Log, os.Stdout)
+	db, cleanup, err := data.NewDB(bc.Data, logger)
+	if err != nil {
+		panic(err)
+	}
+	defer cleanup()
+	if flagFill {
+		total, err := data.BackfillReviewSlots(context.Background(), db, key,
+			int64(bc.Data.Sharding.GetTables()), bc.Data.Sharding.GetLegacyMaxId(), flagBatch, logger)
+		if err != nil {
+			panic(err)
+		}
+		log.NewHelper(logger).Infow("msg", "backfill done", "total", total)
+		return
+	}
+	legacyMaxID, err := data.Rebalance(context.Background(), db, key, flagFrom, flagTo, flagBatch, logger)
+	if err != nil {
+		panic(err)
+	}
+	log.NewHelper(logger).Infow("msg", "rebalance done", "from", flagFrom, "to", flagTo, "key", key)
+	if flagFrom <= 1 {
+		fmt.Printf("set data.sharding.legacy_max_id to %d\n", legacyMaxID)
+	}
+}
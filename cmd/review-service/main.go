@@ -4,8 +4,11 @@ import (
 	"flag"
 	"os"
 
+	"review-service/internal/biz"
 	"review-service/internal/conf"
-	"review-service/pkg/snowflake"
+	"review-service/internal/logging"
+	"review-service/internal/server"
+	"review-service/pkg/consulconfig"
 
 	"github.com/go-kratos/kratos/v2"
 	"github.com/go-kratos/kratos/v2/config"
@@ -35,7 +38,7 @@ func init() {
 	flag.StringVar(&flagconf, "conf", "../../configs", "config path, eg: -conf config.yaml")
 }
 
-func newApp(logger log.Logger, r registry.Registrar, gs *grpc.Server, hs *http.Server) *kratos.App {
+func newApp(logger log.Logger, r registry.Registrar, gs *grpc.Server, hs *http.Server, js *server.JobServer, hss *server.HealthServer) *kratos.App {
 	return kratos.New(
 		kratos.ID(id),
 		kratos.Name(Name),
@@ -45,6 +48,8 @@ func newApp(logger log.Logger, r registry.Registrar, gs *grpc.Server, hs *http.S
 		kratos.Server(
 			gs,
 			hs,
+			js,
+			hss,
 		),
 		kratos.Registrar(r),
 	)
@@ -52,44 +57,68 @@ func newApp(logger log.Logger, r registry.Registrar, gs *grpc.Server, hs *http.S
 
 func main() {
 	flag.Parse()
-	logger := log.With(log.NewStdLogger(os.Stdout),
-		"ts", log.DefaultTimestamp,
-		"caller", log.DefaultCaller,
-		"service.id", id,
-		"service.name", Name,
-		"service.version", Version,
-		"trace.id", tracing.TraceID(),
-		"span.id", tracing.SpanID(),
-	)
 	c := config.New(
 		config.WithSource(
 			file.NewSource(flagconf),
 		),
 	)
-	defer c.Close()
-
 	if err := c.Load(); err != nil {
 		panic(err)
 	}
+	// 解析registry.yaml中的配置信息
+	var rc conf.Registry
+	if err := c.Scan(&rc); err != nil {
+		panic(err)
+	}
+	// 配置了consul KV时合并本地配置和consul中的配置 consul中的配置优先
+	if key := rc.GetConsul().GetConfigKey(); key != "" {
+		c.Close()
+		client, err := server.NewConsulClient(&rc)
+		if err != nil {
+			panic(err)
+		}
+		c = config.New(
+			config.WithSource(
+				file.NewSource(flagconf),
+				consulconfig.NewSource(client, key),
+			),
+		)
+		if err := c.Load(); err != nil {
+			panic(err)
+		}
+	}
+	defer c.Close()
 
 	var bc conf.Bootstrap
 	if err := c.Scan(&bc); err != nil {
 		panic(err)
 	}
-	// 解析registry.yaml中的配置信息
-	var rc conf.Registry
-	if err := c.Scan(&rc); err != nil {
+	if err := bc.Validate(); err != nil {
 		panic(err)
 	}
-	// 初始化snowflake
-	// bc.Snowflake.StartTime
-	if err := snowflake.Init(
-		bc.Snowflake.StartTime,
-		bc.Snowflake.MachineId,
-	); err != nil {
+	// 日志级别和格式由配置决定
+	logger := log.With(logging.NewLogger(bc.Log, os.Stdout),
+		"ts", log.DefaultTimestamp,
+		"caller", log.DefaultCaller,
+		"service.id", id,
+		"service.name", Name,
+		"service.version", Version,
+		"trace.id", tracing.TraceID(),
+		"span.id", tracing.SpanID(),
+	)
+	// 初始化链路追踪
+	shutdown, err := setTracerProvider(bc.Trace)
+	if err != nil {
 		panic(err)
 	}
-	app, cleanup, err := wireApp(bc.Server, &rc, bc.Data, logger)
+	defer shutdown()
+	// 运行时配置 修改后通过 Watch 热更新
+	rt := biz.NewRuntime()
+	rt.Store(runtimeConfig(bc.Runtime))
+	if err := watchRuntime(c, rt, logger); err != nil {
+		log.NewHelper(logger).Warnw("msg", "runtime config is not watched", "err", err)
+	}
+	app, cleanup, err := wireApp(bc.Server, &rc, bc.Data, bc.Snowflake, bc.Elasticsearch, bc.Export, bc.Webhook, rt, logger)
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"review-service/internal/biz"
+	"review-service/internal/conf"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// runtimeConfig 把配置中的 runtime 转换为业务使用的运行时配置 未配置的项使用默认值
+func runtimeConfig(rc *conf.Runtime) *biz.RuntimeConfig {
+	c := biz.DefaultRuntimeConfig()
+	if ttl := rc.GetListCacheTtl().AsDuration(); ttl > 0 {
+		c.ListCacheTTL = ttl
+	}
+	if ttl := rc.GetDashboardCacheTtl().AsDuration(); ttl > 0 {
+		c.DashboardCacheTTL = ttl
+	}
+	c.CreateReviewPerMinute = int(rc.GetCreateReviewPerMinute())
+	if n := rc.GetReportHideThreshold(); n > 0 {
+		c.ReportHideThreshold = int(n)
+	}
+	if n := rc.GetMaxThreadLength(); n > 0 {
+		c.MaxThreadLength = int(n)
+	}
+	c.ReplyBlockedWords = rc.GetReplyBlockedWords()
+	for k, v := range rc.GetFeatures() {
+		c.Features[k] = v
+	}
+	return c
+}
+
+// watchRuntime 监听 runtime 配置的变化 新配置校验失败时保留原来的配置
+func watchRuntime(c config.Config, rt *biz.Runtime, logger log.Logger) error {
+	helper := log.NewHelper(logger)
+	return c.Watch("runtime", func(key string, v config.Value) {
+		var rc conf.Runtime
+		if err := v.Scan(&rc); err != nil {
+			helper.Errorw("msg", "scan runtime config failed", "err", err)
+			return
+		}
+		if err := rc.Validate(); err != nil {
+			helper.Errorw("msg", "invalid runtime config, keep the previous one", "err", err)
+			return
+		}
+		rt.Store(runtimeConfig(&rc))
+		helper.Infow("msg", "runtime config reloaded")
+	})
+}
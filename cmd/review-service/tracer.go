@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"review-service/internal/conf"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+// setTracerProvider 根据配置初始化全局的TracerProvider
+// 没有配置导出方式时不做任何处理，tracing中间件和各个客户端的埋点使用默认的no-op实现
+// 返回的函数在退出时调用，把还没导出的span刷出去
+func setTracerProvider(c *conf.Trace) (func(), error) {
+	var (
+		exporter tracesdk.SpanExporter
+		err      error
+	)
+	switch c.GetExporter() {
+	case "":
+		return func() {}, nil
+	case "otlp":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(c.GetEndpoint())}
+		if c.GetInsecure() {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(context.Background(), opts...)
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unsupported trace exporter:%s", c.GetExporter())
+	}
+	if err != nil {
+		return nil, err
+	}
+	ratio := c.GetSampleRatio()
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+	tp := tracesdk.NewTracerProvider(
+		tracesdk.WithSampler(tracesdk.ParentBased(tracesdk.TraceIDRatioBased(ratio))),
+		tracesdk.WithBatcher(exporter),
+		tracesdk.WithResource(resource.NewSchemaless(
+			semconv.ServiceName(Name),
+			semconv.ServiceVersion(Version),
+			semconv.ServiceInstanceID(id),
+		)),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = tp.Shutdown(ctx)
+	}, nil
+}
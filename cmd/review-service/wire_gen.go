@@ -23,23 +23,98 @@ import (
 // Injectors from wire.go:
 
 // wireApp init kratos application.
-func wireApp(confServer *conf.Server, registry *conf.Registry, confData *conf.Data, logger log.Logger) (*kratos.App, func(), error) {
-	registrar := server.NewRegistrar(registry)
-	db, err := data.NewDB(confData)
+func wireApp(confServer *conf.Server, registry *conf.Registry, confData *conf.Data, snowflake *conf.Snowflake, elasticsearch *conf.Elasticsearch, export *conf.Export, webhook *conf.Webhook, runtime *biz.Runtime, logger log.Logger) (*kratos.App, func(), error) {
+	client, err := server.NewConsulClient(registry)
 	if err != nil {
 		return nil, nil, err
 	}
-	dataData, cleanup, err := data.NewData(db, logger)
+	registrar := server.NewRegistrar(client)
+	db, cleanup, err := data.NewDB(confData, logger)
 	if err != nil {
 		return nil, nil, err
 	}
-	reviewRepo := data.NewReviewRepo(dataData, logger)
-	reviewUsecase := biz.NewReviewUsecase(reviewRepo, logger)
-	reviewService := service.NewReviewService(reviewUsecase)
-	grpcServer := server.NewGRPCServer(confServer, reviewService, logger)
-	httpServer := server.NewHTTPServer(confServer, reviewService, logger)
-	app := newApp(logger, registrar, grpcServer, httpServer)
+	typedClient, cleanup2, err := data.NewESClient(elasticsearch)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	redisClient, cleanup3, err := data.NewRedisClient(confData, logger)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	idGenerator, cleanup4, err := data.NewIDGenerator(snowflake, redisClient, logger)
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	dataData, cleanup5, err := data.NewData(confData, db, typedClient, redisClient, idGenerator, logger)
+	if err != nil {
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	reviewRepo := data.NewReviewRepo(dataData, runtime, logger)
+	userRepo := data.NewUserRepo(logger)
+	dimensionRepo := data.NewDimensionRepo(dataData, logger)
+	replyModerator := biz.NewReplyModerator(runtime)
+	rateLimiter := data.NewRateLimiter(dataData)
+	webhookRepo := data.NewWebhookRepo(dataData, logger)
+	webhookSender := data.NewWebhookSender(webhook, logger)
+	webhookUsecase := biz.NewWebhookUsecase(webhookRepo, webhookSender, idGenerator, logger)
+	contentAnalyzer := biz.NewContentAnalyzer()
+	analysisUsecase := biz.NewAnalysisUsecase(reviewRepo, contentAnalyzer, runtime, logger)
+	reviewUsecase := biz.NewReviewUsecase(reviewRepo, userRepo, dimensionRepo, replyModerator, rateLimiter, webhookUsecase, analysisUsecase, idGenerator, runtime, logger)
+	exportRepo := data.NewExportRepo(dataData, logger)
+	exportStorage, err := data.NewExportStorage(export)
+	if err != nil {
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	exportUsecase := biz.NewExportUsecase(exportRepo, exportStorage, idGenerator, logger)
+	importUsecase := biz.NewImportUsecase(reviewRepo, dimensionRepo, analysisUsecase, logger)
+	eventRepo := data.NewEventRepo(dataData, logger)
+	watchUsecase := biz.NewWatchUsecase(eventRepo, logger)
+	reviewService := service.NewReviewService(reviewUsecase, webhookUsecase, exportUsecase, importUsecase, watchUsecase, logger)
+	callerAuth, err := service.NewCallerAuth(confServer, logger)
+	if err != nil {
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	grpcServer := server.NewGRPCServer(confServer, reviewService, callerAuth, logger)
+	graphQLServer, err := service.NewGraphQLServer(reviewService, confServer, logger)
+	if err != nil {
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	httpServer := server.NewHTTPServer(confServer, export, reviewService, graphQLServer, callerAuth, logger)
+	jobServer := server.NewJobServer(reviewUsecase, webhookUsecase, exportUsecase, watchUsecase, analysisUsecase, logger)
+	healthRepo := data.NewHealthRepo(dataData)
+	healthUsecase := biz.NewHealthUsecase(healthRepo)
+	healthServer := server.NewHealthServer(healthUsecase, httpServer, grpcServer, client, logger)
+	app := newApp(logger, registrar, grpcServer, httpServer, jobServer, healthServer)
 	return app, func() {
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
 		cleanup()
 	}, nil
 }
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"net/http"
+	"sync/atomic"
+
+	"review-service/pkg/webhook"
+)
+
+// 本地调试用的webhook接收方 校验签名后打印收到的事件
+//
+//	webhook-receiver -addr :8088 -secret <密钥>
+//	webhook-receiver -addr :8088 -secret <密钥> -fail 3   前3次请求返回500，用于验证重试和死信
+//
+// 在评价服务的配置中打开 webhook.allow_private_network，再把店铺的webhook地址设置为 http://127.0.0.1:8088/ 即可
+
+var (
+	flagAddr   string
+	flagSecret string
+	flagFail   int64
+)
+
+func init() {
+	flag.StringVar(&flagAddr, "addr", ":8088", "listen address")
+	flag.StringVar(&flagSecret, "secret", "", "webhook secret, empty to skip signature verification")
+	flag.Int64Var(&flagFail, "fail", 0, "number of requests to fail with 500 before succeeding")
+}
+
+func main() {
+	flag.Parse()
+	var received int64
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if flagSecret != "" {
+			if err := webhook.Verify(flagSecret, r.Header, body, 0); err != nil {
+				log.Printf("reject delivery=%s err=%v", r.Header.Get(webhook.HeaderDelivery), err)
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+		}
+		n := atomic.AddInt64(&received, 1)
+		if n <= flagFail {
+			log.Printf("fail #%d delivery=%s", n, r.Header.Get(webhook.HeaderDelivery))
+			http.Error(w, "simulated failure", http.StatusInternalServerError)
+			return
+		}
+		var e webhook.Event
+		if err := json.Unmarshal(body, &e); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("event=%s delivery=%s store_id=%d body=%s", e.Type, e.ID, e.StoreID, body)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	log.Printf("listening on %s", flagAddr)
+	log.Fatal(http.ListenAndServe(flagAddr, nil))
+}
@@ -0,0 +1,232 @@
+package biz
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"review-service/internal/data/model"
+	"review-service/pkg/lexicon"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// 评价内容分析
+// 评价创建后在后台分析内容的情感倾向和关键词，结果合并到 review_info.ext_json，同时写入ES的 review_stats 索引
+// 商家可以按情感筛选评价，查看差评中出现最多的关键词(吐槽点)
+// 分析失败或队列已满时只记录日志，不影响评价的创建，这些评价没有情感和关键词
+// 导入的历史评价在写入前同步分析，运行时配置中关闭 analysis 功能后不再分析
+
+// 评价内容的情感倾向
+const (
+	SentimentPositive = "positive" // 正面
+	SentimentNeutral  = "neutral"  // 中性
+	SentimentNegative = "negative" // 负面
+)
+
+// MaxKeywords 每条评价最多提取的关键词数
+const MaxKeywords = 5
+
+// 店铺吐槽关键词的返回条数
+const (
+	DefaultComplaintKeywords = 10
+	MaxComplaintKeywords     = 50
+)
+
+const (
+	sentimentThreshold  = 0.2              // 情感分的绝对值小于该值时为中性
+	analysisQueueSize   = 1024             // 等待分析的评价数 超过时丢弃新的评价
+	analysisWorkers     = 2                // 后台分析的goroutine数
+	analysisSaveTimeout = 10 * time.Second // 保存一条分析结果的超时时间
+	analysisStopTimeout = 5 * time.Second  // 服务停止时等待队列中的评价分析完的时间
+)
+
+// ContentAnalysis 内容分析的结果
+type ContentAnalysis struct {
+	Sentiment      string
+	SentimentScore float64 // (-1, 1) 大于0为正面
+	Keywords       []string
+}
+
+// KeywordCount 关键词和出现该关键词的评价数
+type KeywordCount struct {
+	Keyword string
+	Count   int64
+}
+
+// ContentAnalyzer 评价内容分析的钩子
+// 接入NLP服务时替换这里的实现即可
+type ContentAnalyzer interface {
+	Analyze(ctx context.Context, content string) (*ContentAnalysis, error)
+}
+
+// lexiconAnalyzer 默认的分析实现 基于词典离线分析中英文内容，见 pkg/lexicon
+type lexiconAnalyzer struct {
+	a *lexicon.Analyzer
+}
+
+// NewContentAnalyzer 默认的内容分析钩子 分词词典在第一次分析时加载
+func NewContentAnalyzer() ContentAnalyzer {
+	return lexiconAnalyzer{a: lexicon.New(MaxKeywords)}
+}
+
+func (l lexiconAnalyzer) Analyze(_ context.Context, content string) (*ContentAnalysis, error) {
+	ret, err := l.a.Analyze(content)
+	if err != nil {
+		return nil, err
+	}
+	return &ContentAnalysis{
+		Sentiment:      SentimentOf(ret.Score),
+		SentimentScore: ret.Score,
+		Keywords:       ret.Keywords,
+	}, nil
+}
+
+// SentimentOf 情感分对应的情感倾向
+func SentimentOf(score float64) string {
+	switch {
+	case score >= sentimentThreshold:
+		return SentimentPositive
+	case score <= -sentimentThreshold:
+		return SentimentNegative
+	}
+	return SentimentNeutral
+}
+
+// ReviewAnalyzer 提交评价的内容分析 不阻塞调用方，也不返回分析的结果
+type ReviewAnalyzer interface {
+	AnalyzeReview(ctx context.Context, review *model.ReviewInfo)
+}
+
+type analysisTask struct {
+	ctx    context.Context
+	review *model.ReviewInfo
+}
+
+type AnalysisUsecase struct {
+	repo     ReviewRepo
+	analyzer ContentAnalyzer
+	runtime  *Runtime
+	log      *log.Helper
+	queue    chan analysisTask
+
+	mu      sync.Mutex
+	started bool
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+func NewAnalysisUsecase(repo ReviewRepo, analyzer ContentAnalyzer, runtime *Runtime, logger log.Logger) *AnalysisUsecase {
+	return &AnalysisUsecase{
+		repo:     repo,
+		analyzer: analyzer,
+		runtime:  runtime,
+		log:      log.NewHelper(logger),
+		queue:    make(chan analysisTask, analysisQueueSize),
+	}
+}
+
+// Enabled 是否分析评价内容
+func (uc *AnalysisUsecase) Enabled() bool {
+	return uc.runtime.Load().Enabled(FeatureAnalysis)
+}
+
+// Analyze 同步分析一段内容 功能关闭时返回nil
+func (uc *AnalysisUsecase) Analyze(ctx context.Context, content string) (*ContentAnalysis, error) {
+	if !uc.Enabled() {
+		return nil, nil
+	}
+	return uc.analyzer.Analyze(ctx, content)
+}
+
+// AnalyzeReview 评价放入分析队列 第一次调用时启动后台分析，服务停止后不再接收新的评价
+func (uc *AnalysisUsecase) AnalyzeReview(ctx context.Context, review *model.ReviewInfo) {
+	if !uc.Enabled() {
+		return
+	}
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if uc.stopped {
+		uc.log.WithContext(ctx).Warnw("msg", "[biz] AnalyzeReview after stop", "review_id", review.ReviewID)
+		return
+	}
+	if !uc.started {
+		uc.started = true
+		for i := 0; i < analysisWorkers; i++ {
+			uc.wg.Add(1)
+			go uc.worker()
+		}
+	}
+	// 分析在请求结束后进行 保留链路信息，不跟随请求取消
+	select {
+	case uc.queue <- analysisTask{ctx: context.WithoutCancel(ctx), review: review}:
+	default:
+		uc.log.WithContext(ctx).Warnw("msg", "[biz] AnalyzeReview queue full", "review_id", review.ReviewID)
+	}
+}
+
+// Stop 停止分析 等待队列中的评价分析完，超时后剩下的评价不再分析
+func (uc *AnalysisUsecase) Stop(ctx context.Context) {
+	uc.mu.Lock()
+	if uc.stopped {
+		uc.mu.Unlock()
+		return
+	}
+	uc.stopped = true
+	close(uc.queue)
+	uc.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		uc.wg.Wait()
+		close(done)
+	}()
+	ctx, cancel := context.WithTimeout(ctx, analysisStopTimeout)
+	defer cancel()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		uc.log.Warnw("msg", "[biz] analysis stop timeout, some reviews are not analyzed")
+	}
+}
+
+func (uc *AnalysisUsecase) worker() {
+	defer uc.wg.Done()
+	for t := range uc.queue {
+		uc.analyze(t.ctx, t.review)
+	}
+}
+
+func (uc *AnalysisUsecase) analyze(ctx context.Context, review *model.ReviewInfo) {
+	ret, err := uc.analyzer.Analyze(ctx, review.Content)
+	if err != nil {
+		uc.log.WithContext(ctx).Errorw("msg", "[biz] AnalyzeReview fail", "review_id", review.ReviewID, "err", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, analysisSaveTimeout)
+	defer cancel()
+	if err := uc.repo.SaveReviewAnalysis(ctx, review.ReviewID, ret); err != nil {
+		uc.log.WithContext(ctx).Errorw("msg", "[biz] SaveReviewAnalysis fail", "review_id", review.ReviewID, "err", err)
+		return
+	}
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] AnalyzeReview", "review_id", review.ReviewID, "sentiment", ret.Sentiment, "keywords", ret.Keywords)
+}
+
+// SetAnalysis 分析结果写入扩展信息
+func (ext *ReviewExt) SetAnalysis(a *ContentAnalysis) {
+	ext.Sentiment = a.Sentiment
+	ext.SentimentScore = a.SentimentScore
+	ext.Keywords = a.Keywords
+}
+
+// GetComplaintKeywords 店铺负面评价中出现最多的关键词 按出现的评价数倒序
+func (uc *ReviewUsecase) GetComplaintKeywords(ctx context.Context, storeID int64, size int) ([]*KeywordCount, error) {
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] GetComplaintKeywords", "store_id", storeID, "size", size)
+	if size <= 0 {
+		size = DefaultComplaintKeywords
+	}
+	if size > MaxComplaintKeywords {
+		size = MaxComplaintKeywords
+	}
+	return uc.repo.TopKeywords(ctx, storeID, SentimentNegative, size)
+}
@@ -0,0 +1,73 @@
+package biz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+func TestSentimentOf(t *testing.T) {
+	tests := []struct {
+		score float64
+		want  string
+	}{
+		{0, SentimentNeutral},
+		{0.19, SentimentNeutral},
+		{-0.19, SentimentNeutral},
+		{sentimentThreshold, SentimentPositive},
+		{-sentimentThreshold, SentimentNegative},
+		{0.9, SentimentPositive},
+		{-0.9, SentimentNegative},
+	}
+	for _, tt := range tests {
+		if got := SentimentOf(tt.score); got != tt.want {
+			t.Fatalf("SentimentOf(%v) = %q, want %q", tt.score, got, tt.want)
+		}
+	}
+}
+
+// fakeContentAnalyzer 返回固定的情感分
+type fakeContentAnalyzer struct {
+	score float64
+	calls int
+}
+
+func (a *fakeContentAnalyzer) Analyze(ctx context.Context, content string) (*ContentAnalysis, error) {
+	a.calls++
+	return &ContentAnalysis{Sentiment: SentimentOf(a.score), SentimentScore: a.score}, nil
+}
+
+func TestAnalysisUsecaseAnalyze(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		want    string
+	}{
+		{name: "enabled", enabled: true, want: SentimentNegative},
+		{name: "disabled", enabled: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runtime := NewRuntime()
+			c := DefaultRuntimeConfig()
+			c.Features[FeatureAnalysis] = tt.enabled
+			runtime.Store(c)
+			analyzer := &fakeContentAnalyzer{score: -0.5}
+			uc := NewAnalysisUsecase(nil, analyzer, runtime, log.DefaultLogger)
+			ret, err := uc.Analyze(context.Background(), "送餐太慢")
+			if err != nil {
+				t.Fatalf("Analyze: %v", err)
+			}
+			if !tt.enabled {
+				if ret != nil || analyzer.calls != 0 {
+					t.Fatalf("Analyze disabled = %v, calls %d, want nil and no call", ret, analyzer.calls)
+				}
+				return
+			}
+			if ret.Sentiment != tt.want {
+				t.Fatalf("Sentiment = %q, want %q", ret.Sentiment, tt.want)
+			}
+		})
+	}
+}
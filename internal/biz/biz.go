@@ -3,5 +3,7 @@ package biz
 import "github.com/google/wire"
 
 // ProviderSet is biz providers.
-var ProviderSet = wire.NewSet(NewReviewUsecase)
+var ProviderSet = wire.NewSet(NewReviewUsecase, NewReplyModerator, NewHealthUsecase,
+	NewWebhookUsecase, wire.Bind(new(ReviewNotifier), new(*WebhookUsecase)), NewExportUsecase, NewImportUsecase, NewWatchUsecase,
+	NewContentAnalyzer, NewAnalysisUsecase, wire.Bind(new(ReviewAnalyzer), new(*AnalysisUsecase)))
 
@@ -0,0 +1,58 @@
+package biz
+
+import (
+	"context"
+	"time"
+)
+
+// 商家评价看板
+// 按天或按周统计店铺的评价数、平均分、差评数、回复率和平均回复耗时，没有评价的日期也返回数据点
+// 结果缓存在Redis中，缓存有效期由运行时配置 dashboard_cache_ttl 控制
+
+// 统计周期
+const (
+	IntervalDay  = "day"
+	IntervalWeek = "week" // 自然周 从周一开始
+)
+
+// MaxDashboardDays 看板一次最多统计的天数
+const MaxDashboardDays = 366
+
+// NegativeScore 评分不高于该值的评价算作差评
+const NegativeScore = 2
+
+// DashboardPoint 看板中一个统计周期的数据
+type DashboardPoint struct {
+	Date                string  `json:"date"` // 周期的第一天 yyyy-MM-dd
+	ReviewCount         int64   `json:"review_count"`
+	AverageScore        float64 `json:"average_score"`
+	NegativeCount       int64   `json:"negative_count"`
+	ReplyCount          int64   `json:"reply_count"`
+	ReplyRate           float64 `json:"reply_rate"`
+	AverageReplySeconds float64 `json:"average_reply_seconds"`
+}
+
+// GetStoreDashboard 商家评价看板 日期格式为 yyyy-MM-dd
+func (uc *ReviewUsecase) GetStoreDashboard(ctx context.Context, storeID int64, startDate, endDate, interval string) ([]*DashboardPoint, error) {
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] GetStoreDashboard", "store_id", storeID, "start", startDate, "end", endDate, "interval", interval)
+	start, err := time.ParseInLocation(time.DateOnly, startDate, time.Local)
+	if err != nil {
+		return nil, ErrInvalidDateRange.WithArgs(MaxDashboardDays)
+	}
+	end, err := time.ParseInLocation(time.DateOnly, endDate, time.Local)
+	if err != nil {
+		return nil, ErrInvalidDateRange.WithArgs(MaxDashboardDays)
+	}
+	if end.Before(start) || end.Sub(start) >= MaxDashboardDays*24*time.Hour {
+		return nil, ErrInvalidDateRange.WithArgs(MaxDashboardDays)
+	}
+	if interval == "" {
+		interval = IntervalDay
+	}
+	return uc.repo.GetStoreDashboard(ctx, &DashboardParam{
+		StoreID:  storeID,
+		Start:    start,
+		End:      end,
+		Interval: interval,
+	})
+}
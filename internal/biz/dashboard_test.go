@@ -0,0 +1,61 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// fakeDashboardRepo 记录看板的查询参数
+type fakeDashboardRepo struct {
+	ReviewRepo
+	param *DashboardParam
+}
+
+func (r *fakeDashboardRepo) GetStoreDashboard(_ context.Context, param *DashboardParam) ([]*DashboardPoint, error) {
+	r.param = param
+	return nil, nil
+}
+
+func TestGetStoreDashboard(t *testing.T) {
+	tests := []struct {
+		name         string
+		start, end   string
+		interval     string
+		wantErr      error
+		wantInterval string
+	}{
+		{name: "one day", start: "2024-01-01", end: "2024-01-01", wantInterval: IntervalDay},
+		{name: "weekly", start: "2024-01-01", end: "2024-03-31", interval: IntervalWeek, wantInterval: IntervalWeek},
+		{name: "max days", start: "2024-01-01", end: "2024-12-31", wantInterval: IntervalDay},
+		{name: "too many days", start: "2024-01-01", end: "2025-01-01", wantErr: ErrInvalidDateRange},
+		{name: "end before start", start: "2024-01-02", end: "2024-01-01", wantErr: ErrInvalidDateRange},
+		{name: "bad start", start: "2024/01/01", end: "2024-01-01", wantErr: ErrInvalidDateRange},
+		{name: "bad end", start: "2024-01-01", end: "", wantErr: ErrInvalidDateRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeDashboardRepo{}
+			uc := NewReviewUsecase(repo, nil, nil, nil, nil, nil, nil, nil, nil, log.DefaultLogger)
+			_, err := uc.GetStoreDashboard(context.Background(), 1, tt.start, tt.end, tt.interval)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetStoreDashboard() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if repo.param != nil {
+					t.Fatal("repo called with invalid range")
+				}
+				return
+			}
+			if repo.param.Interval != tt.wantInterval {
+				t.Fatalf("interval = %s, want %s", repo.param.Interval, tt.wantInterval)
+			}
+			if got := repo.param.End.Format(time.DateOnly); got != tt.end {
+				t.Fatalf("end = %s, want %s", got, tt.end)
+			}
+		})
+	}
+}
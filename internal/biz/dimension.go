@@ -0,0 +1,190 @@
+package biz
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"review-service/internal/data/model"
+)
+
+// 评分维度
+// 每个商品类目可以配置一组评分维度(比如餐饮: 口味、分量；数码: 质量、性价比)，保存在 review_dimension 表中
+// 评价的类目和各维度评分保存在 review_info.ext_json 中，同时写入ES用于统计各维度的平均分
+// 修改类目的维度不影响已有评价中的评分
+
+// MaxDimensions 每个类目最多的评分维度数
+const MaxDimensions = 20
+
+// MaxDimensionNameLen 维度名称的长度上限 与 review_dimension.name 的列定义保持一致
+const MaxDimensionNameLen = 64
+
+var dimensionCodeRe = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+// DimensionRepo 评分维度的存储
+type DimensionRepo interface {
+	// ListDimensions 类目的评分维度 按类目和展示顺序排序
+	ListDimensions(ctx context.Context, categoryIDs ...int64) ([]*model.ReviewDimension, error)
+	// SaveDimensions 整体替换类目的评分维度
+	SaveDimensions(ctx context.Context, categoryID int64, dims []*model.ReviewDimension) error
+}
+
+// ReviewExt review_info.ext_json 中的扩展信息
+type ReviewExt struct {
+	CategoryID      int64            `json:"category_id,omitempty"`
+	DimensionScores map[string]int32 `json:"dimension_scores,omitempty"`
+	// 从其它平台导入的评价 来源平台和来源平台的订单号，导入时按店铺、来源和订单号去重
+	Source          string `json:"source,omitempty"`
+	ExternalOrderID string `json:"external_order_id,omitempty"`
+	// 内容分析的结果 评价创建后在后台写入
+	Sentiment      string   `json:"sentiment,omitempty"`
+	SentimentScore float64  `json:"sentiment_score,omitempty"`
+	Keywords       []string `json:"keywords,omitempty"`
+}
+
+// ParseReviewExt 解析评价的扩展信息 ext_json默认值是空格，解析失败时视为没有扩展信息
+func ParseReviewExt(review *model.ReviewInfo) *ReviewExt {
+	ext := &ReviewExt{}
+	if s := strings.TrimSpace(review.ExtJSON); s != "" {
+		_ = json.Unmarshal([]byte(s), ext)
+	}
+	return ext
+}
+
+// SetReviewExt 把扩展信息写入评价的ext_json
+func SetReviewExt(review *model.ReviewInfo, ext *ReviewExt) error {
+	b, err := json.Marshal(ext)
+	if err != nil {
+		return err
+	}
+	review.ExtJSON = string(b)
+	return nil
+}
+
+// ReviewStats 店铺的评分统计
+type ReviewStats struct {
+	Total               int64
+	AverageScore        float64
+	AverageServiceScore float64
+	AverageExpressScore float64
+	Dimensions          []*DimensionStats
+}
+
+// DimensionStats 维度的平均分
+type DimensionStats struct {
+	Code    string
+	Name    string
+	Average float64
+	Count   int64
+}
+
+// validateDimensionScores 按类目的评分维度校验评价的维度评分
+// 没有配置维度的类目不能提交维度评分，必填的维度必须评分
+func validateDimensionScores(ext *ReviewExt, dims []*model.ReviewDimension) error {
+	defined := make(map[string]*model.ReviewDimension, len(dims))
+	for _, d := range dims {
+		defined[d.Code] = d
+	}
+	for code, score := range ext.DimensionScores {
+		if _, ok := defined[code]; !ok {
+			return ErrUnknownDimension.WithArgs(code)
+		}
+		if score < MinScore || score > MaxScore {
+			return ErrInvalidScore.WithArgs(code, MinScore, MaxScore)
+		}
+	}
+	for _, d := range dims {
+		if _, ok := ext.DimensionScores[d.Code]; d.Required == 1 && !ok {
+			return ErrDimensionRequired.WithArgs(d.Name)
+		}
+	}
+	return nil
+}
+
+// validateDimensions 校验类目的评分维度配置
+func validateDimensions(dims []*model.ReviewDimension) error {
+	if len(dims) > MaxDimensions {
+		return ErrTooManyDimensions.WithArgs(MaxDimensions)
+	}
+	seen := make(map[string]struct{}, len(dims))
+	for _, d := range dims {
+		if !dimensionCodeRe.MatchString(d.Code) {
+			return ErrInvalidDimension.WithArgs(d.Code)
+		}
+		if _, ok := seen[d.Code]; ok {
+			return ErrInvalidDimension.WithArgs(d.Code)
+		}
+		seen[d.Code] = struct{}{}
+		if strings.TrimSpace(d.Name) == "" || utf8.RuneCountInString(d.Name) > MaxDimensionNameLen {
+			return ErrInvalidDimension.WithArgs(d.Code)
+		}
+	}
+	return nil
+}
+
+// SaveRatingDimensions 运营设置类目的评分维度
+func (uc *ReviewUsecase) SaveRatingDimensions(ctx context.Context, categoryID int64, dims []*model.ReviewDimension, opUser string) error {
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] SaveRatingDimensions", "category_id", categoryID, "count", len(dims), "op_user", opUser)
+	if err := validateDimensions(dims); err != nil {
+		return err
+	}
+	for _, d := range dims {
+		d.CategoryID = categoryID
+		d.CreateBy = opUser
+	}
+	if err := uc.dimRepo.SaveDimensions(ctx, categoryID, dims); err != nil {
+		uc.log.WithContext(ctx).Errorw("msg", "[biz] SaveRatingDimensions fail", "err", err)
+		return ErrDBFailed
+	}
+	return nil
+}
+
+// ListRatingDimensions 类目的评分维度
+func (uc *ReviewUsecase) ListRatingDimensions(ctx context.Context, categoryID int64) ([]*model.ReviewDimension, error) {
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] ListRatingDimensions", "category_id", categoryID)
+	dims, err := uc.dimRepo.ListDimensions(ctx, categoryID)
+	if err != nil {
+		uc.log.WithContext(ctx).Errorw("msg", "[biz] ListRatingDimensions fail", "err", err)
+		return nil, ErrDBFailed
+	}
+	return dims, nil
+}
+
+// GetReviewStats 店铺的评分统计 categoryID为0时统计店铺所有类目的维度
+// 不同类目中编码相同的维度合并统计，名称取第一个类目中的名称
+func (uc *ReviewUsecase) GetReviewStats(ctx context.Context, storeID, categoryID int64) (*ReviewStats, error) {
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] GetReviewStats", "store_id", storeID, "category_id", categoryID)
+	categories := []int64{categoryID}
+	if categoryID == 0 {
+		var err error
+		if categories, err = uc.repo.ListStoreCategories(ctx, storeID); err != nil {
+			return nil, err
+		}
+	}
+	var dims []*model.ReviewDimension
+	if len(categories) > 0 {
+		var err error
+		if dims, err = uc.dimRepo.ListDimensions(ctx, categories...); err != nil {
+			uc.log.WithContext(ctx).Errorw("msg", "[biz] GetReviewStats ListDimensions fail", "err", err)
+			return nil, ErrDBFailed
+		}
+	}
+	names := make(map[string]string, len(dims))
+	codes := make([]string, 0, len(dims))
+	for _, d := range dims {
+		if _, ok := names[d.Code]; !ok {
+			names[d.Code] = d.Name
+			codes = append(codes, d.Code)
+		}
+	}
+	stats, err := uc.repo.GetReviewStats(ctx, storeID, categoryID, codes)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range stats.Dimensions {
+		d.Name = names[d.Code]
+	}
+	return stats, nil
+}
@@ -0,0 +1,146 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"review-service/internal/data/model"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// fakeStatsRepo 只实现统计用到的方法
+type fakeStatsRepo struct {
+	ReviewRepo
+	categories []int64
+	codes      []string
+	stats      *ReviewStats
+}
+
+func (r *fakeStatsRepo) ListStoreCategories(_ context.Context, _ int64) ([]int64, error) {
+	return r.categories, nil
+}
+
+func (r *fakeStatsRepo) GetReviewStats(_ context.Context, _, _ int64, codes []string) (*ReviewStats, error) {
+	r.codes = codes
+	return r.stats, nil
+}
+
+// fakeDimRepo 按类目返回评分维度
+type fakeDimRepo struct {
+	dims map[int64][]*model.ReviewDimension
+}
+
+func (r *fakeDimRepo) ListDimensions(_ context.Context, categoryIDs ...int64) ([]*model.ReviewDimension, error) {
+	var ret []*model.ReviewDimension
+	for _, id := range categoryIDs {
+		ret = append(ret, r.dims[id]...)
+	}
+	return ret, nil
+}
+
+func (r *fakeDimRepo) SaveDimensions(context.Context, int64, []*model.ReviewDimension) error {
+	return nil
+}
+
+func TestValidateDimensionScores(t *testing.T) {
+	dims := []*model.ReviewDimension{
+		{Code: "taste", Name: "口味", Required: 1},
+		{Code: "portion", Name: "分量"},
+	}
+	tests := []struct {
+		name   string
+		scores map[string]int32
+		dims   []*model.ReviewDimension
+		want   error
+	}{
+		{name: "all scored", scores: map[string]int32{"taste": 5, "portion": 1}, dims: dims},
+		{name: "optional missing", scores: map[string]int32{"taste": 3}, dims: dims},
+		{name: "required missing", scores: map[string]int32{"portion": 3}, dims: dims, want: ErrDimensionRequired},
+		{name: "unknown code", scores: map[string]int32{"taste": 3, "speed": 3}, dims: dims, want: ErrUnknownDimension},
+		{name: "score out of range", scores: map[string]int32{"taste": 6}, dims: dims, want: ErrInvalidScore},
+		{name: "category without dimensions", scores: map[string]int32{"taste": 3}, want: ErrUnknownDimension},
+		{name: "nothing to score"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateDimensionScores(&ReviewExt{DimensionScores: tt.scores}, tt.dims); !errors.Is(err, tt.want) {
+				t.Fatalf("validateDimensionScores() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateDimensions(t *testing.T) {
+	many := make([]*model.ReviewDimension, MaxDimensions+1)
+	for i := range many {
+		many[i] = &model.ReviewDimension{Code: "d" + strings.Repeat("x", i), Name: "维度"}
+	}
+	tests := []struct {
+		name string
+		dims []*model.ReviewDimension
+		want error
+	}{
+		{name: "valid", dims: []*model.ReviewDimension{{Code: "taste", Name: "口味"}, {Code: "value_2", Name: "性价比"}}},
+		{name: "empty"},
+		{name: "too many", dims: many, want: ErrTooManyDimensions},
+		{name: "upper case code", dims: []*model.ReviewDimension{{Code: "Taste", Name: "口味"}}, want: ErrInvalidDimension},
+		{name: "code too long", dims: []*model.ReviewDimension{{Code: "t" + strings.Repeat("a", 32), Name: "口味"}}, want: ErrInvalidDimension},
+		{name: "duplicate code", dims: []*model.ReviewDimension{{Code: "taste", Name: "口味"}, {Code: "taste", Name: "味道"}}, want: ErrInvalidDimension},
+		{name: "blank name", dims: []*model.ReviewDimension{{Code: "taste", Name: " "}}, want: ErrInvalidDimension},
+		{name: "name too long", dims: []*model.ReviewDimension{{Code: "taste", Name: strings.Repeat("味", MaxDimensionNameLen+1)}}, want: ErrInvalidDimension},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateDimensions(tt.dims); !errors.Is(err, tt.want) {
+				t.Fatalf("validateDimensions() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetReviewStatsDimensions(t *testing.T) {
+	dimRepo := &fakeDimRepo{dims: map[int64][]*model.ReviewDimension{
+		1: {{CategoryID: 1, Code: "taste", Name: "口味"}, {CategoryID: 1, Code: "portion", Name: "分量"}},
+		2: {{CategoryID: 2, Code: "taste", Name: "味道"}, {CategoryID: 2, Code: "speed", Name: "出餐速度"}},
+	}}
+	tests := []struct {
+		name       string
+		categoryID int64
+		categories []int64 // 店铺有评价的类目
+		wantCodes  []string
+		wantNames  []string
+	}{
+		{name: "one category", categoryID: 2, wantCodes: []string{"taste", "speed"}, wantNames: []string{"味道", "出餐速度"}},
+		// 编码相同的维度合并统计 名称取第一个类目中的
+		{name: "all categories", categories: []int64{1, 2}, wantCodes: []string{"taste", "portion", "speed"}, wantNames: []string{"口味", "分量", "出餐速度"}},
+		{name: "store without categories", wantCodes: []string{}, wantNames: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := &ReviewStats{Total: 10}
+			for _, code := range tt.wantCodes {
+				stats.Dimensions = append(stats.Dimensions, &DimensionStats{Code: code, Average: 4, Count: 1})
+			}
+			repo := &fakeStatsRepo{categories: tt.categories, stats: stats}
+			uc := NewReviewUsecase(repo, nil, dimRepo, nil, nil, nil, nil, nil, nil, log.DefaultLogger)
+			got, err := uc.GetReviewStats(context.Background(), 1, tt.categoryID)
+			if err != nil {
+				t.Fatalf("GetReviewStats() error = %v", err)
+			}
+			if !reflect.DeepEqual(repo.codes, tt.wantCodes) {
+				t.Fatalf("codes = %v, want %v", repo.codes, tt.wantCodes)
+			}
+			names := []string{}
+			for _, d := range got.Dimensions {
+				names = append(names, d.Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Fatalf("names = %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package biz
+
+import (
+	"fmt"
+
+	v1 "review-service/api/review/v1"
+)
+
+// Error 业务错误
+// Reason 对应 api/review/v1 中定义的错误原因，service层据此转换成带HTTP/gRPC状态码的API错误
+// Key 是错误消息的key，service层根据调用方的语言返回对应的错误消息，Args 是消息中的参数
+type Error struct {
+	Reason v1.ErrorReason
+	Key    string
+	Args   []interface{}
+}
+
+func newError(reason v1.ErrorReason, key string) *Error {
+	return &Error{Reason: reason, Key: key}
+}
+
+func (e *Error) Error() string {
+	if len(e.Args) == 0 {
+		return fmt.Sprintf("%s: %s", e.Reason, e.Key)
+	}
+	return fmt.Sprintf("%s: %s %v", e.Reason, e.Key, e.Args)
+}
+
+// Is 支持 errors.Is 判断，参数不同的同一种错误视为相等
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Reason == e.Reason && t.Key == e.Key
+}
+
+// WithArgs 返回带消息参数的错误副本，不修改错误目录中的错误
+func (e *Error) WithArgs(args ...interface{}) *Error {
+	return &Error{Reason: e.Reason, Key: e.Key, Args: args}
+}
+
+// 业务错误目录
+// 新增错误时需要同时在 service 层的错误消息表中补充对应的中英文消息
+var (
+	// 服务内部错误
+	ErrDBFailed = newError(v1.ErrorReason_DB_FAILED, "db_failed")
+	ErrGenID    = newError(v1.ErrorReason_INTERNAL_ERROR, "gen_id_failed")
+	ErrInternal = newError(v1.ErrorReason_INTERNAL_ERROR, "internal_error")
+
+	// 资源不存在
+	ErrReviewNotFound  = newError(v1.ErrorReason_NOT_FOUND, "review_not_found")
+	ErrReplyNotFound   = newError(v1.ErrorReason_NOT_FOUND, "reply_not_found")
+	ErrWebhookNotFound = newError(v1.ErrorReason_NOT_FOUND, "webhook_not_found")
+	ErrExportNotFound  = newError(v1.ErrorReason_NOT_FOUND, "export_not_found")
+
+	// 重复操作
+	ErrOrderReviewed   = newError(v1.ErrorReason_ORDER_REVIEWED, "order_reviewed")
+	ErrReviewReplied   = newError(v1.ErrorReason_ALREADY_REPLIED, "review_replied")
+	ErrImportDuplicate = newError(v1.ErrorReason_CONFLICT, "import_duplicate")
+
+	// 调用方身份无效
+	ErrNeedLogin = newError(v1.ErrorReason_NEED_LOGIN, "need_login")
+
+	// 无权操作
+	ErrForbidden  = newError(v1.ErrorReason_FORBIDDEN, "forbidden") // 水平越权
+	ErrVoteSelf   = newError(v1.ErrorReason_FORBIDDEN, "vote_self")
+	ErrReportSelf = newError(v1.ErrorReason_FORBIDDEN, "report_self")
+
+	// 当前状态不允许该操作
+	ErrReviewNotReplied = newError(v1.ErrorReason_INVALID_STATE, "review_not_replied")
+	ErrReplySelf        = newError(v1.ErrorReason_INVALID_STATE, "reply_self")
+	ErrThreadFull       = newError(v1.ErrorReason_INVALID_STATE, "thread_full")
+	ErrThreadRootDelete = newError(v1.ErrorReason_INVALID_STATE, "thread_root_delete")
+	ErrReplyEditExpired = newError(v1.ErrorReason_INVALID_STATE, "reply_edit_expired")
+	ErrAppealAudited    = newError(v1.ErrorReason_INVALID_STATE, "appeal_audited")
+	ErrFeatureDisabled  = newError(v1.ErrorReason_INVALID_STATE, "feature_disabled")
+	ErrWatchSeqExpired  = newError(v1.ErrorReason_INVALID_STATE, "watch_seq_expired")
+
+	// 并发冲突
+	ErrReplyModified  = newError(v1.ErrorReason_CONFLICT, "reply_modified")
+	ErrReviewReported = newError(v1.ErrorReason_CONFLICT, "review_reported")
+
+	// 请求过于频繁
+	ErrRateLimited = newError(v1.ErrorReason_RATE_LIMITED, "rate_limited")
+
+	// 参数不合法
+	ErrInvalidAuthorType   = newError(v1.ErrorReason_INVALID_ARGUMENT, "invalid_author_type")
+	ErrInvalidVote         = newError(v1.ErrorReason_INVALID_ARGUMENT, "invalid_vote")
+	ErrInvalidScore        = newError(v1.ErrorReason_INVALID_ARGUMENT, "invalid_score")
+	ErrContentEmpty        = newError(v1.ErrorReason_INVALID_ARGUMENT, "content_empty")
+	ErrContentTooLong      = newError(v1.ErrorReason_INVALID_ARGUMENT, "content_too_long")
+	ErrMediaTooLong        = newError(v1.ErrorReason_INVALID_ARGUMENT, "media_too_long")
+	ErrTooManyPics         = newError(v1.ErrorReason_INVALID_ARGUMENT, "too_many_pics")
+	ErrTooManyVideos       = newError(v1.ErrorReason_INVALID_ARGUMENT, "too_many_videos")
+	ErrInvalidAppealReason = newError(v1.ErrorReason_INVALID_ARGUMENT, "invalid_appeal_reason")
+	ErrUnknownDimension    = newError(v1.ErrorReason_INVALID_ARGUMENT, "unknown_dimension")
+	ErrDimensionRequired   = newError(v1.ErrorReason_INVALID_ARGUMENT, "dimension_required")
+	ErrInvalidDimension    = newError(v1.ErrorReason_INVALID_ARGUMENT, "invalid_dimension")
+	ErrTooManyDimensions   = newError(v1.ErrorReason_INVALID_ARGUMENT, "too_many_dimensions")
+	ErrInvalidDateRange    = newError(v1.ErrorReason_INVALID_ARGUMENT, "invalid_date_range")
+	ErrInvalidWebhookURL   = newError(v1.ErrorReason_INVALID_ARGUMENT, "invalid_webhook_url")
+	ErrTooManyWebhooks     = newError(v1.ErrorReason_INVALID_ARGUMENT, "too_many_webhooks")
+	ErrInvalidExportFilter = newError(v1.ErrorReason_INVALID_ARGUMENT, "invalid_export_filter")
+	ErrInvalidImportFile   = newError(v1.ErrorReason_INVALID_ARGUMENT, "invalid_import_file")
+	ErrInvalidImportRow    = newError(v1.ErrorReason_INVALID_ARGUMENT, "invalid_import_row")
+	ErrInvalidQueryArg     = newError(v1.ErrorReason_INVALID_ARGUMENT, "invalid_query_arg")
+	ErrQueryTooDeep        = newError(v1.ErrorReason_INVALID_ARGUMENT, "query_too_deep")
+	ErrQueryTooComplex     = newError(v1.ErrorReason_INVALID_ARGUMENT, "query_too_complex")
+)
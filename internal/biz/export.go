@@ -0,0 +1,371 @@
+package biz
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"review-service/internal/data/model"
+	"review-service/pkg/snowflake"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// 评价导出
+// 商家或运营提交导出条件后创建导出任务，任务在后台按主键分批读取评价表(keyset分页，不用offset)，写入CSV、XLSX或JSONL文件
+// 任务状态保存在 review_export_job 表中，多个实例通过抢占任务避免重复执行
+// 执行中的任务定期更新进度，实例退出或崩溃后超过 exportStaleTimeout 没有更新的任务会被重新执行
+
+// 导出文件格式
+const (
+	ExportFormatCSV   = "csv"
+	ExportFormatXLSX  = "xlsx"
+	ExportFormatJSONL = "jsonl"
+)
+
+// 导出任务的状态
+const (
+	ExportStatusPending int32 = 10 // 待执行
+	ExportStatusRunning int32 = 20 // 执行中
+	ExportStatusDone    int32 = 30 // 已完成
+	ExportStatusFailed  int32 = 40 // 失败
+)
+
+// MaxExportRows 一个导出任务最多导出的评价数 XLSX单个工作表最多1048576行
+const MaxExportRows = 1000000
+
+const (
+	exportBatchSize        = 500              // 每批读取的评价数
+	exportMaxRunning       = 2                // 每个实例同时执行的导出任务数
+	exportProgressInterval = 10 * time.Second // 更新任务进度的间隔
+	exportStaleTimeout     = 2 * time.Minute  // 执行中的任务超过该时间没有更新进度时视为中断
+	exportPollLimit        = 10               // 每次轮询最多取出的待执行任务数
+	exportMaxErrorLen      = 512              // 任务失败原因的长度上限
+)
+
+// errExportStopped 服务停止时中断的任务 放回待执行，由其它实例或重启后继续
+var errExportStopped = errors.New("export stopped")
+
+// ExportFilter 导出条件 保存在导出任务的filter中
+type ExportFilter struct {
+	StoreID   int64   `json:"store_id,omitempty"`   // 0表示全部店铺
+	StartDate string  `json:"start_date,omitempty"` // 按创建日期过滤 yyyy-MM-dd，包含结束日期当天
+	EndDate   string  `json:"end_date,omitempty"`
+	Statuses  []int32 `json:"statuses,omitempty"`
+	Scores    []int32 `json:"scores,omitempty"`
+}
+
+// TimeRange 创建时间的范围 [start, end) 未设置的一端为零值
+func (f *ExportFilter) TimeRange() (start, end time.Time) {
+	if f.StartDate != "" {
+		start, _ = time.ParseInLocation(time.DateOnly, f.StartDate, time.Local)
+	}
+	if f.EndDate != "" {
+		end, _ = time.ParseInLocation(time.DateOnly, f.EndDate, time.Local)
+		end = end.AddDate(0, 0, 1)
+	}
+	return start, end
+}
+
+// validate 校验导出条件
+func (f *ExportFilter) validate() error {
+	var start, end time.Time
+	var err error
+	if f.StartDate != "" {
+		if start, err = time.ParseInLocation(time.DateOnly, f.StartDate, time.Local); err != nil {
+			return ErrInvalidExportFilter.WithArgs("start_date")
+		}
+	}
+	if f.EndDate != "" {
+		if end, err = time.ParseInLocation(time.DateOnly, f.EndDate, time.Local); err != nil {
+			return ErrInvalidExportFilter.WithArgs("end_date")
+		}
+	}
+	if !start.IsZero() && !end.IsZero() && end.Before(start) {
+		return ErrInvalidExportFilter.WithArgs("end_date")
+	}
+	for _, s := range f.Statuses {
+		// 10待审核 20审核通过 30审核不通过 40隐藏
+		if s != 10 && s != 20 && s != 30 && s != 40 {
+			return ErrInvalidExportFilter.WithArgs("statuses")
+		}
+	}
+	for _, s := range f.Scores {
+		if s < MinScore || s > MaxScore {
+			return ErrInvalidExportFilter.WithArgs("scores")
+		}
+	}
+	return nil
+}
+
+// ExportRepo 导出任务的存储和评价的分批读取
+type ExportRepo interface {
+	CreateExportJob(ctx context.Context, job *model.ReviewExportJob) error
+	GetExportJob(ctx context.Context, jobID int64) (*model.ReviewExportJob, error)
+	// ListRunnableExportJobs 待执行的任务和中断的任务
+	ListRunnableExportJobs(ctx context.Context, staleBefore time.Time, limit int) ([]int64, error)
+	// ClaimExportJob 抢占任务 返回是否抢占成功
+	ClaimExportJob(ctx context.Context, jobID int64, staleBefore time.Time) (bool, error)
+	UpdateExportJob(ctx context.Context, jobID int64, fields map[string]interface{}) error
+	// ScanReviews 按主键顺序分批读取符合条件的评价 fn返回错误时停止
+	ScanReviews(ctx context.Context, filter *ExportFilter, batchSize int, fn func([]*model.ReviewInfo) error) error
+}
+
+// ExportStorage 导出文件的存储
+type ExportStorage interface {
+	Create(ctx context.Context, key string) (io.WriteCloser, error)
+	Remove(ctx context.Context, key string) error
+	// URL 文件的下载地址
+	URL(key string) string
+}
+
+// ExportJob 导出任务和文件的下载地址
+type ExportJob struct {
+	*model.ReviewExportJob
+	Filter      *ExportFilter
+	DownloadURL string
+}
+
+type ExportUsecase struct {
+	repo    ExportRepo
+	storage ExportStorage
+	idgen   snowflake.IDGenerator
+	log     *log.Helper
+	sem     chan struct{}
+	stop    chan struct{}
+
+	mu      sync.Mutex
+	stopped bool
+	running map[int64]struct{}
+	wg      sync.WaitGroup
+}
+
+func NewExportUsecase(repo ExportRepo, storage ExportStorage, idgen snowflake.IDGenerator, logger log.Logger) *ExportUsecase {
+	return &ExportUsecase{
+		repo:    repo,
+		storage: storage,
+		idgen:   idgen,
+		log:     log.NewHelper(logger),
+		sem:     make(chan struct{}, exportMaxRunning),
+		stop:    make(chan struct{}),
+		running: make(map[int64]struct{}),
+	}
+}
+
+// SubmitExport 提交导出任务 任务在后台执行，通过 GetExportJob 查询进度
+func (uc *ExportUsecase) SubmitExport(ctx context.Context, filter *ExportFilter, format, opUser string) (*ExportJob, error) {
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] SubmitExport", "store_id", filter.StoreID, "format", format, "op_user", opUser)
+	if format != ExportFormatCSV && format != ExportFormatXLSX && format != ExportFormatJSONL {
+		return nil, ErrInvalidExportFilter.WithArgs("format")
+	}
+	if err := filter.validate(); err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(filter)
+	if err != nil {
+		return nil, ErrInternal
+	}
+	id, err := uc.idgen.NextID()
+	if err != nil {
+		uc.log.WithContext(ctx).Errorw("msg", "[biz] SubmitExport gen id fail", "err", err)
+		return nil, ErrGenID
+	}
+	job := &model.ReviewExportJob{
+		JobID:    id,
+		CreateBy: opUser,
+		StoreID:  filter.StoreID,
+		Filter:   string(b),
+		Format:   format,
+		Status:   ExportStatusPending,
+	}
+	if err := uc.repo.CreateExportJob(ctx, job); err != nil {
+		uc.log.WithContext(ctx).Errorw("msg", "[biz] SubmitExport fail", "err", err)
+		return nil, ErrDBFailed
+	}
+	uc.start(ctx, job.JobID)
+	return &ExportJob{ReviewExportJob: job, Filter: filter}, nil
+}
+
+// GetExportJob 导出任务的状态 完成后返回文件的下载地址
+// storeID不为0时只能查看该店铺的导出任务，为0时可以查看全部任务，只有运营可以传0
+func (uc *ExportUsecase) GetExportJob(ctx context.Context, jobID, storeID int64) (*ExportJob, error) {
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] GetExportJob", "job_id", jobID, "store_id", storeID)
+	job, err := uc.repo.GetExportJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if storeID != 0 && job.StoreID != storeID {
+		return nil, ErrForbidden
+	}
+	ret := &ExportJob{ReviewExportJob: job, Filter: &ExportFilter{}}
+	_ = json.Unmarshal([]byte(job.Filter), ret.Filter)
+	if job.Status == ExportStatusDone {
+		ret.DownloadURL = uc.storage.URL(job.FileKey)
+	}
+	return ret, nil
+}
+
+// RunPendingExports 执行待执行的任务和中断的任务 由后台任务定时调用
+func (uc *ExportUsecase) RunPendingExports(ctx context.Context) error {
+	ids, err := uc.repo.ListRunnableExportJobs(ctx, time.Now().Add(-exportStaleTimeout), exportPollLimit)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		uc.start(ctx, id)
+	}
+	return nil
+}
+
+// Stop 停止执行导出任务 执行中的任务放回待执行
+func (uc *ExportUsecase) Stop(ctx context.Context) {
+	uc.mu.Lock()
+	if uc.stopped {
+		uc.mu.Unlock()
+		return
+	}
+	uc.stopped = true
+	close(uc.stop)
+	uc.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		uc.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		uc.log.Warnw("msg", "[biz] export stop timeout")
+	}
+}
+
+// start 在后台执行任务 同一个实例中同一个任务只执行一次
+func (uc *ExportUsecase) start(ctx context.Context, jobID int64) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if _, ok := uc.running[jobID]; ok || uc.stopped {
+		return
+	}
+	uc.running[jobID] = struct{}{}
+	uc.wg.Add(1)
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer func() {
+			uc.mu.Lock()
+			delete(uc.running, jobID)
+			uc.mu.Unlock()
+			uc.wg.Done()
+		}()
+		select {
+		case uc.sem <- struct{}{}:
+			defer func() { <-uc.sem }()
+		case <-uc.stop:
+			return
+		}
+		uc.run(ctx, jobID)
+	}()
+}
+
+func (uc *ExportUsecase) run(ctx context.Context, jobID int64) {
+	ok, err := uc.repo.ClaimExportJob(ctx, jobID, time.Now().Add(-exportStaleTimeout))
+	if err != nil {
+		uc.log.WithContext(ctx).Errorw("msg", "[biz] ClaimExportJob fail", "job_id", jobID, "err", err)
+		return
+	}
+	if !ok {
+		// 已经被其它实例抢占或者已经执行完
+		return
+	}
+	job, err := uc.repo.GetExportJob(ctx, jobID)
+	if err != nil {
+		uc.log.WithContext(ctx).Errorw("msg", "[biz] GetExportJob fail", "job_id", jobID, "err", err)
+		return
+	}
+	uc.log.WithContext(ctx).Infow("msg", "[biz] export start", "job_id", jobID, "store_id", job.StoreID, "format", job.Format)
+	key := exportKey(job)
+	rows, err := uc.export(ctx, job, key)
+	var fields map[string]interface{}
+	switch {
+	case err == nil:
+		fields = map[string]interface{}{"status": ExportStatusDone, "row_count": rows, "file_key": key}
+	case errors.Is(err, errExportStopped):
+		_ = uc.storage.Remove(ctx, key)
+		fields = map[string]interface{}{"status": ExportStatusPending, "row_count": 0}
+	default:
+		uc.log.WithContext(ctx).Errorw("msg", "[biz] export fail", "job_id", jobID, "err", err)
+		_ = uc.storage.Remove(ctx, key)
+		msg := err.Error()
+		if len(msg) > exportMaxErrorLen {
+			msg = msg[:exportMaxErrorLen]
+		}
+		fields = map[string]interface{}{"status": ExportStatusFailed, "row_count": rows, "err_msg": msg}
+	}
+	if err := uc.repo.UpdateExportJob(ctx, jobID, fields); err != nil {
+		uc.log.WithContext(ctx).Errorw("msg", "[biz] UpdateExportJob fail", "job_id", jobID, "err", err)
+	}
+	uc.log.WithContext(ctx).Infow("msg", "[biz] export finish", "job_id", jobID, "rows", rows, "status", fields["status"])
+}
+
+// export 分批读取评价写入文件 返回导出的行数
+func (uc *ExportUsecase) export(ctx context.Context, job *model.ReviewExportJob, key string) (int32, error) {
+	filter := &ExportFilter{}
+	if err := json.Unmarshal([]byte(job.Filter), filter); err != nil {
+		return 0, fmt.Errorf("invalid filter: %w", err)
+	}
+	f, err := uc.storage.Create(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	w, err := newExportWriter(job.Format, f)
+	if err != nil {
+		return 0, err
+	}
+	var rows int32
+	progressAt := time.Now()
+	err = uc.repo.ScanReviews(ctx, filter, exportBatchSize, func(list []*model.ReviewInfo) error {
+		select {
+		case <-uc.stop:
+			return errExportStopped
+		default:
+		}
+		if int(rows)+len(list) > MaxExportRows {
+			return fmt.Errorf("more than %d reviews match the filter, narrow the filter and retry", MaxExportRows)
+		}
+		for _, r := range list {
+			if err := w.Write(r); err != nil {
+				return err
+			}
+		}
+		rows += int32(len(list))
+		// 定期更新进度 同时表示任务还在执行，不会被其它实例抢占
+		if time.Since(progressAt) >= exportProgressInterval {
+			progressAt = time.Now()
+			return uc.repo.UpdateExportJob(ctx, job.JobID, map[string]interface{}{"row_count": rows})
+		}
+		return nil
+	})
+	if err != nil {
+		// 释放xlsx的临时文件 写出的内容随后会被删除
+		_ = w.Close()
+		return rows, err
+	}
+	if err := w.Close(); err != nil {
+		return rows, err
+	}
+	return rows, f.Close()
+}
+
+// exportKey 导出文件的key 带128位的随机串，避免通过任务ID猜到其它店铺的文件
+// 下载地址不经过身份认证，只有通过 GetExportJob 的店铺校验拿到地址的调用方可以下载
+func exportKey(job *model.ReviewExportJob) string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("review_%d_%s.%s", job.JobID, hex.EncodeToString(b), job.Format)
+}
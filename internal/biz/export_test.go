@@ -0,0 +1,111 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"review-service/internal/data/model"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// fakeExportRepo 只实现查询任务
+type fakeExportRepo struct {
+	ExportRepo
+	jobs map[int64]*model.ReviewExportJob
+}
+
+func (r *fakeExportRepo) GetExportJob(_ context.Context, jobID int64) (*model.ReviewExportJob, error) {
+	job, ok := r.jobs[jobID]
+	if !ok {
+		return nil, ErrExportNotFound
+	}
+	return job, nil
+}
+
+type fakeExportStorage struct{}
+
+func (fakeExportStorage) Create(context.Context, string) (io.WriteCloser, error) { return nil, nil }
+func (fakeExportStorage) Remove(context.Context, string) error                   { return nil }
+func (fakeExportStorage) URL(key string) string                                  { return "/files/" + key }
+
+func TestGetExportJob(t *testing.T) {
+	repo := &fakeExportRepo{jobs: map[int64]*model.ReviewExportJob{
+		1: {JobID: 1, StoreID: 7, Status: ExportStatusDone, FileKey: "a.csv", Filter: `{"store_id":7,"scores":[1,2]}`},
+		2: {JobID: 2, StoreID: 0, Status: ExportStatusRunning, Filter: `{}`},
+	}}
+	uc := NewExportUsecase(repo, fakeExportStorage{}, nil, log.DefaultLogger)
+	tests := []struct {
+		name    string
+		jobID   int64
+		storeID int64
+		wantErr error
+		wantURL string
+	}{
+		{name: "own store", jobID: 1, storeID: 7, wantURL: "/files/a.csv"},
+		{name: "other store", jobID: 1, storeID: 8, wantErr: ErrForbidden},
+		// 全部店铺的导出只有运营(storeID为0)能查看
+		{name: "all stores job by store", jobID: 2, storeID: 7, wantErr: ErrForbidden},
+		{name: "all stores job by operator", jobID: 2},
+		{name: "operator sees store job", jobID: 1, wantURL: "/files/a.csv"},
+		{name: "not found", jobID: 3, storeID: 7, wantErr: ErrExportNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job, err := uc.GetExportJob(context.Background(), tt.jobID, tt.storeID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetExportJob() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if job.DownloadURL != tt.wantURL {
+				t.Fatalf("DownloadURL = %q, want %q", job.DownloadURL, tt.wantURL)
+			}
+			if job.Filter == nil {
+				t.Fatal("Filter is nil")
+			}
+		})
+	}
+}
+
+func TestExportFilterValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter *ExportFilter
+		want   error
+	}{
+		{name: "empty", filter: &ExportFilter{}},
+		{name: "full", filter: &ExportFilter{StoreID: 1, StartDate: "2024-01-01", EndDate: "2024-01-31", Statuses: []int32{10, 20, 30, 40}, Scores: []int32{1, 5}}},
+		{name: "same day", filter: &ExportFilter{StartDate: "2024-01-01", EndDate: "2024-01-01"}},
+		{name: "bad start", filter: &ExportFilter{StartDate: "20240101"}, want: ErrInvalidExportFilter},
+		{name: "bad end", filter: &ExportFilter{EndDate: "2024-13-01"}, want: ErrInvalidExportFilter},
+		{name: "end before start", filter: &ExportFilter{StartDate: "2024-01-02", EndDate: "2024-01-01"}, want: ErrInvalidExportFilter},
+		{name: "unknown status", filter: &ExportFilter{Statuses: []int32{50}}, want: ErrInvalidExportFilter},
+		{name: "score out of range", filter: &ExportFilter{Scores: []int32{0}}, want: ErrInvalidExportFilter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.validate(); !errors.Is(err, tt.want) {
+				t.Fatalf("validate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportFilterTimeRange(t *testing.T) {
+	f := &ExportFilter{StartDate: "2024-01-01", EndDate: "2024-01-31"}
+	start, end := f.TimeRange()
+	// 包含结束日期当天
+	if got := start.Format("2006-01-02 15:04"); got != "2024-01-01 00:00" {
+		t.Fatalf("start = %s", got)
+	}
+	if got := end.Format("2006-01-02 15:04"); got != "2024-02-01 00:00" {
+		t.Fatalf("end = %s", got)
+	}
+	if start, end := (&ExportFilter{}).TimeRange(); !start.IsZero() || !end.IsZero() {
+		t.Fatalf("TimeRange() of empty filter = %v, %v", start, end)
+	}
+}
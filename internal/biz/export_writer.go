@@ -0,0 +1,205 @@
+package biz
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"review-service/internal/data/model"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// exportColumns 导出文件的列 三种格式使用相同的列
+var exportColumns = []string{
+	"review_id", "order_id", "store_id", "spu_id", "sku_id", "user_id",
+	"score", "service_score", "express_score", "content",
+	"status", "has_media", "has_reply", "helpful_count", "create_at",
+}
+
+// exportRecord 导出的一行 匿名评价不导出用户id和能对应到用户的订单号
+type exportRecord struct {
+	ReviewID     int64  `json:"review_id,string"`
+	OrderID      int64  `json:"order_id,string,omitempty"`
+	StoreID      int64  `json:"store_id,string"`
+	SpuID        int64  `json:"spu_id,string"`
+	SkuID        int64  `json:"sku_id,string"`
+	UserID       int64  `json:"user_id,string,omitempty"`
+	Score        int32  `json:"score"`
+	ServiceScore int32  `json:"service_score"`
+	ExpressScore int32  `json:"express_score"`
+	Content      string `json:"content"`
+	Status       int32  `json:"status"`
+	HasMedia     int32  `json:"has_media"`
+	HasReply     int32  `json:"has_reply"`
+	HelpfulCount int32  `json:"helpful_count"`
+	CreateAt     string `json:"create_at"`
+}
+
+func newExportRecord(r *model.ReviewInfo) *exportRecord {
+	rec := &exportRecord{
+		ReviewID:     r.ReviewID,
+		StoreID:      r.StoreID,
+		SpuID:        r.SpuID,
+		SkuID:        r.SkuID,
+		Score:        r.Score,
+		ServiceScore: r.ServiceScore,
+		ExpressScore: r.ExpressScore,
+		Content:      r.Content,
+		Status:       r.Status,
+		HasMedia:     r.HasMedia,
+		HasReply:     r.HasReply,
+		HelpfulCount: r.HelpfulCount,
+		CreateAt:     r.CreateAt.Format("2006-01-02 15:04:05"),
+	}
+	if r.Anonymous == 0 {
+		rec.OrderID = r.OrderID
+		rec.UserID = r.UserID
+	}
+	return rec
+}
+
+// values 与 exportColumns 的顺序一致 id用字符串，避免在表格软件中丢失精度
+func (rec *exportRecord) values() []string {
+	orderID, userID := "", ""
+	if rec.OrderID != 0 {
+		orderID = strconv.FormatInt(rec.OrderID, 10)
+	}
+	if rec.UserID != 0 {
+		userID = strconv.FormatInt(rec.UserID, 10)
+	}
+	return []string{
+		strconv.FormatInt(rec.ReviewID, 10),
+		orderID,
+		strconv.FormatInt(rec.StoreID, 10),
+		strconv.FormatInt(rec.SpuID, 10),
+		strconv.FormatInt(rec.SkuID, 10),
+		userID,
+		strconv.Itoa(int(rec.Score)),
+		strconv.Itoa(int(rec.ServiceScore)),
+		strconv.Itoa(int(rec.ExpressScore)),
+		rec.Content,
+		strconv.Itoa(int(rec.Status)),
+		strconv.Itoa(int(rec.HasMedia)),
+		strconv.Itoa(int(rec.HasReply)),
+		strconv.Itoa(int(rec.HelpfulCount)),
+		rec.CreateAt,
+	}
+}
+
+// exportWriter 按格式写导出文件 Close 写入剩余内容，不关闭底层的文件
+type exportWriter interface {
+	Write(r *model.ReviewInfo) error
+	Close() error
+}
+
+func newExportWriter(format string, w io.Writer) (exportWriter, error) {
+	switch format {
+	case ExportFormatCSV:
+		return newCSVWriter(w)
+	case ExportFormatJSONL:
+		return newJSONLWriter(w), nil
+	case ExportFormatXLSX:
+		return newXLSXWriter(w)
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	// 写入UTF-8 BOM Excel打开时才能正确识别中文
+	if _, err := w.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return nil, err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportColumns); err != nil {
+		return nil, err
+	}
+	return &csvWriter{w: cw}, nil
+}
+
+func (c *csvWriter) Write(r *model.ReviewInfo) error {
+	return c.w.Write(newExportRecord(r).values())
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type jsonlWriter struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func newJSONLWriter(w io.Writer) *jsonlWriter {
+	buf := bufio.NewWriter(w)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	return &jsonlWriter{buf: buf, enc: enc}
+}
+
+func (j *jsonlWriter) Write(r *model.ReviewInfo) error {
+	// Encode 每条记录后自动换行
+	return j.enc.Encode(newExportRecord(r))
+}
+
+func (j *jsonlWriter) Close() error {
+	return j.buf.Flush()
+}
+
+// xlsxWriter 使用流式写入 行数据写入临时文件，不会全部保存在内存中
+type xlsxWriter struct {
+	w    io.Writer
+	file *excelize.File
+	sw   *excelize.StreamWriter
+	row  int
+}
+
+const exportSheet = "Sheet1"
+
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+	sw, err := file.NewStreamWriter(exportSheet)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	x := &xlsxWriter{w: w, file: file, sw: sw}
+	if err := x.writeRow(exportColumns); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return x, nil
+}
+
+func (x *xlsxWriter) writeRow(values []string) error {
+	x.row++
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+	row := make([]interface{}, len(values))
+	for i, v := range values {
+		row[i] = v
+	}
+	return x.sw.SetRow(cell, row)
+}
+
+func (x *xlsxWriter) Write(r *model.ReviewInfo) error {
+	return x.writeRow(newExportRecord(r).values())
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.file.Close()
+	if err := x.sw.Flush(); err != nil {
+		return err
+	}
+	return x.file.Write(x.w)
+}
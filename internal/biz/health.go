@@ -0,0 +1,52 @@
+package biz
+
+import (
+	"context"
+	"time"
+)
+
+// 依赖名称
+const (
+	DependencyMySQL         = "mysql"
+	DependencyRedis         = "redis"
+	DependencyElasticsearch = "elasticsearch"
+)
+
+// HealthCheckTimeout 单次健康检查的超时时间 超时的依赖视为不可用
+const HealthCheckTimeout = 2 * time.Second
+
+// HealthRepo 检查服务依赖的存储是否可用
+type HealthRepo interface {
+	// Ping 并发检查所有依赖 key为依赖名称，value为nil表示可用
+	Ping(ctx context.Context) map[string]error
+}
+
+// HealthStatus 健康检查的结果
+type HealthStatus struct {
+	Ready        bool              `json:"ready"`
+	Dependencies map[string]string `json:"dependencies"` // 依赖名称 --> ok 或错误信息
+}
+
+type HealthUsecase struct {
+	repo HealthRepo
+}
+
+func NewHealthUsecase(repo HealthRepo) *HealthUsecase {
+	return &HealthUsecase{repo: repo}
+}
+
+// Check 检查所有依赖 任何一个依赖不可用时服务都不能对外提供服务
+func (uc *HealthUsecase) Check(ctx context.Context) *HealthStatus {
+	ctx, cancel := context.WithTimeout(ctx, HealthCheckTimeout)
+	defer cancel()
+	ret := &HealthStatus{Ready: true, Dependencies: make(map[string]string)}
+	for name, err := range uc.repo.Ping(ctx) {
+		if err != nil {
+			ret.Ready = false
+			ret.Dependencies[name] = err.Error()
+			continue
+		}
+		ret.Dependencies[name] = "ok"
+	}
+	return ret
+}
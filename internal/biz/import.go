@@ -0,0 +1,554 @@
+package biz
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"review-service/internal/data/model"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// 评价导入
+// 把其它平台的历史评价导入到评价表 文件格式为CSV(首行为列名)或JSONL(每行一个JSON对象)，列名见下面的导入文件的列
+// 每行按创建评价的规则校验，来源平台的订单号写入ext_json，同一店铺、同一来源的订单号只导入一次
+// 导入过的订单号记录在 review_import_info 中，由唯一键保证不重复，重复执行或者同时执行导入都是安全的
+// 校验通过的行按批写入，每批一个事务；导入的是历史评价，不触发限流和差评通知
+// 内容的情感和关键词在写入前同步分析，和评价一起写入ext_json
+
+// 导入文件格式
+const (
+	ImportFormatCSV   = "csv"
+	ImportFormatJSONL = "jsonl"
+)
+
+// 导入结果中每行的结果
+const (
+	ImportRowDuplicate = "duplicate" // 已经导入过 跳过
+	ImportRowFailed    = "failed"    // 校验或写入失败
+)
+
+// DefaultImportBatchSize 每个事务写入的评价数
+const DefaultImportBatchSize = 200
+
+// maxImportLineSize JSONL单行的长度上限
+const maxImportLineSize = 1 << 20
+
+// MaxExternalOrderIDLen 来源平台订单号的长度上限 与 review_import_info.external_order_id 的列定义保持一致
+const MaxExternalOrderIDLen = 64
+
+// 导入文件的列
+const (
+	importColExternalOrderID = "external_order_id" // 必填 来源平台的订单号
+	importColStoreID         = "store_id"          // 必填
+	importColUserID          = "user_id"
+	importColOrderID         = "order_id" // 本平台的订单号 没有时为0
+	importColSpuID           = "spu_id"
+	importColSkuID           = "sku_id"
+	importColScore           = "score" // 必填
+	importColServiceScore    = "service_score"
+	importColExpressScore    = "express_score"
+	importColContent         = "content" // 必填
+	importColPicInfo         = "pic_info"
+	importColVideoInfo       = "video_info"
+	importColAnonymous       = "anonymous"        // 1或true表示匿名
+	importColCreateAt        = "create_at"        // 2006-01-02 15:04:05 或 RFC3339，为空时为导入时间
+	importColCategoryID      = "category_id"      // 有维度评分时必填
+	importColDimensionScores = "dimension_scores" // 维度评分的JSON 如 {"taste":5}
+)
+
+// importRequiredColumns CSV文件必须包含的列
+var importRequiredColumns = []string{importColExternalOrderID, importColStoreID, importColScore, importColContent}
+
+// ImportOptions 导入参数
+type ImportOptions struct {
+	Source    string // 必填 来源平台
+	Status    int32  // 导入后的评价状态 默认20审核通过
+	BatchSize int
+	DryRun    bool // 只校验不写入
+	OpUser    string
+}
+
+// ImportRowResult 没有导入的行
+type ImportRowResult struct {
+	Line            int // 文件中的行号 CSV的列名为第1行
+	ExternalOrderID string
+	Result          string
+	Err             error
+}
+
+// ImportReport 导入结果
+type ImportReport struct {
+	Total      int
+	Imported   int
+	Duplicated int
+	Failed     int
+	Rows       []*ImportRowResult // 重复和失败的行 按行号排序
+}
+
+func (r *ImportReport) add(row *ImportRowResult) {
+	if row.Result == ImportRowDuplicate {
+		r.Duplicated++
+	} else {
+		r.Failed++
+	}
+	r.Rows = append(r.Rows, row)
+}
+
+type ImportUsecase struct {
+	repo     ReviewRepo
+	dimRepo  DimensionRepo
+	analysis *AnalysisUsecase
+	log      *log.Helper
+}
+
+func NewImportUsecase(repo ReviewRepo, dimRepo DimensionRepo, analysis *AnalysisUsecase, logger log.Logger) *ImportUsecase {
+	return &ImportUsecase{
+		repo:     repo,
+		dimRepo:  dimRepo,
+		analysis: analysis,
+		log:      log.NewHelper(logger),
+	}
+}
+
+// importRow 待写入的一行
+type importRow struct {
+	line   int
+	extID  string
+	review *model.ReviewInfo
+}
+
+// importState 一次导入过程中的缓存
+type importState struct {
+	opts     *ImportOptions
+	report   *ImportReport
+	imported map[int64]map[string]struct{} // 店铺 --> 已经导入的来源订单号
+	dims     map[int64][]*model.ReviewDimension
+	orders   map[int64]struct{} // 本次导入中已经使用的本平台订单号
+	batch    []*importRow
+}
+
+// ImportReviews 从CSV或JSONL导入评价
+// 文件格式错误时返回错误；单行的错误记录在导入结果中，不影响其它行
+func (uc *ImportUsecase) ImportReviews(ctx context.Context, format string, r io.Reader, opts *ImportOptions) (*ImportReport, error) {
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] ImportReviews", "format", format, "source", opts.Source, "dry_run", opts.DryRun, "op_user", opts.OpUser)
+	if opts.Source == "" {
+		return nil, ErrInvalidImportFile.WithArgs("source is required")
+	}
+	// 10待审核 20审核通过
+	if opts.Status == 0 {
+		opts.Status = 20
+	}
+	if opts.Status != 10 && opts.Status != 20 {
+		return nil, ErrInvalidImportFile.WithArgs("status must be 10 or 20")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultImportBatchSize
+	}
+	reader, err := newImportReader(format, r)
+	if err != nil {
+		return nil, err
+	}
+	st := &importState{
+		opts:     opts,
+		report:   &ImportReport{},
+		imported: make(map[int64]map[string]struct{}),
+		dims:     make(map[int64][]*model.ReviewDimension),
+		orders:   make(map[int64]struct{}),
+	}
+	for {
+		line, rec, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, ErrInvalidImportRow) {
+			// 无法解析的行 记为失败，继续读取下一行
+			st.report.Total++
+			st.report.add(&ImportRowResult{Line: line, Result: ImportRowFailed, Err: err})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		st.report.Total++
+		row, res := uc.prepare(ctx, st, line, rec)
+		if res != nil {
+			st.report.add(res)
+			continue
+		}
+		st.batch = append(st.batch, row)
+		if len(st.batch) >= opts.BatchSize {
+			uc.flush(ctx, st)
+		}
+	}
+	uc.flush(ctx, st)
+	sort.SliceStable(st.report.Rows, func(i, j int) bool { return st.report.Rows[i].Line < st.report.Rows[j].Line })
+	uc.log.WithContext(ctx).Infow("msg", "[biz] ImportReviews done", "source", opts.Source, "total", st.report.Total,
+		"imported", st.report.Imported, "duplicated", st.report.Duplicated, "failed", st.report.Failed)
+	return st.report, nil
+}
+
+// prepare 转换并校验一行 不能导入时返回该行的结果
+func (uc *ImportUsecase) prepare(ctx context.Context, st *importState, line int, rec map[string]string) (*importRow, *ImportRowResult) {
+	extID := strings.TrimSpace(rec[importColExternalOrderID])
+	failed := func(err error) *ImportRowResult {
+		return &ImportRowResult{Line: line, ExternalOrderID: extID, Result: ImportRowFailed, Err: err}
+	}
+	review, err := importReview(rec, st.opts)
+	if err != nil {
+		return nil, failed(err)
+	}
+	if err := validateReview(review); err != nil {
+		return nil, failed(err)
+	}
+	if ext := ParseReviewExt(review); ext.CategoryID > 0 || len(ext.DimensionScores) > 0 {
+		dims, ok := st.dims[ext.CategoryID]
+		if !ok {
+			if dims, err = uc.dimRepo.ListDimensions(ctx, ext.CategoryID); err != nil {
+				uc.log.WithContext(ctx).Errorw("msg", "[biz] ImportReviews ListDimensions fail", "err", err)
+				return nil, failed(ErrDBFailed)
+			}
+			st.dims[ext.CategoryID] = dims
+		}
+		if err := validateDimensionScores(ext, dims); err != nil {
+			return nil, failed(err)
+		}
+	}
+	// 按店铺、来源去重 同一个文件中重复的行也会被跳过；同时进行的导入写入时由唯一键去重
+	imported, ok := st.imported[review.StoreID]
+	if !ok {
+		ids, err := uc.repo.ListImportedOrderIDs(ctx, review.StoreID, st.opts.Source)
+		if err != nil {
+			uc.log.WithContext(ctx).Errorw("msg", "[biz] ImportReviews ListImportedOrderIDs fail", "err", err)
+			return nil, failed(ErrDBFailed)
+		}
+		imported = make(map[string]struct{}, len(ids))
+		for _, id := range ids {
+			imported[id] = struct{}{}
+		}
+		st.imported[review.StoreID] = imported
+	}
+	if _, ok := imported[extID]; ok {
+		return nil, &ImportRowResult{Line: line, ExternalOrderID: extID, Result: ImportRowDuplicate, Err: ErrImportDuplicate.WithArgs(extID)}
+	}
+	// 本平台的订单号 与创建评价一样，一个订单只能有一条评价
+	if review.OrderID > 0 {
+		if _, ok := st.orders[review.OrderID]; ok {
+			return nil, failed(ErrOrderReviewed.WithArgs(review.OrderID))
+		}
+		reviews, err := uc.repo.GetReviewByOrderID(ctx, review)
+		if err != nil {
+			uc.log.WithContext(ctx).Errorw("msg", "[biz] ImportReviews GetReviewByOrderID fail", "err", err)
+			return nil, failed(ErrDBFailed)
+		}
+		if len(reviews) > 0 {
+			return nil, failed(ErrOrderReviewed.WithArgs(review.OrderID))
+		}
+	}
+	review.ReviewID, err = uc.repo.NewReviewID(review)
+	if err != nil {
+		uc.log.WithContext(ctx).Errorw("msg", "[biz] ImportReviews gen review id fail", "err", err)
+		return nil, failed(ErrGenID)
+	}
+	imported[extID] = struct{}{}
+	if review.OrderID > 0 {
+		st.orders[review.OrderID] = struct{}{}
+	}
+	return &importRow{line: line, extID: extID, review: review}, nil
+}
+
+// flush 在一个事务中写入当前批次 失败时整批记为失败，可以修正后重新导入
+func (uc *ImportUsecase) flush(ctx context.Context, st *importState) {
+	if len(st.batch) == 0 {
+		return
+	}
+	batch := st.batch
+	st.batch = nil
+	if st.opts.DryRun {
+		st.report.Imported += len(batch)
+		return
+	}
+	reviews := make([]*model.ReviewInfo, 0, len(batch))
+	for _, row := range batch {
+		uc.analyze(ctx, row.review)
+		reviews = append(reviews, row.review)
+	}
+	duplicated, err := uc.repo.ImportReviews(ctx, reviews)
+	if err != nil {
+		uc.log.WithContext(ctx).Errorw("msg", "[biz] ImportReviews save fail", "first_line", batch[0].line, "err", err)
+		for _, row := range batch {
+			delete(st.imported[row.review.StoreID], row.extID)
+			delete(st.orders, row.review.OrderID)
+			st.report.add(&ImportRowResult{Line: row.line, ExternalOrderID: row.extID, Result: ImportRowFailed, Err: ErrDBFailed})
+		}
+		return
+	}
+	// 读取已导入的订单号之后，其它导入写入了同样的订单号
+	skipped := make(map[int64]struct{}, len(duplicated))
+	for _, review := range duplicated {
+		skipped[review.ReviewID] = struct{}{}
+	}
+	for _, row := range batch {
+		if _, ok := skipped[row.review.ReviewID]; ok {
+			delete(st.orders, row.review.OrderID)
+			st.report.add(&ImportRowResult{Line: row.line, ExternalOrderID: row.extID, Result: ImportRowDuplicate, Err: ErrImportDuplicate.WithArgs(row.extID)})
+		}
+	}
+	st.report.Imported += len(batch) - len(duplicated)
+}
+
+// analyze 写入前分析评价内容的情感和关键词 分析失败时只记录日志，照常导入
+func (uc *ImportUsecase) analyze(ctx context.Context, review *model.ReviewInfo) {
+	ret, err := uc.analysis.Analyze(ctx, review.Content)
+	if err != nil {
+		uc.log.WithContext(ctx).Warnw("msg", "[biz] ImportReviews analyze fail", "review_id", review.ReviewID, "err", err)
+		return
+	}
+	if ret == nil {
+		return
+	}
+	ext := ParseReviewExt(review)
+	ext.SetAnalysis(ret)
+	if err := SetReviewExt(review, ext); err != nil {
+		uc.log.WithContext(ctx).Warnw("msg", "[biz] ImportReviews SetReviewExt fail", "review_id", review.ReviewID, "err", err)
+	}
+}
+
+// importReview 把一行转换成评价
+func importReview(rec map[string]string, opts *ImportOptions) (*model.ReviewInfo, error) {
+	p := &importParser{rec: rec}
+	ext := &ReviewExt{
+		Source:          opts.Source,
+		ExternalOrderID: p.str(importColExternalOrderID),
+		CategoryID:      p.int64(importColCategoryID),
+	}
+	if ext.ExternalOrderID == "" || utf8.RuneCountInString(ext.ExternalOrderID) > MaxExternalOrderIDLen {
+		return nil, ErrInvalidImportRow.WithArgs(importColExternalOrderID)
+	}
+	if s := p.str(importColDimensionScores); s != "" {
+		if err := json.Unmarshal([]byte(s), &ext.DimensionScores); err != nil {
+			return nil, ErrInvalidImportRow.WithArgs(importColDimensionScores)
+		}
+	}
+	review := &model.ReviewInfo{
+		CreateBy:     opts.OpUser,
+		UpdateBy:     opts.OpUser,
+		StoreID:      p.int64(importColStoreID),
+		UserID:       p.int64(importColUserID),
+		OrderID:      p.int64(importColOrderID),
+		SpuID:        p.int64(importColSpuID),
+		SkuID:        p.int64(importColSkuID),
+		Score:        p.int32(importColScore),
+		ServiceScore: p.int32(importColServiceScore),
+		ExpressScore: p.int32(importColExpressScore),
+		Content:      p.str(importColContent),
+		PicInfo:      p.str(importColPicInfo),
+		VideoInfo:    p.str(importColVideoInfo),
+		Anonymous:    p.bool(importColAnonymous),
+		Status:       opts.Status,
+	}
+	review.CreateAt = p.time(importColCreateAt)
+	if p.err != nil {
+		return nil, p.err
+	}
+	if review.StoreID <= 0 {
+		return nil, ErrInvalidImportRow.WithArgs(importColStoreID)
+	}
+	// 来源平台没有单独的服务评分和物流评分时使用总评分
+	if review.ServiceScore == 0 {
+		review.ServiceScore = review.Score
+	}
+	if review.ExpressScore == 0 {
+		review.ExpressScore = review.Score
+	}
+	if hasMedia(review) {
+		review.HasMedia = 1
+	}
+	if review.CreateAt.IsZero() {
+		review.CreateAt = time.Now()
+	}
+	review.UpdateAt = time.Now()
+	if err := SetReviewExt(review, ext); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// importParser 解析一行中的字段 记录第一个解析失败的字段
+type importParser struct {
+	rec map[string]string
+	err error
+}
+
+func (p *importParser) str(col string) string {
+	return strings.TrimSpace(p.rec[col])
+}
+
+func (p *importParser) fail(col string) {
+	if p.err == nil {
+		p.err = ErrInvalidImportRow.WithArgs(col)
+	}
+}
+
+func (p *importParser) int64(col string) int64 {
+	s := p.str(col)
+	if s == "" {
+		return 0
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || v < 0 {
+		p.fail(col)
+	}
+	return v
+}
+
+func (p *importParser) int32(col string) int32 {
+	s := p.str(col)
+	if s == "" {
+		return 0
+	}
+	v, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		p.fail(col)
+	}
+	return int32(v)
+}
+
+func (p *importParser) bool(col string) int32 {
+	switch strings.ToLower(p.str(col)) {
+	case "", "0", "false":
+		return 0
+	case "1", "true":
+		return 1
+	}
+	p.fail(col)
+	return 0
+}
+
+func (p *importParser) time(col string) time.Time {
+	s := p.str(col)
+	if s == "" {
+		return time.Time{}
+	}
+	for _, layout := range []string{time.DateTime, time.RFC3339, time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t
+		}
+	}
+	p.fail(col)
+	return time.Time{}
+}
+
+// importReader 按行读取导入文件 每行转换成 列名 --> 值
+type importReader interface {
+	// next 返回行号和一行的内容 读完时返回 io.EOF，无法解析的行返回 ErrInvalidImportRow
+	next() (int, map[string]string, error)
+}
+
+func newImportReader(format string, r io.Reader) (importReader, error) {
+	switch format {
+	case ImportFormatCSV:
+		return newCSVImportReader(r)
+	case ImportFormatJSONL:
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 64*1024), maxImportLineSize)
+		return &jsonlImportReader{sc: sc}, nil
+	}
+	return nil, ErrInvalidImportFile.WithArgs(fmt.Sprintf("unknown format %q", format))
+}
+
+type csvImportReader struct {
+	r      *csv.Reader
+	header []string
+}
+
+func newCSVImportReader(r io.Reader) (*csvImportReader, error) {
+	cr := csv.NewReader(r)
+	// 各行的列数可以不同 缺少的列视为空
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, ErrInvalidImportFile.WithArgs("missing header")
+	}
+	cols := make(map[string]struct{}, len(header))
+	for i, h := range header {
+		// 去掉Excel导出的CSV开头的UTF-8 BOM
+		if i == 0 {
+			h = strings.TrimPrefix(h, "\xEF\xBB\xBF")
+		}
+		header[i] = strings.ToLower(strings.TrimSpace(h))
+		cols[header[i]] = struct{}{}
+	}
+	for _, c := range importRequiredColumns {
+		if _, ok := cols[c]; !ok {
+			return nil, ErrInvalidImportFile.WithArgs("missing column " + c)
+		}
+	}
+	return &csvImportReader{r: cr, header: header}, nil
+}
+
+func (c *csvImportReader) next() (int, map[string]string, error) {
+	values, err := c.r.Read()
+	if err == io.EOF {
+		return 0, nil, io.EOF
+	}
+	if err != nil {
+		var perr *csv.ParseError
+		if errors.As(err, &perr) {
+			return perr.StartLine, nil, ErrInvalidImportRow.WithArgs(perr.Err.Error())
+		}
+		return 0, nil, err
+	}
+	line, _ := c.r.FieldPos(0)
+	rec := make(map[string]string, len(c.header))
+	for i, v := range values {
+		if i < len(c.header) {
+			rec[c.header[i]] = v
+		}
+	}
+	return line, rec, nil
+}
+
+type jsonlImportReader struct {
+	sc   *bufio.Scanner
+	line int
+}
+
+func (j *jsonlImportReader) next() (int, map[string]string, error) {
+	for j.sc.Scan() {
+		j.line++
+		b := j.sc.Bytes()
+		if len(strings.TrimSpace(string(b))) == 0 {
+			continue
+		}
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(b, &obj); err != nil {
+			return j.line, nil, ErrInvalidImportRow.WithArgs("invalid json")
+		}
+		// 字符串去掉引号，数字、布尔值和对象(维度评分)保留原文
+		rec := make(map[string]string, len(obj))
+		for k, v := range obj {
+			var s string
+			if err := json.Unmarshal(v, &s); err == nil {
+				rec[k] = s
+			} else if string(v) != "null" {
+				rec[k] = string(v)
+			}
+		}
+		return j.line, rec, nil
+	}
+	if err := j.sc.Err(); err != nil {
+		return 0, nil, ErrInvalidImportFile.WithArgs(err.Error())
+	}
+	return 0, nil, io.EOF
+}
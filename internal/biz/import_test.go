@@ -0,0 +1,218 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"review-service/internal/data/model"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// fakeImportRepo 内存中的已导入订单号和评价
+type fakeImportRepo struct {
+	ReviewRepo
+	imported   map[int64][]string // 店铺 --> 之前导入过的来源订单号
+	orders     map[int64]bool     // 已经有评价的本平台订单号
+	concurrent map[string]bool    // 写入时被并发的导入抢先写入的来源订单号
+	saveErr    error
+	nextID     int64
+	saved      []*model.ReviewInfo
+}
+
+func (r *fakeImportRepo) ListImportedOrderIDs(_ context.Context, storeID int64, _ string) ([]string, error) {
+	return r.imported[storeID], nil
+}
+
+func (r *fakeImportRepo) GetReviewByOrderID(_ context.Context, review *model.ReviewInfo) ([]*model.ReviewInfo, error) {
+	if r.orders[review.OrderID] {
+		return []*model.ReviewInfo{{OrderID: review.OrderID}}, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeImportRepo) NewReviewID(*model.ReviewInfo) (int64, error) {
+	r.nextID++
+	return r.nextID, nil
+}
+
+func (r *fakeImportRepo) ImportReviews(_ context.Context, reviews []*model.ReviewInfo) ([]*model.ReviewInfo, error) {
+	if r.saveErr != nil {
+		return nil, r.saveErr
+	}
+	var duplicated []*model.ReviewInfo
+	for _, review := range reviews {
+		if r.concurrent[ParseReviewExt(review).ExternalOrderID] {
+			duplicated = append(duplicated, review)
+			continue
+		}
+		r.saved = append(r.saved, review)
+	}
+	return duplicated, nil
+}
+
+// newTestImportUsecase 关闭内容分析 只测试导入
+func newTestImportUsecase(repo *fakeImportRepo) *ImportUsecase {
+	runtime := NewRuntime()
+	c := DefaultRuntimeConfig()
+	c.Features[FeatureAnalysis] = false
+	runtime.Store(c)
+	return NewImportUsecase(repo, &fakeDimRepo{}, NewAnalysisUsecase(repo, nil, runtime, log.DefaultLogger), log.DefaultLogger)
+}
+
+// rowResults 没有导入的行 格式为 行号:结果
+func rowResults(report *ImportReport) []string {
+	ret := []string{}
+	for _, row := range report.Rows {
+		ret = append(ret, fmt.Sprintf("%d:%s", row.Line, row.Result))
+	}
+	return ret
+}
+
+func TestImportReviewsDedupe(t *testing.T) {
+	const header = "external_order_id,store_id,order_id,score,content\n"
+	tests := []struct {
+		name     string
+		csv      string
+		repo     *fakeImportRepo
+		batch    int
+		imported int
+		rows     []string
+	}{
+		{
+			name:     "all new",
+			csv:      header + "A1,1,,5,很好\nA2,1,,4,不错\n",
+			repo:     &fakeImportRepo{},
+			imported: 2,
+			rows:     []string{},
+		},
+		{
+			name:     "imported before",
+			csv:      header + "A1,1,,5,很好\nA2,1,,4,不错\n",
+			repo:     &fakeImportRepo{imported: map[int64][]string{1: {"A1"}}},
+			imported: 1,
+			rows:     []string{"2:duplicate"},
+		},
+		{
+			name:     "repeated in file",
+			csv:      header + "A1,1,,5,很好\nA1,1,,4,不错\n",
+			repo:     &fakeImportRepo{},
+			imported: 1,
+			rows:     []string{"3:duplicate"},
+		},
+		// 去重按店铺 不同店铺的来源订单号可以相同
+		{
+			name:     "same id in other store",
+			csv:      header + "A1,1,,5,很好\nA1,2,,4,不错\n",
+			repo:     &fakeImportRepo{imported: map[int64][]string{2: {"B1"}}},
+			imported: 2,
+			rows:     []string{},
+		},
+		{
+			name:     "order reviewed",
+			csv:      header + "A1,1,100,5,很好\nA2,1,101,4,不错\nA3,1,101,4,不错\n",
+			repo:     &fakeImportRepo{orders: map[int64]bool{100: true}},
+			imported: 1,
+			rows:     []string{"2:failed", "4:failed"},
+		},
+		{
+			name:     "imported concurrently",
+			csv:      header + "A1,1,,5,很好\nA2,1,,4,不错\n",
+			repo:     &fakeImportRepo{concurrent: map[string]bool{"A2": true}},
+			imported: 1,
+			rows:     []string{"3:duplicate"},
+		},
+		{
+			name:     "invalid rows",
+			csv:      header + ",1,,5,很好\nA2,x,,4,不错\nA3,1,,9,不错\nA4,1,,5,\"unterminated\n",
+			repo:     &fakeImportRepo{},
+			imported: 0,
+			rows:     []string{"2:failed", "3:failed", "4:failed", "5:failed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := newTestImportUsecase(tt.repo)
+			report, err := uc.ImportReviews(context.Background(), ImportFormatCSV, strings.NewReader(tt.csv), &ImportOptions{Source: "other", BatchSize: tt.batch})
+			if err != nil {
+				t.Fatalf("ImportReviews() error = %v", err)
+			}
+			if report.Imported != tt.imported || len(tt.repo.saved) != tt.imported {
+				t.Fatalf("imported = %d, saved = %d, want %d", report.Imported, len(tt.repo.saved), tt.imported)
+			}
+			if got := rowResults(report); !reflect.DeepEqual(got, tt.rows) {
+				t.Fatalf("rows = %v, want %v", got, tt.rows)
+			}
+			if report.Total != report.Imported+report.Duplicated+report.Failed {
+				t.Fatalf("total %d != %d + %d + %d", report.Total, report.Imported, report.Duplicated, report.Failed)
+			}
+		})
+	}
+}
+
+func TestImportReviewsSaveFail(t *testing.T) {
+	repo := &fakeImportRepo{saveErr: errors.New("db is down")}
+	uc := newTestImportUsecase(repo)
+	csv := "external_order_id,store_id,score,content\nA1,1,5,很好\nA2,1,4,不错\nA3,1,3,一般\n"
+	report, err := uc.ImportReviews(context.Background(), ImportFormatCSV, strings.NewReader(csv), &ImportOptions{Source: "other", BatchSize: 2})
+	if err != nil {
+		t.Fatalf("ImportReviews() error = %v", err)
+	}
+	// 写入失败时整批记为失败
+	if want := []string{"2:failed", "3:failed", "4:failed"}; !reflect.DeepEqual(rowResults(report), want) {
+		t.Fatalf("rows = %v, want %v", rowResults(report), want)
+	}
+	for _, row := range report.Rows {
+		if !errors.Is(row.Err, ErrDBFailed) {
+			t.Fatalf("line %d error = %v, want %v", row.Line, row.Err, ErrDBFailed)
+		}
+	}
+}
+
+func TestImportReviewsJSONL(t *testing.T) {
+	repo := &fakeImportRepo{}
+	uc := newTestImportUsecase(repo)
+	jsonl := `{"external_order_id":"A1","store_id":1,"score":5,"content":"很好","anonymous":true,"create_at":"2024-01-02 03:04:05"}
+
+{"external_order_id":"A1","store_id":"1","score":"4","content":"重复"}
+not json
+`
+	report, err := uc.ImportReviews(context.Background(), ImportFormatJSONL, strings.NewReader(jsonl), &ImportOptions{Source: "other", DryRun: true})
+	if err != nil {
+		t.Fatalf("ImportReviews() error = %v", err)
+	}
+	if want := []string{"3:duplicate", "4:failed"}; !reflect.DeepEqual(rowResults(report), want) {
+		t.Fatalf("rows = %v, want %v", rowResults(report), want)
+	}
+	// 只校验不写入
+	if report.Imported != 1 || len(repo.saved) != 0 {
+		t.Fatalf("imported = %d, saved = %d", report.Imported, len(repo.saved))
+	}
+}
+
+func TestImportReviewsInvalidFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		file   string
+		opts   *ImportOptions
+	}{
+		{name: "no source", format: ImportFormatCSV, file: "external_order_id,store_id,score,content\n", opts: &ImportOptions{}},
+		{name: "bad status", format: ImportFormatCSV, file: "external_order_id,store_id,score,content\n", opts: &ImportOptions{Source: "other", Status: 40}},
+		{name: "unknown format", format: "xml", opts: &ImportOptions{Source: "other"}},
+		{name: "empty csv", format: ImportFormatCSV, opts: &ImportOptions{Source: "other"}},
+		{name: "missing column", format: ImportFormatCSV, file: "external_order_id,store_id,content\n", opts: &ImportOptions{Source: "other"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := newTestImportUsecase(&fakeImportRepo{})
+			if _, err := uc.ImportReviews(context.Background(), tt.format, strings.NewReader(tt.file), tt.opts); !errors.Is(err, ErrInvalidImportFile) {
+				t.Fatalf("ImportReviews() error = %v, want %v", err, ErrInvalidImportFile)
+			}
+		})
+	}
+}
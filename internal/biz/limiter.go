@@ -0,0 +1,12 @@
+package biz
+
+import (
+	"context"
+	"time"
+)
+
+// RateLimiter 固定窗口限流
+type RateLimiter interface {
+	// Allow key在当前窗口内的请求数不超过limit时返回true
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, error)
+}
@@ -0,0 +1,41 @@
+package biz
+
+import (
+	"context"
+	"strings"
+
+	"review-service/internal/data/model"
+)
+
+// 回复的审核状态
+const (
+	ReplyStatusPending  int32 = 10 // 待审核
+	ReplyStatusApproved int32 = 20 // 审核通过
+	ReplyStatusRejected int32 = 30 // 审核不通过
+)
+
+// ReplyModerator 回复内容审核的钩子
+// 在回复入库前调用，返回回复的审核状态，只有审核通过的回复才会在对话中展示
+type ReplyModerator interface {
+	Moderate(ctx context.Context, reply *model.ReviewReplyInfo) (int32, error)
+}
+
+// wordModerator 默认的审核实现 命中运行时配置中的敏感词时进入待审核，否则直接审核通过
+type wordModerator struct {
+	runtime *Runtime
+}
+
+// NewReplyModerator 默认的回复审核钩子
+// 接入内容安全服务时替换这里的实现即可
+func NewReplyModerator(runtime *Runtime) ReplyModerator {
+	return wordModerator{runtime: runtime}
+}
+
+func (m wordModerator) Moderate(_ context.Context, reply *model.ReviewReplyInfo) (int32, error) {
+	for _, word := range m.runtime.Load().ReplyBlockedWords {
+		if word != "" && strings.Contains(reply.Content, word) {
+			return ReplyStatusPending, nil
+		}
+	}
+	return ReplyStatusApproved, nil
+}
@@ -1,5 +1,7 @@
 package biz
 
+import "time"
+
 // ListReviewParam 评价列表参数
 type ListReviewParam struct {
 	UserID int64
@@ -7,6 +9,28 @@ type ListReviewParam struct {
 	Size   int
 }
 
+// VoteReviewParam 用户给评价投票的参数
+type VoteReviewParam struct {
+	ReviewID int64
+	UserID   int64
+	Vote     int32
+}
+
+// VoteResult 投票后的结果
+type VoteResult struct {
+	Vote           int32 // 用户当前的投票 0表示已取消
+	HelpfulCount   int32
+	UnhelpfulCount int32
+}
+
+// ReportReviewParam 用户举报评价的参数
+type ReportReviewParam struct {
+	ReviewID int64
+	UserID   int64
+	Reason   string
+	Content  string
+}
+
 // ReplyParam 商家回复评价的参数
 type ReplyReviewParam struct {
 	ReviewID  int64
@@ -16,6 +40,21 @@ type ReplyReviewParam struct {
 	VideoInfo string
 }
 
+// UpdateReplyParam 商家修改回复的参数
+type UpdateReplyParam struct {
+	ReplyID   int64
+	StoreID   int64
+	Content   string
+	PicInfo   string
+	VideoInfo string
+}
+
+// DeleteReplyParam 商家删除回复的参数
+type DeleteReplyParam struct {
+	ReplyID int64
+	StoreID int64
+}
+
 // AppealParam 商家申诉的评价参数
 type AppealReviewParam struct {
 	ReviewID  int64
@@ -43,3 +82,31 @@ type AuditAppealParam struct {
 	OpUser    string
 	OpRemarks string
 }
+
+// ThreadReplyParam 在评价的回复对话中追加回复的参数
+type ThreadReplyParam struct {
+	ReviewID   int64
+	ParentID   int64
+	AuthorType int32
+	StoreID    int64
+	UserID     int64
+	Content    string
+	PicInfo    string
+	VideoInfo  string
+}
+
+// DeleteThreadReplyParam 删除对话中回复的参数
+type DeleteThreadReplyParam struct {
+	ReplyID    int64
+	AuthorType int32
+	StoreID    int64
+	UserID     int64
+}
+
+// DashboardParam 商家评价看板的参数 Start和End都是当天零点，统计包含End当天
+type DashboardParam struct {
+	StoreID  int64
+	Start    time.Time
+	End      time.Time
+	Interval string
+}
@@ -3,110 +3,457 @@ package biz
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
-	v1 "review-service/api/review/v1"
 	"review-service/internal/data/model"
+	"review-service/internal/metrics"
 	"review-service/pkg/snowflake"
+	"review-service/pkg/webhook"
 
 	"github.com/go-kratos/kratos/v2/log"
 )
 
 type ReviewRepo interface {
 	SaveReview(context.Context, *model.ReviewInfo) (*model.ReviewInfo, error)
-	GetReviewByOrderID(context.Context, int64) ([]*model.ReviewInfo, error)
+	NewReviewID(*model.ReviewInfo) (int64, error)
+	GetReviewByOrderID(context.Context, *model.ReviewInfo) ([]*model.ReviewInfo, error)
 	GetReviewByReviewID(context.Context, int64) (*model.ReviewInfo, error)
 	ListReviewByUserID(context.Context, int64, int, int) ([]*model.ReviewInfo, error)
 	SaveReply(context.Context, *model.ReviewReplyInfo) (*model.ReviewReplyInfo, error)
 	AppealReview(context.Context, *AppealReviewParam) (*model.ReviewAppealInfo, error)
 	AuditReview(context.Context, *AuditReviewParam) error
 	AuditAppeal(context.Context, *AuditAppealParam) error
-    ListReviewByStoreID(ctx context.Context, storeID int64, offset, limit int) ([]*MyReviewInfo, error) 
+	// ListReviewByStoreID 商家的评价列表 sentiment不为空时只返回该情感倾向的评价
+	ListReviewByStoreID(ctx context.Context, storeID int64, offset, limit int, sort, sentiment string) ([]*MyReviewInfo, error)
+	GetReplyByReplyID(context.Context, int64) (*model.ReviewReplyInfo, error)
+	SaveThreadReply(ctx context.Context, reply *model.ReviewReplyInfo, maxLength int) (*model.ReviewReplyInfo, error)
+	ListThreadReply(context.Context, int64) ([]*model.ReviewReplyInfo, error)
+	DeleteThreadReply(context.Context, int64) error
+	UpdateReply(ctx context.Context, reply *model.ReviewReplyInfo, history *model.ReviewReplyHistory) error
+	DeleteReply(context.Context, *model.ReviewReplyInfo) error
+	VoteReview(ctx context.Context, review *model.ReviewInfo, userID int64, vote int32) (*VoteResult, error)
+	FlushVoteCount(ctx context.Context, batch int) (int, error)
+	SaveReport(ctx context.Context, report *model.ReviewReportInfo, threshold int) (*model.ReviewReportInfo, error)
+	// ListPendingReports 待处理的举报 reviewID为0时不按评价筛选
+	ListPendingReports(ctx context.Context, reviewID int64, offset, limit int) ([]*model.ReviewReportInfo, error)
+	BatchGetReply(ctx context.Context, reviewIDs []int64) ([]*model.ReviewReplyInfo, error)
+	BatchGetAppeal(ctx context.Context, reviewIDs []int64) ([]*model.ReviewAppealInfo, error)
+	// BatchListThreadReply 批量查询评价的回复对话 按评价和楼层排序
+	BatchListThreadReply(ctx context.Context, reviewIDs []int64) ([]*model.ReviewReplyInfo, error)
+	ListStoreCategories(ctx context.Context, storeID int64) ([]int64, error)
+	GetReviewStats(ctx context.Context, storeID, categoryID int64, codes []string) (*ReviewStats, error)
+	GetStoreDashboard(ctx context.Context, param *DashboardParam) ([]*DashboardPoint, error)
+	// ImportReviews 在一个事务中写入导入的评价 返回来源订单号已经导入过而跳过的评价
+	ImportReviews(ctx context.Context, reviews []*model.ReviewInfo) ([]*model.ReviewInfo, error)
+	// ListImportedOrderIDs 店铺从来源平台导入过的订单号
+	ListImportedOrderIDs(ctx context.Context, storeID int64, source string) ([]string, error)
+	// SaveReviewAnalysis 内容分析的结果合并到评价的ext_json，同时写入统计文档
+	SaveReviewAnalysis(ctx context.Context, reviewID int64, a *ContentAnalysis) error
+	// TopKeywords 店铺某种情感倾向的评价中出现最多的关键词
+	TopKeywords(ctx context.Context, storeID int64, sentiment string, size int) ([]*KeywordCount, error)
 }
 
+// ReviewRelation 评价关联的商家首条回复和申诉 key为reviewID
+type ReviewRelation struct {
+	Replies map[int64]*model.ReviewReplyInfo
+	Appeals map[int64]*model.ReviewAppealInfo
+}
+
+// 回复的作者类型
+const (
+	ReplyAuthorStore int32 = 1 // 商家
+	ReplyAuthorUser  int32 = 2 // 用户
+)
+
+// MaxThreadLength 一条评价下回复对话的默认最大楼层数(包含商家首条回复) 可在运行时配置中修改
+const MaxThreadLength = 20
+
+// ReplyEditWindow 商家回复发布后允许修改的时间窗口
+const ReplyEditWindow = 24 * time.Hour
+
+// 评价投票类型
+const (
+	VoteHelpful   int32 = 1 // 有用
+	VoteUnhelpful int32 = 2 // 无用
+)
+
+// ReportHideThreshold 待处理的举报数达到该值时自动隐藏评价，等待运营审核 可在运行时配置中修改
+const ReportHideThreshold = 5
+
+// 商家评价列表的排序方式
+const (
+	SortDefault = ""        // 默认排序
+	SortHelpful = "helpful" // 按有用数倒序
+)
+
 type ReviewUsecase struct {
-	repo ReviewRepo
-	log  *log.Helper
+	repo      ReviewRepo
+	userRepo  UserRepo
+	dimRepo   DimensionRepo
+	moderator ReplyModerator
+	limiter   RateLimiter
+	notifier  ReviewNotifier
+	analyzer  ReviewAnalyzer
+	idgen     snowflake.IDGenerator
+	runtime   *Runtime
+	log       *log.Helper
 }
 
-func NewReviewUsecase(repo ReviewRepo, logger log.Logger) *ReviewUsecase {
+func NewReviewUsecase(repo ReviewRepo, userRepo UserRepo, dimRepo DimensionRepo, moderator ReplyModerator, limiter RateLimiter, notifier ReviewNotifier, analyzer ReviewAnalyzer, idgen snowflake.IDGenerator, runtime *Runtime, logger log.Logger) *ReviewUsecase {
 	return &ReviewUsecase{
-		repo: repo,
-		log:  log.NewHelper(logger),
+		repo:      repo,
+		userRepo:  userRepo,
+		dimRepo:   dimRepo,
+		moderator: moderator,
+		limiter:   limiter,
+		notifier:  notifier,
+		analyzer:  analyzer,
+		idgen:     idgen,
+		runtime:   runtime,
+		log:       log.NewHelper(logger),
+	}
+}
+
+// RuntimeConfig 当前生效的运行时配置
+func (uc *ReviewUsecase) RuntimeConfig() *RuntimeConfig {
+	return uc.runtime.Load()
+}
+
+// nextID 生成回复、举报等的ID (雪花算法)
+func (uc *ReviewUsecase) nextID(ctx context.Context) (int64, error) {
+	id, err := uc.idgen.NextID()
+	if err != nil {
+		uc.log.WithContext(ctx).Errorw("msg", "[biz] gen id fail", "err", err)
+		return 0, ErrGenID
 	}
+	return id, nil
 }
 
 // 实现业务逻辑的地方
 // service层调用该方法
 func (uc *ReviewUsecase) CreateReview(ctx context.Context, review *model.ReviewInfo) (*model.ReviewInfo, error) {
-	uc.log.WithContext(ctx).Debugf("[biz] CreateReview, req:%v", review)
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] CreateReview", "order_id", review.OrderID, "user_id", review.UserID)
 	// 1.数据校验
 	// 1.1 参数基础校验: 正常来说不应该放在这一层，在上一层或者框架层拦住
+	// 但是内部任务会直接调用biz，评分、内容长度和媒体数量这类业务约束在这里再校验一次
+	if err := validateReview(review); err != nil {
+		return nil, err
+	}
+	// 限流 限流计数读写失败时放行，不影响正常创建评价
+	if limit := uc.runtime.Load().CreateReviewPerMinute; limit > 0 {
+		ok, err := uc.limiter.Allow(ctx, fmt.Sprintf("create_review:%d", review.UserID), limit, time.Minute)
+		if err != nil {
+			uc.log.WithContext(ctx).Warnw("msg", "[biz] CreateReview rate limit fail", "err", err)
+		} else if !ok {
+			return nil, ErrRateLimited
+		}
+	}
 	// 1.2 参数业务校验: 带业务逻辑的参数校验，比如已经评价过的订单不能再创建评价
-	reviews, err := uc.repo.GetReviewByOrderID(ctx, review.OrderID)
+	// 维度评分按评价所属类目的评分维度校验
+	if ext := ParseReviewExt(review); ext.CategoryID > 0 || len(ext.DimensionScores) > 0 {
+		dims, err := uc.dimRepo.ListDimensions(ctx, ext.CategoryID)
+		if err != nil {
+			uc.log.WithContext(ctx).Errorw("msg", "[biz] CreateReview ListDimensions fail", "err", err)
+			return nil, ErrDBFailed
+		}
+		if err := validateDimensionScores(ext, dims); err != nil {
+			return nil, err
+		}
+	}
+	reviews, err := uc.repo.GetReviewByOrderID(ctx, review)
 	if err != nil {
-		return nil, v1.ErrorDbFailed("查询数据库失败")
+		uc.log.WithContext(ctx).Errorw("msg", "[biz] CreateReview GetReviewByOrderID fail", "err", err)
+		return nil, ErrDBFailed
 	}
 	if len(reviews) > 0 {
-		fmt.Printf("len(reviews):%d", len(reviews))
-		return nil, v1.ErrorOrderReviewed("订单%d已评价", review.OrderID)
+		return nil, ErrOrderReviewed.WithArgs(review.OrderID)
 	}
 	// 2.生成reviewID (雪花算法)
-	// 这里可以使用雪花算法自己生成
-	review.ReviewID = snowflake.GenID()
+	// 分表时由数据层在ID中带上评价所在的分片
+	review.ReviewID, err = uc.repo.NewReviewID(review)
+	if err != nil {
+		uc.log.WithContext(ctx).Errorw("msg", "[biz] CreateReview gen review id fail", "err", err)
+		return nil, ErrGenID
+	}
+	if hasMedia(review) {
+		review.HasMedia = 1
+	}
 	// 3.查询订单和商品快照信息
 	// 实际业务场景下就需要查询订单服务和商家服务(比如说通过RPC调用订单服务和商家服务)
 	// 4.拼装数据入库
-	fmt.Printf("[biz] CreateReview,review:%#v\n", review)
-	return uc.repo.SaveReview(ctx, review)
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] CreateReview save", "review_id", review.ReviewID)
+	review, err = uc.repo.SaveReview(ctx, review)
+	if err != nil {
+		return nil, err
+	}
+	metrics.ReviewCreated.Inc()
+	// 5.差评通知商家
+	uc.notifier.NotifyReview(ctx, webhook.EventReviewCreated, review)
+	// 6.后台分析评价内容的情感和关键词
+	uc.analyzer.AnalyzeReview(ctx, review)
+	return review, nil
 }
 
 // GetReview
 func (uc *ReviewUsecase) GetReview(ctx context.Context, reviewId int64) (*model.ReviewInfo, error) {
-	uc.log.WithContext(ctx).Debugf("[biz] GetReview,req:%#v\n", reviewId)
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] GetReview", "review_id", reviewId)
 	return uc.repo.GetReviewByReviewID(ctx, reviewId)
 }
 
 // ListReviewByUserID 通过用户id获取评价列表
 func (uc *ReviewUsecase) ListReviewByUserID(ctx context.Context, param *ListReviewParam) ([]*model.ReviewInfo, error) {
-	uc.log.WithContext(ctx).Debugf("[biz] ListReviewByUserID,param:%#v\n", param)
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] ListReviewByUserID", "user_id", param.UserID, "offset", param.Offset, "size", param.Size)
 	return uc.repo.ListReviewByUserID(ctx, param.UserID, param.Offset, param.Size)
 }
 
 func (uc *ReviewUsecase) CreateReply(ctx context.Context, param *ReplyReviewParam) (*model.ReviewReplyInfo, error) {
-	uc.log.WithContext(ctx).Debugf("[biz] ReviewReply,param:%v", param)
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] ReviewReply", "review_id", param.ReviewID, "store_id", param.StoreID)
+	if err := validateReply(param.Content, param.PicInfo, param.VideoInfo); err != nil {
+		return nil, err
+	}
+	replyID, err := uc.nextID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// 商家的首条回复是对话的第一层，has_reply 只由它决定
 	reply := &model.ReviewReplyInfo{
-		ReplyID:   snowflake.GenID(),
-		ReviewID:  param.ReviewID,
-		StoreID:   param.StoreID,
-		Content:   param.Content,
-		PicInfo:   param.PicInfo,
-		VideoInfo: param.VideoInfo,
+		ReplyID:    replyID,
+		ReviewID:   param.ReviewID,
+		StoreID:    param.StoreID,
+		AuthorType: ReplyAuthorStore,
+		Seq:        1,
+		Content:    param.Content,
+		PicInfo:    param.PicInfo,
+		VideoInfo:  param.VideoInfo,
+	}
+	status, err := uc.moderator.Moderate(ctx, reply)
+	if err != nil {
+		return nil, err
 	}
+	reply.Status = status
 	return uc.repo.SaveReply(ctx, reply)
 }
 
+// getStoreReply 查询商家的回复并做水平越权校验
+func (uc *ReviewUsecase) getStoreReply(ctx context.Context, replyID, storeID int64) (*model.ReviewReplyInfo, error) {
+	reply, err := uc.repo.GetReplyByReplyID(ctx, replyID)
+	if err != nil {
+		return nil, err
+	}
+	if reply.IsDel == 1 {
+		return nil, ErrReplyNotFound
+	}
+	// 商家只能操作自己店铺发出的回复
+	if reply.AuthorType != ReplyAuthorStore || reply.StoreID != storeID {
+		return nil, ErrForbidden
+	}
+	return reply, nil
+}
+
+// UpdateReply 商家修改回复
+// 只允许在发布后的时间窗口内修改，修改前的内容记录到修改历史中
+func (uc *ReviewUsecase) UpdateReply(ctx context.Context, param *UpdateReplyParam) (*model.ReviewReplyInfo, error) {
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] UpdateReply", "reply_id", param.ReplyID, "store_id", param.StoreID)
+	if err := validateReply(param.Content, param.PicInfo, param.VideoInfo); err != nil {
+		return nil, err
+	}
+	reply, err := uc.getStoreReply(ctx, param.ReplyID, param.StoreID)
+	if err != nil {
+		return nil, err
+	}
+	if time.Since(reply.CreateAt) > ReplyEditWindow {
+		return nil, ErrReplyEditExpired
+	}
+	history := &model.ReviewReplyHistory{
+		ReplyID:   reply.ReplyID,
+		ReviewID:  reply.ReviewID,
+		StoreID:   reply.StoreID,
+		Version:   reply.Version,
+		Content:   reply.Content,
+		PicInfo:   reply.PicInfo,
+		VideoInfo: reply.VideoInfo,
+	}
+	reply.Content = param.Content
+	reply.PicInfo = param.PicInfo
+	reply.VideoInfo = param.VideoInfo
+	// 修改后的内容需要重新审核
+	reply.Status, err = uc.moderator.Moderate(ctx, reply)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.repo.UpdateReply(ctx, reply, history); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// DeleteReply 商家删除回复
+// 删除的是首条回复时，评价恢复为未回复状态，对话中的其它回复一并删除
+func (uc *ReviewUsecase) DeleteReply(ctx context.Context, param *DeleteReplyParam) error {
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] DeleteReply", "reply_id", param.ReplyID, "store_id", param.StoreID)
+	reply, err := uc.getStoreReply(ctx, param.ReplyID, param.StoreID)
+	if err != nil {
+		return err
+	}
+	return uc.repo.DeleteReply(ctx, reply)
+}
+
+// ReplyThread 在评价的回复对话中追加一条回复
+// 用户只能回复商家的回复，商家只能回复用户的回复
+func (uc *ReviewUsecase) ReplyThread(ctx context.Context, param *ThreadReplyParam) (*model.ReviewReplyInfo, error) {
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] ReplyThread", "review_id", param.ReviewID, "parent_id", param.ParentID, "author_type", param.AuthorType)
+	// 1.数据校验
+	if err := validateReply(param.Content, param.PicInfo, param.VideoInfo); err != nil {
+		return nil, err
+	}
+	review, err := uc.repo.GetReviewByReviewID(ctx, param.ReviewID)
+	if err != nil {
+		return nil, err
+	}
+	// 1.1 商家回复之前不能开启对话
+	if review.HasReply != 1 {
+		return nil, ErrReviewNotReplied
+	}
+	// 1.2 水平越权校验 只有评价的用户和评价所属的商家可以参与对话
+	switch param.AuthorType {
+	case ReplyAuthorStore:
+		if review.StoreID != param.StoreID {
+			return nil, ErrForbidden
+		}
+	case ReplyAuthorUser:
+		if review.UserID != param.UserID {
+			return nil, ErrForbidden
+		}
+	default:
+		return nil, ErrInvalidAuthorType
+	}
+	// 1.3 被回复的回复必须属于该评价，并且是对方发出的
+	parent, err := uc.repo.GetReplyByReplyID(ctx, param.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	if parent.ReviewID != param.ReviewID || parent.IsDel == 1 || parent.Status != ReplyStatusApproved {
+		return nil, ErrReplyNotFound
+	}
+	if parent.AuthorType == param.AuthorType {
+		return nil, ErrReplySelf
+	}
+	// 2.内容审核
+	replyID, err := uc.nextID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reply := &model.ReviewReplyInfo{
+		ReplyID:    replyID,
+		ReviewID:   param.ReviewID,
+		ParentID:   param.ParentID,
+		StoreID:    review.StoreID,
+		AuthorType: param.AuthorType,
+		Content:    param.Content,
+		PicInfo:    param.PicInfo,
+		VideoInfo:  param.VideoInfo,
+	}
+	if param.AuthorType == ReplyAuthorUser {
+		reply.UserID = param.UserID
+	}
+	reply.Status, err = uc.moderator.Moderate(ctx, reply)
+	if err != nil {
+		return nil, err
+	}
+	// 3.入库 楼层序号和楼层上限在数据层的事务中处理
+	return uc.repo.SaveThreadReply(ctx, reply, uc.runtime.Load().MaxThreadLength)
+}
+
+// ListReplyThread 获取评价的回复对话 按楼层顺序返回
+func (uc *ReviewUsecase) ListReplyThread(ctx context.Context, reviewID int64) ([]*model.ReviewReplyInfo, error) {
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] ListReplyThread", "review_id", reviewID)
+	return uc.repo.ListThreadReply(ctx, reviewID)
+}
+
+// BatchListReplyThread 批量获取评价的回复对话 key为reviewID
+func (uc *ReviewUsecase) BatchListReplyThread(ctx context.Context, reviewIDs []int64) (map[int64][]*model.ReviewReplyInfo, error) {
+	ret := make(map[int64][]*model.ReviewReplyInfo, len(reviewIDs))
+	if len(reviewIDs) == 0 {
+		return ret, nil
+	}
+	replies, err := uc.repo.BatchListThreadReply(ctx, reviewIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range replies {
+		ret[r.ReviewID] = append(ret[r.ReviewID], r)
+	}
+	return ret, nil
+}
+
+// DeleteThreadReply 删除自己在对话中的回复 (逻辑删除)
+func (uc *ReviewUsecase) DeleteThreadReply(ctx context.Context, param *DeleteThreadReplyParam) error {
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] DeleteThreadReply", "reply_id", param.ReplyID, "author_type", param.AuthorType)
+	reply, err := uc.repo.GetReplyByReplyID(ctx, param.ReplyID)
+	if err != nil {
+		return err
+	}
+	if reply.IsDel == 1 {
+		return nil
+	}
+	// 商家首条回复关系到评价的 has_reply，不能在对话里删除
+	if reply.ParentID == 0 {
+		return ErrThreadRootDelete
+	}
+	if reply.AuthorType != param.AuthorType {
+		return ErrForbidden
+	}
+	if (param.AuthorType == ReplyAuthorStore && reply.StoreID != param.StoreID) ||
+		(param.AuthorType == ReplyAuthorUser && reply.UserID != param.UserID) {
+		return ErrForbidden
+	}
+	return uc.repo.DeleteThreadReply(ctx, param.ReplyID)
+}
+
 // AppealReview
 func (uc *ReviewUsecase) AppealReview(ctx context.Context, param *AppealReviewParam) (*model.ReviewAppealInfo, error) {
-	uc.log.WithContext(ctx).Debugf("[biz] AppealReview,param:%#v\n", param)
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] AppealReview", "review_id", param.ReviewID, "store_id", param.StoreID, "reason", param.Reason)
+	if err := validateAppeal(param); err != nil {
+		return nil, err
+	}
 	return uc.repo.AppealReview(ctx, param)
 }
 
 // AuditReview
 func (uc *ReviewUsecase) AuditReview(ctx context.Context, param *AuditReviewParam) error {
-	uc.log.WithContext(ctx).Debugf("[biz] AuditReview,param:%#v\n", param)
-	return uc.repo.AuditReview(ctx, param)
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] AuditReview", "review_id", param.ReviewID, "status", param.Status, "op_user", param.OpUser)
+	if err := uc.repo.AuditReview(ctx, param); err != nil {
+		return err
+	}
+	metrics.ReviewAudited.WithLabelValues(strconv.Itoa(int(param.Status))).Inc()
+	// 审核通过(20)后评价对用户可见 差评再通知一次商家
+	if param.Status == 20 {
+		review, err := uc.repo.GetReviewByReviewID(ctx, param.ReviewID)
+		if err != nil {
+			uc.log.WithContext(ctx).Warnw("msg", "[biz] AuditReview GetReview fail", "review_id", param.ReviewID, "err", err)
+			return nil
+		}
+		uc.notifier.NotifyReview(ctx, webhook.EventReviewApproved, review)
+	}
+	return nil
 }
 
 // AuditAppeal
 func (uc *ReviewUsecase) AuditAppeal(ctx context.Context, param *AuditAppealParam) error {
-	uc.log.WithContext(ctx).Debugf("[biz] AuditAppeal,param:%#v\n", param)
-	return uc.repo.AuditAppeal(ctx, param)
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] AuditAppeal", "appeal_id", param.AppealID, "status", param.Status, "op_user", param.OpUser)
+	if err := uc.repo.AuditAppeal(ctx, param); err != nil {
+		return err
+	}
+	metrics.AppealAudited.WithLabelValues(strconv.Itoa(int(param.Status))).Inc()
+	return nil
 }
 
-// ListReviewByStoreID 根据storeID分页查询评价
-func (uc *ReviewUsecase) ListReviewByStoreID(ctx context.Context, storeID int64, page, size int) ([]*MyReviewInfo, error) {
+// ListReviewByStoreID 根据storeID分页查询评价 sentiment不为空时按内容的情感倾向筛选
+// 按情感筛选时只能按创建时间倒序排列
+func (uc *ReviewUsecase) ListReviewByStoreID(ctx context.Context, storeID int64, page, size int, sort, sentiment string) ([]*MyReviewInfo, error) {
 	if page < 0 {
 		page = 1
 	}
@@ -115,30 +462,148 @@ func (uc *ReviewUsecase) ListReviewByStoreID(ctx context.Context, storeID int64,
 	}
 	offset := (page - 1) * size
 	limit := size
-	uc.log.WithContext(ctx).Debugf("[biz] ListReviewByStoreID storeID:%v\n", storeID)
-	return uc.repo.ListReviewByStoreID(ctx, storeID, offset, limit)
+	if sort != SortHelpful {
+		sort = SortDefault
+	}
+	switch sentiment {
+	case "", SentimentPositive, SentimentNeutral, SentimentNegative:
+	default:
+		return nil, ErrInvalidQueryArg.WithArgs("sentiment")
+	}
+	if sentiment != "" && sort != SortDefault {
+		return nil, ErrInvalidQueryArg.WithArgs("sort")
+	}
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] ListReviewByStoreID", "store_id", storeID, "sort", sort, "sentiment", sentiment)
+	return uc.repo.ListReviewByStoreID(ctx, storeID, offset, limit, sort, sentiment)
+}
+
+// VoteReview 用户给评价投票 (有用/无用)
+// 每个用户对一条评价只保留一票，重复投同一票视为取消，投另一票视为改票
+func (uc *ReviewUsecase) VoteReview(ctx context.Context, param *VoteReviewParam) (*VoteResult, error) {
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] VoteReview", "review_id", param.ReviewID, "user_id", param.UserID, "vote", param.Vote)
+	if !uc.runtime.Load().Enabled(FeatureVote) {
+		return nil, ErrFeatureDisabled
+	}
+	if param.Vote != VoteHelpful && param.Vote != VoteUnhelpful {
+		return nil, ErrInvalidVote
+	}
+	review, err := uc.repo.GetReviewByReviewID(ctx, param.ReviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.UserID == param.UserID {
+		return nil, ErrVoteSelf
+	}
+	return uc.repo.VoteReview(ctx, review, param.UserID, param.Vote)
+}
+
+// FlushVoteCount 把缓存中变化过的投票计数刷回数据库
+func (uc *ReviewUsecase) FlushVoteCount(ctx context.Context) error {
+	for {
+		n, err := uc.repo.FlushVoteCount(ctx, 100)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		uc.log.WithContext(ctx).Debugw("msg", "[biz] FlushVoteCount", "flushed", n)
+	}
+}
+
+// ReportReview 用户举报评价
+// 举报进入运营的审核流程，待处理的举报达到阈值时自动隐藏评价
+func (uc *ReviewUsecase) ReportReview(ctx context.Context, param *ReportReviewParam) (*model.ReviewReportInfo, error) {
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] ReportReview", "review_id", param.ReviewID, "user_id", param.UserID, "reason", param.Reason)
+	if !uc.runtime.Load().Enabled(FeatureReport) {
+		return nil, ErrFeatureDisabled
+	}
+	if utf8.RuneCountInString(param.Content) > MaxReportContentLen {
+		return nil, ErrContentTooLong.WithArgs(MaxReportContentLen)
+	}
+	review, err := uc.repo.GetReviewByReviewID(ctx, param.ReviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.UserID == param.UserID {
+		return nil, ErrReportSelf
+	}
+	reportID, err := uc.nextID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := &model.ReviewReportInfo{
+		ReportID: reportID,
+		ReviewID: param.ReviewID,
+		UserID:   param.UserID,
+		Reason:   param.Reason,
+		Content:  param.Content,
+	}
+	return uc.repo.SaveReport(ctx, report, uc.runtime.Load().ReportHideThreshold)
+}
+
+// ListPendingReports 运营查询待处理的举报 审核评价(AuditReview)时一起处理掉
+func (uc *ReviewUsecase) ListPendingReports(ctx context.Context, reviewID int64, page, size int) ([]*model.ReviewReportInfo, error) {
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] ListPendingReports", "review_id", reviewID, "page", page, "size", size)
+	return uc.repo.ListPendingReports(ctx, reviewID, (page-1)*size, size)
+}
+
+// GetReviewRelation 批量查询评价关联的商家回复和申诉
+func (uc *ReviewUsecase) GetReviewRelation(ctx context.Context, reviewIDs []int64) (*ReviewRelation, error) {
+	rel := &ReviewRelation{
+		Replies: make(map[int64]*model.ReviewReplyInfo, len(reviewIDs)),
+		Appeals: make(map[int64]*model.ReviewAppealInfo, len(reviewIDs)),
+	}
+	if len(reviewIDs) == 0 {
+		return rel, nil
+	}
+	replies, err := uc.repo.BatchGetReply(ctx, reviewIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range replies {
+		rel.Replies[r.ReviewID] = r
+	}
+	appeals, err := uc.repo.BatchGetAppeal(ctx, reviewIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range appeals {
+		rel.Appeals[a.ReviewID] = a
+	}
+	return rel, nil
+}
+
+// BatchGetUser 批量获取用户的展示信息(昵称、头像)
+func (uc *ReviewUsecase) BatchGetUser(ctx context.Context, userIDs []int64) (map[int64]*UserInfo, error) {
+	if len(userIDs) == 0 {
+		return map[int64]*UserInfo{}, nil
+	}
+	return uc.userRepo.BatchGetUser(ctx, userIDs)
 }
 
 type MyReviewInfo struct {
 	*model.ReviewInfo
-	CreateAt     MyTime `json:"create_at"` // 创建时间
-	UpdateAt     MyTime `json:"update_at"` // 创建时间
-	Anonymous    int32  `json:"anonymous,string"`
-	Score        int32  `json:"score,string"`
-	ServiceScore int32  `json:"service_score,string"`
-	ExpressScore int32  `json:"express_score,string"`
-	HasMedia     int32  `json:"has_media,string"`
-	Status       int32  `json:"status,string"`
-	IsDefault    int32  `json:"is_default,string"`
-	HasReply     int32  `json:"has_reply,string"`
-	ID           int64  `json:"id,string"`
-	Version      int32  `json:"version,string"`
-	ReviewID     int64  `json:"review_id,string"`
-	OrderID      int64  `json:"order_id,string"`
-	SkuID        int64  `json:"sku_id,string"`
-	SpuID        int64  `json:"spu_id,string"`
-	StoreID      int64  `json:"store_id,string"`
-	UserID       int64  `json:"user_id,string"`
+	CreateAt       MyTime `json:"create_at"` // 创建时间
+	UpdateAt       MyTime `json:"update_at"` // 创建时间
+	Anonymous      int32  `json:"anonymous,string"`
+	Score          int32  `json:"score,string"`
+	ServiceScore   int32  `json:"service_score,string"`
+	ExpressScore   int32  `json:"express_score,string"`
+	HasMedia       int32  `json:"has_media,string"`
+	Status         int32  `json:"status,string"`
+	IsDefault      int32  `json:"is_default,string"`
+	HasReply       int32  `json:"has_reply,string"`
+	HelpfulCount   int32  `json:"helpful_count,string"`
+	UnhelpfulCount int32  `json:"unhelpful_count,string"`
+	ID             int64  `json:"id,string"`
+	Version        int32  `json:"version,string"`
+	ReviewID       int64  `json:"review_id,string"`
+	OrderID        int64  `json:"order_id,string"`
+	SkuID          int64  `json:"sku_id,string"`
+	SpuID          int64  `json:"spu_id,string"`
+	StoreID        int64  `json:"store_id,string"`
+	UserID         int64  `json:"user_id,string"`
 }
 
 type MyTime time.Time
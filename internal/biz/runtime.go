@@ -0,0 +1,75 @@
+package biz
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// 运行时配置
+// 修改配置文件或consul KV中的 runtime 配置后立即生效，不需要重启服务
+// 未配置的项使用 DefaultRuntimeConfig 中的默认值
+
+// 功能开关
+const (
+	FeatureVote     = "vote"     // 评价投票
+	FeatureReport   = "report"   // 举报评价
+	FeatureAnalysis = "analysis" // 评价内容的情感和关键词分析
+)
+
+// DefaultListCacheTTL 商家评价列表缓存的默认有效期
+const DefaultListCacheTTL = 60 * time.Second
+
+// DefaultDashboardCacheTTL 商家评价看板缓存的默认有效期
+const DefaultDashboardCacheTTL = 5 * time.Minute
+
+// RuntimeConfig 运行时配置
+type RuntimeConfig struct {
+	ListCacheTTL          time.Duration
+	DashboardCacheTTL     time.Duration
+	CreateReviewPerMinute int
+	ReportHideThreshold   int
+	MaxThreadLength       int
+	ReplyBlockedWords     []string
+	Features              map[string]bool
+	UpdatedAt             time.Time
+}
+
+// DefaultRuntimeConfig 默认的运行时配置
+func DefaultRuntimeConfig() *RuntimeConfig {
+	return &RuntimeConfig{
+		ListCacheTTL:        DefaultListCacheTTL,
+		DashboardCacheTTL:   DefaultDashboardCacheTTL,
+		ReportHideThreshold: ReportHideThreshold,
+		MaxThreadLength:     MaxThreadLength,
+		Features:            map[string]bool{},
+	}
+}
+
+// Enabled 功能是否开启 未配置的功能默认开启
+func (c *RuntimeConfig) Enabled(feature string) bool {
+	on, ok := c.Features[feature]
+	return !ok || on
+}
+
+// Runtime 当前生效的运行时配置 并发安全
+// 配置整体替换，读取方拿到的始终是一份完整的配置
+type Runtime struct {
+	v atomic.Pointer[RuntimeConfig]
+}
+
+func NewRuntime() *Runtime {
+	r := &Runtime{}
+	r.Store(DefaultRuntimeConfig())
+	return r
+}
+
+// Load 当前生效的配置 调用方不能修改返回值
+func (r *Runtime) Load() *RuntimeConfig {
+	return r.v.Load()
+}
+
+// Store 替换当前生效的配置
+func (r *Runtime) Store(c *RuntimeConfig) {
+	c.UpdatedAt = time.Now()
+	r.v.Store(c)
+}
@@ -0,0 +1,15 @@
+package biz
+
+import "context"
+
+// UserInfo 用户服务返回的用户展示信息
+type UserInfo struct {
+	UserID   int64
+	Nickname string
+	Avatar   string
+}
+
+// UserRepo 用户服务的接口，由data层对接实际的用户服务
+type UserRepo interface {
+	BatchGetUser(ctx context.Context, userIDs []int64) (map[int64]*UserInfo, error)
+}
@@ -0,0 +1,131 @@
+package biz
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"review-service/internal/data/model"
+)
+
+// 业务参数校验
+// proto中的validate规则只在gRPC/HTTP入口生效，内部任务直接调用biz时不会经过，
+// 所以业务上的约束统一在这里校验，保证不管从哪里调用行为都一致
+
+// 评分范围
+const (
+	MinScore = 1
+	MaxScore = 5
+)
+
+// 文本字段的长度上限 与 review.sql 中的列定义保持一致 (varchar按字符计算，不是字节)
+const (
+	MaxReviewContentLen = 512  // review_info.content
+	MaxReplyContentLen  = 512  // review_reply_info.content
+	MaxAppealContentLen = 255  // review_appeal_info.content
+	MaxReportContentLen = 255  // review_report_info.content
+	MaxMediaInfoLen     = 1024 // pic_info / video_info
+)
+
+// 媒体数量上限 pic_info/video_info 中是以逗号分隔的URL
+const (
+	MaxPicCount   = 9
+	MaxVideoCount = 1
+)
+
+// 申诉原因
+const (
+	AppealReasonMalicious  = "malicious"  // 恶意差评
+	AppealReasonInaccurate = "inaccurate" // 与事实不符
+	AppealReasonAd         = "ad"         // 广告/无关内容
+	AppealReasonPrivacy    = "privacy"    // 泄露隐私
+	AppealReasonOther      = "other"      // 其它
+)
+
+var appealReasons = map[string]struct{}{
+	AppealReasonMalicious:  {},
+	AppealReasonInaccurate: {},
+	AppealReasonAd:         {},
+	AppealReasonPrivacy:    {},
+	AppealReasonOther:      {},
+}
+
+// validateReview 校验创建评价的参数
+func validateReview(review *model.ReviewInfo) error {
+	scores := []struct {
+		name  string
+		score int32
+	}{
+		{"score", review.Score},
+		{"service_score", review.ServiceScore},
+		{"express_score", review.ExpressScore},
+	}
+	for _, s := range scores {
+		if s.score < MinScore || s.score > MaxScore {
+			return ErrInvalidScore.WithArgs(s.name, MinScore, MaxScore)
+		}
+	}
+	if err := validateContent(review.Content, MaxReviewContentLen); err != nil {
+		return err
+	}
+	return validateMedia(review.PicInfo, review.VideoInfo)
+}
+
+// validateReply 校验商家回复和对话回复的参数
+func validateReply(content, picInfo, videoInfo string) error {
+	if err := validateContent(content, MaxReplyContentLen); err != nil {
+		return err
+	}
+	return validateMedia(picInfo, videoInfo)
+}
+
+// validateAppeal 校验商家申诉的参数
+func validateAppeal(param *AppealReviewParam) error {
+	if _, ok := appealReasons[param.Reason]; !ok {
+		return ErrInvalidAppealReason.WithArgs(param.Reason)
+	}
+	if err := validateContent(param.Content, MaxAppealContentLen); err != nil {
+		return err
+	}
+	return validateMedia(param.PicInfo, param.VideoInfo)
+}
+
+// validateContent 内容不能为空，长度按字符数计算
+func validateContent(content string, max int) error {
+	if strings.TrimSpace(content) == "" {
+		return ErrContentEmpty
+	}
+	if utf8.RuneCountInString(content) > max {
+		return ErrContentTooLong.WithArgs(max)
+	}
+	return nil
+}
+
+// validateMedia 校验图片和视频的数量以及字段长度
+func validateMedia(picInfo, videoInfo string) error {
+	if utf8.RuneCountInString(picInfo) > MaxMediaInfoLen || utf8.RuneCountInString(videoInfo) > MaxMediaInfoLen {
+		return ErrMediaTooLong.WithArgs(MaxMediaInfoLen)
+	}
+	if mediaCount(picInfo) > MaxPicCount {
+		return ErrTooManyPics.WithArgs(MaxPicCount)
+	}
+	if mediaCount(videoInfo) > MaxVideoCount {
+		return ErrTooManyVideos.WithArgs(MaxVideoCount)
+	}
+	return nil
+}
+
+// mediaCount 统计以逗号分隔的媒体URL个数
+func mediaCount(info string) int {
+	n := 0
+	for _, s := range strings.Split(info, ",") {
+		if strings.TrimSpace(s) != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// hasMedia 评价是否带图或视频
+func hasMedia(review *model.ReviewInfo) bool {
+	return mediaCount(review.PicInfo)+mediaCount(review.VideoInfo) > 0
+}
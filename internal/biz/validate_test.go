@@ -0,0 +1,101 @@
+package biz
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"review-service/internal/data/model"
+)
+
+func TestValidateReview(t *testing.T) {
+	valid := func() *model.ReviewInfo {
+		return &model.ReviewInfo{Score: 5, ServiceScore: 4, ExpressScore: 1, Content: "很好用"}
+	}
+	tests := []struct {
+		name   string
+		modify func(r *model.ReviewInfo)
+		want   error
+	}{
+		{name: "valid", modify: func(r *model.ReviewInfo) {}},
+		{name: "score too low", modify: func(r *model.ReviewInfo) { r.Score = 0 }, want: ErrInvalidScore},
+		{name: "service score too high", modify: func(r *model.ReviewInfo) { r.ServiceScore = 6 }, want: ErrInvalidScore},
+		{name: "express score negative", modify: func(r *model.ReviewInfo) { r.ExpressScore = -1 }, want: ErrInvalidScore},
+		{name: "blank content", modify: func(r *model.ReviewInfo) { r.Content = "  \n" }, want: ErrContentEmpty},
+		// 长度按字符计算 512个汉字不超限
+		{name: "max content in runes", modify: func(r *model.ReviewInfo) { r.Content = strings.Repeat("好", MaxReviewContentLen) }},
+		{name: "content too long", modify: func(r *model.ReviewInfo) { r.Content = strings.Repeat("a", MaxReviewContentLen+1) }, want: ErrContentTooLong},
+		{name: "too many pics", modify: func(r *model.ReviewInfo) { r.PicInfo = strings.Repeat("p.jpg,", MaxPicCount+1) }, want: ErrTooManyPics},
+		{name: "too many videos", modify: func(r *model.ReviewInfo) { r.VideoInfo = "a.mp4,b.mp4" }, want: ErrTooManyVideos},
+		{name: "media too long", modify: func(r *model.ReviewInfo) { r.PicInfo = strings.Repeat("p", MaxMediaInfoLen+1) }, want: ErrMediaTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := valid()
+			tt.modify(r)
+			if err := validateReview(r); !errors.Is(err, tt.want) {
+				t.Fatalf("validateReview() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateReply(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		picInfo   string
+		videoInfo string
+		want      error
+	}{
+		{name: "valid", content: "感谢支持", picInfo: "a.jpg,b.jpg", videoInfo: "a.mp4"},
+		{name: "empty", content: "", want: ErrContentEmpty},
+		{name: "too long", content: strings.Repeat("谢", MaxReplyContentLen+1), want: ErrContentTooLong},
+		{name: "too many videos", content: "感谢支持", videoInfo: "a.mp4,b.mp4", want: ErrTooManyVideos},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateReply(tt.content, tt.picInfo, tt.videoInfo); !errors.Is(err, tt.want) {
+				t.Fatalf("validateReply() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateAppeal(t *testing.T) {
+	tests := []struct {
+		name  string
+		param *AppealReviewParam
+		want  error
+	}{
+		{name: "valid", param: &AppealReviewParam{Reason: AppealReasonMalicious, Content: "恶意差评"}},
+		{name: "unknown reason", param: &AppealReviewParam{Reason: "angry", Content: "恶意差评"}, want: ErrInvalidAppealReason},
+		{name: "empty content", param: &AppealReviewParam{Reason: AppealReasonOther}, want: ErrContentEmpty},
+		{name: "content too long", param: &AppealReviewParam{Reason: AppealReasonAd, Content: strings.Repeat("广", MaxAppealContentLen+1)}, want: ErrContentTooLong},
+		{name: "too many pics", param: &AppealReviewParam{Reason: AppealReasonPrivacy, Content: "泄露隐私", PicInfo: strings.Repeat("p.jpg,", MaxPicCount+1)}, want: ErrTooManyPics},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateAppeal(tt.param); !errors.Is(err, tt.want) {
+				t.Fatalf("validateAppeal() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMediaCount(t *testing.T) {
+	tests := []struct {
+		info string
+		want int
+	}{
+		{info: "", want: 0},
+		{info: " ", want: 0},
+		{info: "a.jpg", want: 1},
+		{info: "a.jpg,,b.jpg, ", want: 2},
+	}
+	for _, tt := range tests {
+		if got := mediaCount(tt.info); got != tt.want {
+			t.Errorf("mediaCount(%q) = %d, want %d", tt.info, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package biz
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"review-service/internal/data/model"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// 评价变更订阅
+// 评价、回复和申诉变更时，数据层在同一个事务中写入一条变更事件(review_event)，事件的自增主键即序号
+// WatchReviews 按序号顺序轮询事件推送给下游，下游记录最后处理的序号，断开后从该序号继续订阅
+// 事务提交的顺序与主键分配的顺序可能不一致，先提交的事件序号可能更大
+// 推送前先找出已提交的最大连续序号，遇到序号空洞时停在空洞前等待前面的事务提交
+// 空洞之后的事件写入超过 watchCommitTimeout 时，空洞的事务已经超时回滚，才越过空洞继续推送
+
+// 变更事件类型
+const (
+	EventReviewCreated = "review.created" // 创建或导入评价
+	EventReviewAudited = "review.audited" // 运营审核评价
+	EventReviewHidden  = "review.hidden"  // 举报数达到阈值自动隐藏
+	EventReplyCreated  = "reply.created"  // 商家回复
+	EventReplyUpdated  = "reply.updated"  // 修改商家回复
+	EventReplyDeleted  = "reply.deleted"  // 删除回复
+	EventThreadReplied = "thread.replied" // 回复对话中追加回复
+	EventAppealCreated = "appeal.created" // 商家申诉
+	EventAppealAudited = "appeal.audited" // 运营审核申诉
+)
+
+// EventRetention 变更事件的保留时间 超过后从过期的序号继续订阅会返回 ErrWatchSeqExpired
+const EventRetention = 7 * 24 * time.Hour
+
+const (
+	watchPollInterval  = time.Second // 没有新事件时的轮询间隔
+	watchCommitTimeout = time.Minute // 写入事件的事务最长的执行时间 超过后认为序号空洞的事务已经回滚
+	watchBatchSize     = 200         // 每次查询的事件数
+	eventCleanupBatch  = 1000        // 每次清理的事件数
+)
+
+// WatchFilter 订阅条件 店铺和用户都为0时订阅全部评价
+type WatchFilter struct {
+	StoreID  int64
+	UserID   int64
+	Types    []string // 为空时订阅全部类型
+	AfterSeq int64    // 从该序号之后开始推送
+	FromNow  bool     // 忽略AfterSeq，只推送订阅之后的事件
+}
+
+// EventRepo 变更事件的读取和清理 事件由 ReviewRepo 在变更时写入
+type EventRepo interface {
+	// ListEventSeqs 序号大于afterSeq的全部事件的序号和写入时间 不按订阅条件过滤 按序号排序
+	ListEventSeqs(ctx context.Context, afterSeq int64, limit int) ([]*model.ReviewEvent, error)
+	// ListEvents 序号在(afterSeq, uptoSeq]之间的事件 按序号排序
+	ListEvents(ctx context.Context, filter *WatchFilter, afterSeq, uptoSeq int64, limit int) ([]*model.ReviewEvent, error)
+	// LastEventSeq 当前最大的序号
+	LastEventSeq(ctx context.Context) (int64, error)
+	// EventExists 序号对应的事件是否还在
+	EventExists(ctx context.Context, seq int64) (bool, error)
+	// DeleteEventsBefore 删除写入时间早于before的事件 返回删除的数量
+	DeleteEventsBefore(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+type WatchUsecase struct {
+	repo EventRepo
+	log  *log.Helper
+
+	mu      sync.Mutex
+	stopped bool
+	stop    chan struct{}
+}
+
+func NewWatchUsecase(repo EventRepo, logger log.Logger) *WatchUsecase {
+	return &WatchUsecase{
+		repo: repo,
+		log:  log.NewHelper(logger),
+		stop: make(chan struct{}),
+	}
+}
+
+// WatchReviews 按序号顺序推送变更事件 直到ctx结束、send返回错误或服务停止
+// 服务停止时返回nil，下游应该用最后收到的序号重新订阅
+func (uc *WatchUsecase) WatchReviews(ctx context.Context, filter *WatchFilter, send func(*model.ReviewEvent) error) error {
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] WatchReviews", "store_id", filter.StoreID, "user_id", filter.UserID, "after_seq", filter.AfterSeq, "from_now", filter.FromNow)
+	after, err := uc.startSeq(ctx, filter)
+	if err != nil {
+		return err
+	}
+	types := make(map[string]struct{}, len(filter.Types))
+	for _, t := range filter.Types {
+		types[t] = struct{}{}
+	}
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-uc.stop:
+			return nil
+		case <-timer.C:
+		}
+		seqs, err := uc.repo.ListEventSeqs(ctx, after, watchBatchSize)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			uc.log.WithContext(ctx).Errorw("msg", "[biz] WatchReviews ListEventSeqs fail", "err", err)
+			return ErrDBFailed
+		}
+		upto := committedSeq(after, seqs, time.Now().Add(-watchCommitTimeout))
+		var events []*model.ReviewEvent
+		if upto > after {
+			events, err = uc.repo.ListEvents(ctx, filter, after, upto, watchBatchSize)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				uc.log.WithContext(ctx).Errorw("msg", "[biz] WatchReviews ListEvents fail", "err", err)
+				return ErrDBFailed
+			}
+			for _, e := range events {
+				if _, ok := types[e.EventType]; len(types) > 0 && !ok {
+					continue
+				}
+				if err := send(e); err != nil {
+					return err
+				}
+			}
+			// 过滤后的事件没有取完时从最后一条继续 否则(after, upto]之间的事件都已经处理
+			if len(events) == watchBatchSize {
+				after = events[len(events)-1].ID
+			} else {
+				after = upto
+			}
+		}
+		// 一批没有取完时马上取下一批 停在序号空洞前时按间隔轮询
+		if len(events) == watchBatchSize || (len(seqs) == watchBatchSize && after == seqs[len(seqs)-1].ID) {
+			timer.Reset(0)
+		} else {
+			timer.Reset(watchPollInterval)
+		}
+	}
+}
+
+// committedSeq 从after开始连续的已提交的最大序号 seqs按序号排序
+// 序号不连续时，空洞中的事件可能还在未提交的事务中，停在空洞前
+// 空洞之后的事件写入早于deadline时，空洞中的事件分配序号更早，它的事务已经超时回滚，越过空洞
+func committedSeq(after int64, seqs []*model.ReviewEvent, deadline time.Time) int64 {
+	for _, e := range seqs {
+		if e.ID != after+1 && e.CreateAt.After(deadline) {
+			break
+		}
+		after = e.ID
+	}
+	return after
+}
+
+// startSeq 开始推送的序号 序号对应的事件已经被清理时返回 ErrWatchSeqExpired
+func (uc *WatchUsecase) startSeq(ctx context.Context, filter *WatchFilter) (int64, error) {
+	if filter.FromNow {
+		seq, err := uc.repo.LastEventSeq(ctx)
+		if err != nil {
+			uc.log.WithContext(ctx).Errorw("msg", "[biz] WatchReviews LastEventSeq fail", "err", err)
+			return 0, ErrDBFailed
+		}
+		return seq, nil
+	}
+	if filter.AfterSeq <= 0 {
+		return 0, nil
+	}
+	ok, err := uc.repo.EventExists(ctx, filter.AfterSeq)
+	if err != nil {
+		uc.log.WithContext(ctx).Errorw("msg", "[biz] WatchReviews EventExists fail", "err", err)
+		return 0, ErrDBFailed
+	}
+	if !ok {
+		return 0, ErrWatchSeqExpired.WithArgs(filter.AfterSeq)
+	}
+	return filter.AfterSeq, nil
+}
+
+// CleanupEvents 清理超过保留时间的事件 由后台任务定时调用
+func (uc *WatchUsecase) CleanupEvents(ctx context.Context) error {
+	before := time.Now().Add(-EventRetention)
+	var total int64
+	for {
+		n, err := uc.repo.DeleteEventsBefore(ctx, before, eventCleanupBatch)
+		if err != nil {
+			return err
+		}
+		total += n
+		if n < eventCleanupBatch {
+			break
+		}
+	}
+	if total > 0 {
+		uc.log.WithContext(ctx).Infow("msg", "[biz] CleanupEvents", "deleted", total)
+	}
+	return nil
+}
+
+// Stop 结束所有订阅 gRPC服务优雅退出时会等待进行中的流结束
+func (uc *WatchUsecase) Stop() {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if !uc.stopped {
+		uc.stopped = true
+		close(uc.stop)
+	}
+}
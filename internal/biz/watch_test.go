@@ -0,0 +1,38 @@
+package biz
+
+import (
+	"testing"
+	"time"
+
+	"review-service/internal/data/model"
+)
+
+func TestCommittedSeq(t *testing.T) {
+	now := time.Now()
+	deadline := now.Add(-watchCommitTimeout)
+	recent := now.Add(-time.Second)
+	old := deadline.Add(-time.Second)
+	events := func(list ...*model.ReviewEvent) []*model.ReviewEvent { return list }
+	tests := []struct {
+		name  string
+		after int64
+		seqs  []*model.ReviewEvent
+		want  int64
+	}{
+		{name: "no events", after: 5, want: 5},
+		{name: "contiguous", after: 5, seqs: events(&model.ReviewEvent{ID: 6, CreateAt: recent}, &model.ReviewEvent{ID: 7, CreateAt: recent}), want: 7},
+		// 6还在未提交的事务中 7已经提交
+		{name: "gap before uncommitted", after: 5, seqs: events(&model.ReviewEvent{ID: 7, CreateAt: recent}), want: 5},
+		{name: "gap in the middle", after: 5, seqs: events(&model.ReviewEvent{ID: 6, CreateAt: recent}, &model.ReviewEvent{ID: 8, CreateAt: recent}), want: 6},
+		// 8写入超过事务超时时间 7的事务已经回滚
+		{name: "gap older than commit timeout", after: 5, seqs: events(&model.ReviewEvent{ID: 6, CreateAt: old}, &model.ReviewEvent{ID: 8, CreateAt: old}, &model.ReviewEvent{ID: 9, CreateAt: recent}), want: 9},
+		{name: "stop at recent gap after old gap", after: 5, seqs: events(&model.ReviewEvent{ID: 7, CreateAt: old}, &model.ReviewEvent{ID: 9, CreateAt: recent}), want: 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := committedSeq(tt.after, tt.seqs, deadline); got != tt.want {
+				t.Fatalf("committedSeq() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,381 @@
+package biz
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"review-service/internal/data/model"
+	"review-service/pkg/snowflake"
+	"review-service/pkg/webhook"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// 差评通知
+// 用户提交差评或差评审核通过时，把事件推送到店铺配置的webhook，评分不高于webhook的 max_score 时才推送
+// 推送的请求体用webhook的密钥签名，签名方式见 pkg/webhook
+// 推送失败时按指数退避重试，重试多次仍然失败的写入死信表 webhook_dead_letter，排查后手动补发
+// 推送在后台goroutine中进行，不影响评价的创建和审核
+
+// MaxWebhooksPerStore 每个店铺最多配置的webhook数
+const MaxWebhooksPerStore = 5
+
+// MaxWebhookURLLen 推送地址的长度上限 与 store_webhook.url 的列定义保持一致
+const MaxWebhookURLLen = 512
+
+const (
+	webhookMaxAttempts  = 5               // 每个事件最多推送的次数
+	webhookRetryBackoff = time.Second     // 第一次重试的等待时间 之后每次翻倍
+	webhookMaxInflight  = 16              // 同时进行中的推送请求数
+	webhookSecretBytes  = 32              // 自动生成的密钥长度 hex编码后为64个字符
+	webhookMaxErrorLen  = 512             // 死信中记录的失败原因的长度上限
+	webhookStopTimeout  = 5 * time.Second // 服务停止时等待推送结束的时间
+)
+
+// WebhookRepo webhook和死信的存储
+type WebhookRepo interface {
+	ListWebhooks(ctx context.Context, storeID int64) ([]*model.StoreWebhook, error)
+	GetWebhook(ctx context.Context, id int64) (*model.StoreWebhook, error)
+	SaveWebhook(ctx context.Context, hook *model.StoreWebhook) error
+	DeleteWebhook(ctx context.Context, id int64) error
+	SaveDeadLetter(ctx context.Context, letter *model.WebhookDeadLetter) error
+}
+
+// WebhookSender 推送事件
+type WebhookSender interface {
+	// CheckURL 检查推送地址 域名解析到内网、回环或链路本地地址时返回错误
+	CheckURL(ctx context.Context, rawURL string) error
+	// Send 推送一次事件 返回接收方的HTTP状态码，请求失败或状态码不是2xx时返回错误
+	Send(ctx context.Context, hook *model.StoreWebhook, d *WebhookDelivery) (int, error)
+}
+
+// WebhookDelivery 一次待推送的事件 重试时内容和事件id不变
+type WebhookDelivery struct {
+	ID      string
+	Event   string
+	Payload []byte
+}
+
+// ReviewNotifier 评价事件的通知 不阻塞调用方，也不返回推送的结果
+type ReviewNotifier interface {
+	NotifyReview(ctx context.Context, event string, review *model.ReviewInfo)
+}
+
+// WebhookTestResult 测试推送的结果
+type WebhookTestResult struct {
+	StatusCode int
+	Error      string
+}
+
+type WebhookUsecase struct {
+	repo     WebhookRepo
+	sender   WebhookSender
+	idgen    snowflake.IDGenerator
+	log      *log.Helper
+	inflight chan struct{}
+	stop     chan struct{}
+	backoff  time.Duration // 第一次重试的等待时间
+
+	mu      sync.Mutex
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+func NewWebhookUsecase(repo WebhookRepo, sender WebhookSender, idgen snowflake.IDGenerator, logger log.Logger) *WebhookUsecase {
+	return &WebhookUsecase{
+		repo:     repo,
+		sender:   sender,
+		idgen:    idgen,
+		log:      log.NewHelper(logger),
+		inflight: make(chan struct{}, webhookMaxInflight),
+		stop:     make(chan struct{}),
+		backoff:  webhookRetryBackoff,
+	}
+}
+
+// validateWebhook 校验推送地址和评分阈值 未设置评分阈值时使用差评的评分
+func validateWebhook(hook *model.StoreWebhook) error {
+	if len(hook.URL) > MaxWebhookURLLen {
+		return ErrInvalidWebhookURL
+	}
+	u, err := url.Parse(hook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+	if hook.MaxScore == 0 {
+		hook.MaxScore = NegativeScore
+	}
+	if hook.MaxScore < MinScore || hook.MaxScore > MaxScore {
+		return ErrInvalidScore.WithArgs("max_score", MinScore, MaxScore)
+	}
+	return nil
+}
+
+// getStoreWebhook 查询webhook并做水平越权校验
+func (uc *WebhookUsecase) getStoreWebhook(ctx context.Context, id, storeID int64) (*model.StoreWebhook, error) {
+	hook, err := uc.repo.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// 商家只能操作自己店铺的webhook
+	if hook.StoreID != storeID {
+		return nil, ErrForbidden
+	}
+	return hook, nil
+}
+
+// SaveWebhook 商家新增或修改webhook hook.ID为0时新增
+// 没有填写密钥时新增的webhook自动生成密钥，修改时保留原来的密钥
+func (uc *WebhookUsecase) SaveWebhook(ctx context.Context, hook *model.StoreWebhook) (*model.StoreWebhook, error) {
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] SaveWebhook", "id", hook.ID, "store_id", hook.StoreID)
+	if err := validateWebhook(hook); err != nil {
+		return nil, err
+	}
+	// 推送地址只能是公网地址 避免通过webhook访问内部服务
+	if err := uc.sender.CheckURL(ctx, hook.URL); err != nil {
+		uc.log.WithContext(ctx).Warnw("msg", "[biz] SaveWebhook CheckURL fail", "url", hook.URL, "err", err)
+		return nil, ErrInvalidWebhookURL
+	}
+	if hook.ID == 0 {
+		hooks, err := uc.repo.ListWebhooks(ctx, hook.StoreID)
+		if err != nil {
+			uc.log.WithContext(ctx).Errorw("msg", "[biz] SaveWebhook ListWebhooks fail", "err", err)
+			return nil, ErrDBFailed
+		}
+		if len(hooks) >= MaxWebhooksPerStore {
+			return nil, ErrTooManyWebhooks.WithArgs(MaxWebhooksPerStore)
+		}
+		if hook.Secret == "" {
+			b := make([]byte, webhookSecretBytes)
+			if _, err := rand.Read(b); err != nil {
+				return nil, ErrInternal
+			}
+			hook.Secret = hex.EncodeToString(b)
+		}
+	} else {
+		old, err := uc.getStoreWebhook(ctx, hook.ID, hook.StoreID)
+		if err != nil {
+			return nil, err
+		}
+		hook.CreateBy = old.CreateBy
+		hook.CreateAt = old.CreateAt
+		if hook.Secret == "" {
+			hook.Secret = old.Secret
+		}
+	}
+	if err := uc.repo.SaveWebhook(ctx, hook); err != nil {
+		uc.log.WithContext(ctx).Errorw("msg", "[biz] SaveWebhook fail", "err", err)
+		return nil, ErrDBFailed
+	}
+	return hook, nil
+}
+
+// ListWebhooks 店铺的webhook
+func (uc *WebhookUsecase) ListWebhooks(ctx context.Context, storeID int64) ([]*model.StoreWebhook, error) {
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] ListWebhooks", "store_id", storeID)
+	hooks, err := uc.repo.ListWebhooks(ctx, storeID)
+	if err != nil {
+		uc.log.WithContext(ctx).Errorw("msg", "[biz] ListWebhooks fail", "err", err)
+		return nil, ErrDBFailed
+	}
+	return hooks, nil
+}
+
+// DeleteWebhook 商家删除webhook 删除后重试中的事件推送完当前这次后不再重试
+func (uc *WebhookUsecase) DeleteWebhook(ctx context.Context, id, storeID int64) error {
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] DeleteWebhook", "id", id, "store_id", storeID)
+	if _, err := uc.getStoreWebhook(ctx, id, storeID); err != nil {
+		return err
+	}
+	if err := uc.repo.DeleteWebhook(ctx, id); err != nil {
+		uc.log.WithContext(ctx).Errorw("msg", "[biz] DeleteWebhook fail", "err", err)
+		return ErrDBFailed
+	}
+	return nil
+}
+
+// TestWebhook 向webhook推送一次测试事件 不重试，推送失败的原因在结果中返回，不包含接收方的响应内容
+func (uc *WebhookUsecase) TestWebhook(ctx context.Context, id, storeID int64) (*WebhookTestResult, error) {
+	uc.log.WithContext(ctx).Debugw("msg", "[biz] TestWebhook", "id", id, "store_id", storeID)
+	hook, err := uc.getStoreWebhook(ctx, id, storeID)
+	if err != nil {
+		return nil, err
+	}
+	d, err := uc.newDelivery(webhook.EventPing, storeID, nil)
+	if err != nil {
+		return nil, err
+	}
+	ret := &WebhookTestResult{}
+	ret.StatusCode, err = uc.sender.Send(ctx, hook, d)
+	if err != nil {
+		ret.Error = err.Error()
+	}
+	return ret, nil
+}
+
+// NotifyReview 评分不高于webhook阈值的评价推送给店铺的webhook 服务停止后不再推送新的事件
+func (uc *WebhookUsecase) NotifyReview(ctx context.Context, event string, review *model.ReviewInfo) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if uc.stopped {
+		uc.log.WithContext(ctx).Warnw("msg", "[biz] NotifyReview after stop", "review_id", review.ReviewID, "event", event)
+		return
+	}
+	uc.wg.Add(1)
+	// 推送在请求结束后进行 保留链路信息，不跟随请求取消
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer uc.wg.Done()
+		uc.dispatch(ctx, event, review)
+	}()
+}
+
+// Stop 停止推送 等待进行中的推送结束，还在等待重试的事件直接写入死信
+func (uc *WebhookUsecase) Stop(ctx context.Context) {
+	uc.mu.Lock()
+	if uc.stopped {
+		uc.mu.Unlock()
+		return
+	}
+	uc.stopped = true
+	close(uc.stop)
+	uc.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		uc.wg.Wait()
+		close(done)
+	}()
+	ctx, cancel := context.WithTimeout(ctx, webhookStopTimeout)
+	defer cancel()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		uc.log.Warnw("msg", "[biz] webhook stop timeout, some events may be lost")
+	}
+}
+
+func (uc *WebhookUsecase) dispatch(ctx context.Context, event string, review *model.ReviewInfo) {
+	hooks, err := uc.repo.ListWebhooks(ctx, review.StoreID)
+	if err != nil {
+		uc.log.WithContext(ctx).Errorw("msg", "[biz] NotifyReview ListWebhooks fail", "review_id", review.ReviewID, "err", err)
+		return
+	}
+	var d *WebhookDelivery
+	for _, hook := range hooks {
+		if hook.Enabled != 1 || review.Score > hook.MaxScore {
+			continue
+		}
+		if d == nil {
+			if d, err = uc.newDelivery(event, review.StoreID, review); err != nil {
+				uc.log.WithContext(ctx).Errorw("msg", "[biz] NotifyReview newDelivery fail", "review_id", review.ReviewID, "err", err)
+				return
+			}
+		}
+		uc.deliver(ctx, hook, d)
+	}
+}
+
+// deliver 推送事件 失败时按指数退避重试
+func (uc *WebhookUsecase) deliver(ctx context.Context, hook *model.StoreWebhook, d *WebhookDelivery) {
+	backoff := uc.backoff
+	var attempts int32
+	var lastErr error
+	for attempts < webhookMaxAttempts {
+		attempts++
+		if _, lastErr = uc.send(ctx, hook, d); lastErr == nil {
+			return
+		}
+		uc.log.WithContext(ctx).Warnw("msg", "[biz] webhook send fail", "webhook_id", hook.ID, "event_id", d.ID, "attempts", attempts, "err", lastErr)
+		if attempts == webhookMaxAttempts {
+			break
+		}
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-uc.stop:
+			uc.saveDeadLetter(ctx, hook, d, attempts, lastErr)
+			return
+		}
+		// 等待重试期间webhook被删除或停用时不再推送
+		latest, err := uc.repo.GetWebhook(ctx, hook.ID)
+		if errors.Is(err, ErrWebhookNotFound) || (err == nil && latest.Enabled != 1) {
+			return
+		}
+	}
+	uc.saveDeadLetter(ctx, hook, d, attempts, lastErr)
+}
+
+func (uc *WebhookUsecase) send(ctx context.Context, hook *model.StoreWebhook, d *WebhookDelivery) (int, error) {
+	uc.inflight <- struct{}{}
+	defer func() { <-uc.inflight }()
+	return uc.sender.Send(ctx, hook, d)
+}
+
+func (uc *WebhookUsecase) saveDeadLetter(ctx context.Context, hook *model.StoreWebhook, d *WebhookDelivery, attempts int32, lastErr error) {
+	msg := lastErr.Error()
+	if len(msg) > webhookMaxErrorLen {
+		msg = msg[:webhookMaxErrorLen]
+	}
+	letter := &model.WebhookDeadLetter{
+		WebhookID: hook.ID,
+		StoreID:   hook.StoreID,
+		EventID:   d.ID,
+		Event:     d.Event,
+		URL:       hook.URL,
+		Payload:   string(d.Payload),
+		Attempts:  attempts,
+		LastError: msg,
+	}
+	if err := uc.repo.SaveDeadLetter(ctx, letter); err != nil {
+		uc.log.WithContext(ctx).Errorw("msg", "[biz] SaveDeadLetter fail", "webhook_id", hook.ID, "event_id", d.ID, "err", err)
+	}
+}
+
+// newDelivery 生成事件 同一个事件推送给店铺所有的webhook
+func (uc *WebhookUsecase) newDelivery(event string, storeID int64, review *model.ReviewInfo) (*WebhookDelivery, error) {
+	id, err := uc.idgen.NextID()
+	if err != nil {
+		return nil, ErrGenID
+	}
+	now := time.Now()
+	e := &webhook.Event{
+		ID:        strconv.FormatInt(id, 10),
+		Type:      event,
+		StoreID:   storeID,
+		CreatedAt: now.Format(time.DateTime),
+	}
+	if review != nil {
+		// 刚创建的评价创建时间由数据库生成，还没有回填
+		createAt := review.CreateAt
+		if createAt.IsZero() {
+			createAt = now
+		}
+		e.Review = &webhook.Review{
+			ReviewID:     review.ReviewID,
+			Score:        review.Score,
+			ServiceScore: review.ServiceScore,
+			ExpressScore: review.ExpressScore,
+			Content:      review.Content,
+			Status:       review.Status,
+			CreateAt:     createAt.Format(time.DateTime),
+		}
+		// 匿名评价的订单号同样能对应到用户，和用户id一起不推送
+		if review.Anonymous != 1 {
+			e.Review.OrderID = review.OrderID
+			e.Review.UserID = review.UserID
+		}
+	}
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookDelivery{ID: e.ID, Event: event, Payload: payload}, nil
+}
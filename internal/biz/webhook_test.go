@@ -0,0 +1,298 @@
+package biz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"review-service/internal/data/model"
+	"review-service/pkg/snowflake"
+	"review-service/pkg/webhook"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// fakeWebhookRepo 内存中的webhook和死信
+type fakeWebhookRepo struct {
+	mu        sync.Mutex
+	hooks     map[int64]*model.StoreWebhook
+	letters   []*model.WebhookDeadLetter
+	letterErr error // 保存死信失败时返回的错误
+}
+
+func (r *fakeWebhookRepo) ListWebhooks(_ context.Context, storeID int64) ([]*model.StoreWebhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var ret []*model.StoreWebhook
+	for _, hook := range r.hooks {
+		if hook.StoreID == storeID {
+			h := *hook
+			ret = append(ret, &h)
+		}
+	}
+	return ret, nil
+}
+
+func (r *fakeWebhookRepo) GetWebhook(_ context.Context, id int64) (*model.StoreWebhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	hook, ok := r.hooks[id]
+	if !ok {
+		return nil, ErrWebhookNotFound
+	}
+	h := *hook
+	return &h, nil
+}
+
+func (r *fakeWebhookRepo) SaveWebhook(_ context.Context, hook *model.StoreWebhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	h := *hook
+	r.hooks[hook.ID] = &h
+	return nil
+}
+
+func (r *fakeWebhookRepo) DeleteWebhook(_ context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.hooks, id)
+	return nil
+}
+
+func (r *fakeWebhookRepo) SaveDeadLetter(_ context.Context, letter *model.WebhookDeadLetter) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.letterErr != nil {
+		return r.letterErr
+	}
+	r.letters = append(r.letters, letter)
+	return nil
+}
+
+func (r *fakeWebhookRepo) deadLetters() []*model.WebhookDeadLetter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*model.WebhookDeadLetter(nil), r.letters...)
+}
+
+// httpSender 直接通过HTTP推送 不做地址检查，地址检查见 data 包的测试
+type httpSender struct {
+	checkErr error
+}
+
+func (s *httpSender) CheckURL(context.Context, string) error {
+	return s.checkErr
+}
+
+func (s *httpSender) Send(ctx context.Context, hook *model.StoreWebhook, d *WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	webhook.SetHeaders(req.Header, hook.Secret, d.Event, d.ID, time.Now(), d.Payload)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// webhookReceiver 记录每次推送的时间 按handle返回状态码
+type webhookReceiver struct {
+	mu     sync.Mutex
+	hits   []time.Time
+	handle func(n int) int
+}
+
+func (rv *webhookReceiver) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	rv.mu.Lock()
+	rv.hits = append(rv.hits, time.Now())
+	n := len(rv.hits)
+	rv.mu.Unlock()
+	w.WriteHeader(rv.handle(n))
+}
+
+func (rv *webhookReceiver) times() []time.Time {
+	rv.mu.Lock()
+	defer rv.mu.Unlock()
+	return append([]time.Time(nil), rv.hits...)
+}
+
+const testBackoff = 20 * time.Millisecond
+
+func newTestWebhookUsecase(t *testing.T, rv *webhookReceiver) (*WebhookUsecase, *fakeWebhookRepo) {
+	srv := httptest.NewServer(rv)
+	t.Cleanup(srv.Close)
+	repo := &fakeWebhookRepo{hooks: map[int64]*model.StoreWebhook{
+		1: {ID: 1, StoreID: 10, URL: srv.URL, Secret: "x", MaxScore: NegativeScore, Enabled: 1},
+	}}
+	idgen, err := snowflake.NewGenerator("2024-01-01", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uc := NewWebhookUsecase(repo, &httpSender{}, idgen, log.DefaultLogger)
+	uc.backoff = testBackoff
+	return uc, repo
+}
+
+var negativeReview = &model.ReviewInfo{ReviewID: 100, StoreID: 10, OrderID: 1000, UserID: 1, Score: 1}
+
+func TestWebhookRetryBackoff(t *testing.T) {
+	rv := &webhookReceiver{handle: func(n int) int {
+		if n <= 3 {
+			return http.StatusServiceUnavailable
+		}
+		return http.StatusOK
+	}}
+	uc, repo := newTestWebhookUsecase(t, rv)
+	uc.dispatch(context.Background(), webhook.EventReviewCreated, negativeReview)
+
+	hits := rv.times()
+	if len(hits) != 4 {
+		t.Fatalf("hits = %d, want 4", len(hits))
+	}
+	// 每次重试的等待时间翻倍
+	for i := 1; i < len(hits); i++ {
+		want := testBackoff << (i - 1)
+		if gap := hits[i].Sub(hits[i-1]); gap < want {
+			t.Errorf("retry %d after %v, want at least %v", i, gap, want)
+		}
+	}
+	if letters := repo.deadLetters(); len(letters) != 0 {
+		t.Fatalf("dead letters = %d, want 0", len(letters))
+	}
+}
+
+func TestWebhookDeadLetter(t *testing.T) {
+	rv := &webhookReceiver{handle: func(int) int { return http.StatusBadGateway }}
+	uc, repo := newTestWebhookUsecase(t, rv)
+	uc.dispatch(context.Background(), webhook.EventReviewCreated, negativeReview)
+
+	if n := len(rv.times()); n != webhookMaxAttempts {
+		t.Fatalf("hits = %d, want %d", n, webhookMaxAttempts)
+	}
+	letters := repo.deadLetters()
+	if len(letters) != 1 {
+		t.Fatalf("dead letters = %d, want 1", len(letters))
+	}
+	l := letters[0]
+	if l.WebhookID != 1 || l.StoreID != 10 || l.Attempts != webhookMaxAttempts || !strings.Contains(l.LastError, "502") || l.Payload == "" {
+		t.Fatalf("dead letter = %+v", l)
+	}
+}
+
+func TestWebhookStopRetry(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(repo *fakeWebhookRepo)
+	}{
+		{name: "deleted", change: func(repo *fakeWebhookRepo) {
+			_ = repo.DeleteWebhook(context.Background(), 1)
+		}},
+		{name: "disabled", change: func(repo *fakeWebhookRepo) {
+			repo.mu.Lock()
+			repo.hooks[1].Enabled = 0
+			repo.mu.Unlock()
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var repo *fakeWebhookRepo
+			rv := &webhookReceiver{handle: func(int) int {
+				// 第一次推送失败后 商家删除或停用了webhook
+				tt.change(repo)
+				return http.StatusInternalServerError
+			}}
+			uc, r := newTestWebhookUsecase(t, rv)
+			repo = r
+			uc.dispatch(context.Background(), webhook.EventReviewCreated, negativeReview)
+
+			if n := len(rv.times()); n != 1 {
+				t.Fatalf("hits = %d, want 1", n)
+			}
+			if letters := repo.deadLetters(); len(letters) != 0 {
+				t.Fatalf("dead letters = %d, want 0", len(letters))
+			}
+		})
+	}
+}
+
+func TestSaveWebhookRejectsPrivateURL(t *testing.T) {
+	repo := &fakeWebhookRepo{hooks: map[int64]*model.StoreWebhook{}}
+	sender := &httpSender{checkErr: errors.New("webhook address is not a public address")}
+	uc := NewWebhookUsecase(repo, sender, nil, log.DefaultLogger)
+	_, err := uc.SaveWebhook(context.Background(), &model.StoreWebhook{StoreID: 10, URL: "http://169.254.169.254/latest/meta-data/"})
+	if !errors.Is(err, ErrInvalidWebhookURL) {
+		t.Fatalf("SaveWebhook() = %v, want ErrInvalidWebhookURL", err)
+	}
+	if len(repo.hooks) != 0 {
+		t.Fatal("webhook with a private address was saved")
+	}
+}
+
+func TestWebhookPayloadAnonymous(t *testing.T) {
+	tests := []struct {
+		name      string
+		anonymous int32
+		orderID   int64
+		userID    int64
+	}{
+		{name: "public review", anonymous: 0, orderID: 1000, userID: 1},
+		// 匿名评价的订单号能对应到用户 和用户id一样不推送
+		{name: "anonymous review", anonymous: 1},
+	}
+	idgen, err := snowflake.NewGenerator("2024-01-01", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uc := NewWebhookUsecase(&fakeWebhookRepo{}, &httpSender{}, idgen, log.DefaultLogger)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			review := *negativeReview
+			review.Anonymous = tt.anonymous
+			d, err := uc.newDelivery(webhook.EventReviewCreated, review.StoreID, &review)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var e webhook.Event
+			if err := json.Unmarshal(d.Payload, &e); err != nil {
+				t.Fatal(err)
+			}
+			if e.Review.OrderID != tt.orderID || e.Review.UserID != tt.userID {
+				t.Fatalf("order_id = %d, user_id = %d, want %d, %d", e.Review.OrderID, e.Review.UserID, tt.orderID, tt.userID)
+			}
+			if tt.anonymous == 1 && (bytes.Contains(d.Payload, []byte("order_id")) || bytes.Contains(d.Payload, []byte("user_id"))) {
+				t.Fatalf("payload = %s", d.Payload)
+			}
+		})
+	}
+}
+
+func TestWebhookDeadLetterLog(t *testing.T) {
+	var buf bytes.Buffer
+	repo := &fakeWebhookRepo{letterErr: errors.New("db is down")}
+	uc := NewWebhookUsecase(repo, &httpSender{}, nil, log.NewStdLogger(&buf))
+	hook := &model.StoreWebhook{ID: 1, StoreID: 10, URL: "https://example.com/hook"}
+	d := &WebhookDelivery{ID: "1001", Event: webhook.EventReviewCreated, Payload: []byte(`{"review":{"content":"难吃的要命"}}`)}
+	uc.saveDeadLetter(context.Background(), hook, d, webhookMaxAttempts, errors.New("unexpected status 500"))
+
+	out := buf.String()
+	if !strings.Contains(out, "SaveDeadLetter fail") || !strings.Contains(out, "1001") {
+		t.Fatalf("log = %q, want the failure with the event id", out)
+	}
+	if strings.Contains(out, "难吃的要命") {
+		t.Fatalf("log leaks the review content: %q", out)
+	}
+}
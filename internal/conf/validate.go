@@ -0,0 +1,204 @@
+package conf
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"review-service/pkg/snowflake"
+)
+
+// 启动时校验配置 配置有误时直接返回指明字段的错误，避免带着错误的配置跑起来
+
+// Validate 校验启动必需的配置
+func (x *Bootstrap) Validate() error {
+	if err := x.GetServer().Validate(); err != nil {
+		return err
+	}
+	if err := x.GetData().Validate(); err != nil {
+		return err
+	}
+	if err := x.GetSnowflake().Validate(); err != nil {
+		return err
+	}
+	if err := x.GetElasticsearch().Validate(); err != nil {
+		return err
+	}
+	if err := x.GetExport().Validate(); err != nil {
+		return err
+	}
+	return x.GetRuntime().Validate()
+}
+
+// Validate 校验运行时配置 热更新时也会校验，校验失败时保留原来的配置
+func (x *Runtime) Validate() error {
+	if x == nil {
+		return nil
+	}
+	if x.GetListCacheTtl().AsDuration() < 0 {
+		return invalid("runtime.list_cache_ttl", "must not be negative")
+	}
+	if x.GetDashboardCacheTtl().AsDuration() < 0 {
+		return invalid("runtime.dashboard_cache_ttl", "must not be negative")
+	}
+	if x.GetCreateReviewPerMinute() < 0 {
+		return invalid("runtime.create_review_per_minute", "must not be negative")
+	}
+	if x.GetReportHideThreshold() < 0 {
+		return invalid("runtime.report_hide_threshold", "must not be negative")
+	}
+	if x.GetMaxThreadLength() < 0 {
+		return invalid("runtime.max_thread_length", "must not be negative")
+	}
+	for i, w := range x.GetReplyBlockedWords() {
+		if strings.TrimSpace(w) == "" {
+			return invalid(fmt.Sprintf("runtime.reply_blocked_words[%d]", i), "must not be empty")
+		}
+	}
+	return nil
+}
+
+// Validate 校验服务的配置
+func (x *Server) Validate() error {
+	if x.GetGraphql().GetMaxDepth() < 0 {
+		return invalid("server.graphql.max_depth", "must not be negative")
+	}
+	if x.GetGraphql().GetMaxComplexity() < 0 {
+		return invalid("server.graphql.max_complexity", "must not be negative")
+	}
+	return nil
+}
+
+// Validate 校验雪花算法的配置
+func (x *Snowflake) Validate() error {
+	if x == nil || x.GetStartTime() == "" {
+		return invalid("snowflake.start_time", "is required")
+	}
+	if _, err := snowflake.ParseEpoch(x.GetStartTime()); err != nil {
+		return invalid("snowflake.start_time", "must be formatted as 2006-01-02, got %q", x.GetStartTime())
+	}
+	if x.GetMachineId() < 0 || x.GetMachineId() > snowflake.MaxMachineID {
+		return invalid("snowflake.machine_id", "must be between 0 and %d", snowflake.MaxMachineID)
+	}
+	switch x.GetClockPolicy() {
+	case "", "wait", "fail":
+	default:
+		return invalid("snowflake.clock_policy", "must be wait or fail, got %q", x.GetClockPolicy())
+	}
+	if x.GetMaxWait().AsDuration() < 0 {
+		return invalid("snowflake.max_wait", "must not be negative")
+	}
+	if x.GetLeaseTtl().AsDuration() < 0 {
+		return invalid("snowflake.lease_ttl", "must not be negative")
+	}
+	return nil
+}
+
+// Validate 校验数据库和Redis的配置
+func (x *Data) Validate() error {
+	if x == nil {
+		return invalid("data", "is required")
+	}
+	db := x.GetDatabase()
+	if db == nil {
+		return invalid("data.database", "is required")
+	}
+	switch strings.ToLower(db.GetDriver()) {
+	case "mysql", "sqlite":
+	default:
+		return invalid("data.database.driver", "must be mysql or sqlite, got %q", db.GetDriver())
+	}
+	if db.GetSource() == "" {
+		return invalid("data.database.source", "is required")
+	}
+	if db.GetMaxOpenConns() < 0 {
+		return invalid("data.database.max_open_conns", "must not be negative")
+	}
+	if db.GetMaxIdleConns() < 0 {
+		return invalid("data.database.max_idle_conns", "must not be negative")
+	}
+	if db.GetMaxOpenConns() > 0 && db.GetMaxIdleConns() > db.GetMaxOpenConns() {
+		return invalid("data.database.max_idle_conns", "must not exceed max_open_conns(%d)", db.GetMaxOpenConns())
+	}
+	if db.GetConnMaxLifetime().AsDuration() < 0 {
+		return invalid("data.database.conn_max_lifetime", "must not be negative")
+	}
+	if db.GetConnMaxIdleTime().AsDuration() < 0 {
+		return invalid("data.database.conn_max_idle_time", "must not be negative")
+	}
+	if len(db.GetReplicas()) > 0 && strings.ToLower(db.GetDriver()) != "mysql" {
+		return invalid("data.database.replicas", "only supported with mysql")
+	}
+	for i, dsn := range db.GetReplicas() {
+		if dsn == "" {
+			return invalid(fmt.Sprintf("data.database.replicas[%d]", i), "must not be empty")
+		}
+	}
+
+	if sh := x.GetSharding(); sh != nil {
+		if n := sh.GetTables(); n < 0 || n > snowflake.SlotCount || n&(n-1) != 0 {
+			return invalid("data.sharding.tables", "must be a power of 2 not greater than %d, got %d", snowflake.SlotCount, n)
+		}
+		switch sh.GetKey() {
+		case "", "user_id", "store_id":
+		default:
+			return invalid("data.sharding.key", "must be user_id or store_id, got %q", sh.GetKey())
+		}
+		if sh.GetLegacyMaxId() < 0 {
+			return invalid("data.sharding.legacy_max_id", "must not be negative")
+		}
+	}
+
+	rdb := x.GetRedis()
+	if rdb == nil {
+		return invalid("data.redis", "is required")
+	}
+	if rdb.GetAddr() == "" {
+		return invalid("data.redis.addr", "is required")
+	}
+	if rdb.GetPoolSize() < 0 {
+		return invalid("data.redis.pool_size", "must not be negative")
+	}
+	if rdb.GetMinIdleConns() < 0 {
+		return invalid("data.redis.min_idle_conns", "must not be negative")
+	}
+	if rdb.GetMaxRetries() < -1 {
+		return invalid("data.redis.max_retries", "must be -1 (no retry) or greater")
+	}
+	return nil
+}
+
+// Validate 校验ES的配置
+func (x *Elasticsearch) Validate() error {
+	if x == nil || len(x.GetAddresses()) == 0 {
+		return invalid("elasticsearch.addresses", "is required")
+	}
+	for _, addr := range x.GetAddresses() {
+		u, err := url.Parse(addr)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return invalid("elasticsearch.addresses", "%q is not a valid http(s) URL", addr)
+		}
+	}
+	if x.GetMaxIdleConnsPerHost() < 0 {
+		return invalid("elasticsearch.max_idle_conns_per_host", "must not be negative")
+	}
+	if x.GetResponseHeaderTimeout().AsDuration() < 0 {
+		return invalid("elasticsearch.response_header_timeout", "must not be negative")
+	}
+	if x.GetMaxRetries() < 0 {
+		return invalid("elasticsearch.max_retries", "must not be negative")
+	}
+	return nil
+}
+
+// Validate 校验评价导出的配置 下载地址前缀必须以/结尾
+func (x *Export) Validate() error {
+	if p := x.GetUrlPrefix(); p != "" && !strings.HasSuffix(p, "/") {
+		return invalid("export.url_prefix", "must end with /")
+	}
+	return nil
+}
+
+func invalid(field, format string, args ...interface{}) error {
+	return fmt.Errorf("invalid config %s: %s", field, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,150 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"review-service/internal/biz"
+	"review-service/internal/data/query"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/some"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/sortorder"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 评价内容分析的存储
+// 分析结果合并到 review_info.ext_json，同步任务会把ext_json随整行写入 review 索引，但只是一个字符串，不能用于筛选
+// 所以情感和关键词同时写入 review_stats 索引，按情感筛选评价和统计关键词都查 review_stats
+
+// SaveReviewAnalysis 加锁读出ext_json，合并分析结果后整体写回，不覆盖ext_json中的其它字段
+func (r *reviewRepo) SaveReviewAnalysis(ctx context.Context, reviewID int64, a *biz.ContentAnalysis) error {
+	table, err := r.reviewTable(ctx, reviewID)
+	if err != nil {
+		return err
+	}
+	err = r.data.query.Transaction(func(tx *query.Query) error {
+		ri := tx.ReviewInfo.Table(table)
+		review, err := ri.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select(ri.ReviewID, ri.ExtJSON).Where(ri.ReviewID.Eq(reviewID)).First()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return biz.ErrReviewNotFound
+		}
+		if err != nil {
+			return err
+		}
+		ext := biz.ParseReviewExt(review)
+		ext.SetAnalysis(a)
+		if err := biz.SetReviewExt(review, ext); err != nil {
+			return err
+		}
+		_, err = ri.WithContext(ctx).Where(ri.ReviewID.Eq(reviewID)).Update(ri.ExtJSON, review.ExtJSON)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	r.updateReviewStats(ctx, reviewID, map[string]interface{}{
+		"sentiment":       a.Sentiment,
+		"sentiment_score": a.SentimentScore,
+		"keywords":        a.Keywords,
+	})
+	return nil
+}
+
+// TopKeywords 按出现的评价数统计关键词 审核不通过和隐藏的评价不参与统计
+func (r *reviewRepo) TopKeywords(ctx context.Context, storeID int64, sentiment string, size int) ([]*biz.KeywordCount, error) {
+	resp, err := r.data.es.Search().Index(statsIndex).Size(0).TypedKeys(true).
+		Query(withSentiment(statsFilter(storeID, 0), sentiment)).
+		Aggregations(map[string]types.Aggregations{
+			"keywords": {Terms: &types.TermsAggregation{Field: some.String("keywords.keyword"), Size: some.Int(size)}},
+		}).Do(ctx)
+	if isESNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	agg, ok := resp.Aggregations["keywords"].(*types.StringTermsAggregate)
+	if !ok {
+		return nil, nil
+	}
+	buckets, _ := agg.Buckets.([]types.StringTermsBucket)
+	ret := make([]*biz.KeywordCount, 0, len(buckets))
+	for _, b := range buckets {
+		if kw, ok := b.Key.(string); ok {
+			ret = append(ret, &biz.KeywordCount{Keyword: kw, Count: b.DocCount})
+		}
+	}
+	return ret, nil
+}
+
+// withSentiment 在过滤条件中加上情感倾向
+func withSentiment(q *types.Query, sentiment string) *types.Query {
+	q.Bool.Filter = append(q.Bool.Filter, types.Query{
+		Term: map[string]types.TermQuery{"sentiment.keyword": {Value: sentiment}},
+	})
+	return q
+}
+
+// searchBySentiment 按情感筛选商家的评价 先从 review_stats 中查出一页评价ID，再从 review 索引中取评价
+// 评价ID由雪花算法生成，按ID倒序即按创建时间倒序
+// 和不筛选时一样返回店铺所有状态的评价，返回值是 review 索引的查询结果，可以一样写入缓存
+func (r *reviewRepo) searchBySentiment(ctx context.Context, storeID int64, sentiment string, offset, limit int) ([]byte, error) {
+	resp, err := r.data.es.Search().Index(statsIndex).From(offset).Size(limit).
+		Query(withSentiment(&types.Query{
+			Bool: &types.BoolQuery{
+				Filter: []types.Query{{Term: map[string]types.TermQuery{"store_id": {Value: storeID}}}},
+			},
+		}, sentiment)).
+		Sort(types.SortOptions{SortOptions: map[string]types.FieldSort{"review_id": {Order: &sortorder.Desc}}}).
+		Source_(types.SourceFilter{Includes: []string{"review_id"}}).Do(ctx)
+	if isESNotFound(err) {
+		return json.Marshal(&types.HitsMetadata{Total: &types.TotalHits{}})
+	}
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(resp.Hits.Hits))
+	for _, hit := range resp.Hits.Hits {
+		var doc statsDoc
+		if err := json.Unmarshal(hit.Source_, &doc); err != nil {
+			return nil, err
+		}
+		ids = append(ids, strconv.FormatInt(doc.ReviewID, 10))
+	}
+	hits := &types.HitsMetadata{Total: resp.Hits.Total}
+	if len(ids) == 0 {
+		return json.Marshal(hits)
+	}
+	reviews, err := r.data.es.Search().Index("review").Size(len(ids)).
+		Query(&types.Query{
+			Bool: &types.BoolQuery{
+				Filter: []types.Query{
+					{Terms: &types.TermsQuery{TermsQuery: map[string]types.TermsQueryField{"review_id": ids}}},
+				},
+			},
+		}).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// 按 review_stats 中的顺序返回 同步任务还没有写入的评价跳过
+	byID := make(map[string]types.Hit, len(reviews.Hits.Hits))
+	for _, hit := range reviews.Hits.Hits {
+		var doc struct {
+			ReviewID json.Number `json:"review_id"`
+		}
+		if err := json.Unmarshal(hit.Source_, &doc); err == nil {
+			byID[doc.ReviewID.String()] = hit
+		}
+	}
+	for _, id := range ids {
+		if hit, ok := byID[id]; ok {
+			hits.Hits = append(hits.Hits, hit)
+		}
+	}
+	return json.Marshal(hits)
+}
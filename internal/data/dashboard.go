@@ -0,0 +1,179 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"review-service/internal/biz"
+	"review-service/internal/metrics"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/search"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/some"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/calendarinterval"
+	"github.com/redis/go-redis/v9"
+)
+
+// 商家评价看板
+// 评价数、平均分、差评数和回复数从 review 索引统计，回复耗时只有 review_stats 索引中有，两次查询的结果按日期合并
+// ES中的时间没有时区，按写入时的本地时间分桶
+
+// GetStoreDashboard 商家评价看板 先查Redis缓存，缓存没有时通过singleflight查询ES
+func (r *reviewRepo) GetStoreDashboard(ctx context.Context, param *biz.DashboardParam) ([]*biz.DashboardPoint, error) {
+	key := fmt.Sprintf("review:dashboard:%d:%s:%s:%s", param.StoreID,
+		param.Start.Format(time.DateOnly), param.End.Format(time.DateOnly), param.Interval)
+	v, err, shared := g.Do(key, func() (interface{}, error) {
+		data, err := r.data.rdb.Get(ctx, key).Bytes()
+		if err == nil {
+			metrics.CacheRequests.WithLabelValues("hit").Inc()
+			return data, nil
+		}
+		if !errors.Is(err, redis.Nil) {
+			metrics.CacheRequests.WithLabelValues("error").Inc()
+			return nil, err
+		}
+		metrics.CacheRequests.WithLabelValues("miss").Inc()
+		points, err := r.dashboardFromES(ctx, param)
+		if err != nil {
+			return nil, err
+		}
+		if data, err = json.Marshal(points); err != nil {
+			return nil, err
+		}
+		return data, r.data.rdb.Set(ctx, key, data, r.runtime.Load().DashboardCacheTTL).Err()
+	})
+	r.log.WithContext(ctx).Debugw("msg", "dashboard singleflight result", "key", key, "shared", shared, "err", err)
+	metrics.SingleflightRequests.WithLabelValues(strconv.FormatBool(shared)).Inc()
+	if err != nil {
+		return nil, err
+	}
+	var points []*biz.DashboardPoint
+	if err := json.Unmarshal(v.([]byte), &points); err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// dashboardFromES 从ES统计看板数据
+func (r *reviewRepo) dashboardFromES(ctx context.Context, param *biz.DashboardParam) ([]*biz.DashboardPoint, error) {
+	resp, err := r.searchDashboard(ctx, "review", param, map[string]types.Aggregations{
+		"score": {Avg: &types.AverageAggregation{Field: some.String("score")}},
+		"negative": {Filter: &types.Query{Range: map[string]types.RangeQuery{
+			"score": types.NumberRangeQuery{Lte: some.Float64(biz.NegativeScore)},
+		}}},
+		"replied": {Filter: &types.Query{Term: map[string]types.TermQuery{"has_reply": {Value: 1}}}},
+	})
+	if isESNotFound(err) {
+		return emptyDashboard(param), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	points := make([]*biz.DashboardPoint, 0)
+	byDate := make(map[string]*biz.DashboardPoint)
+	for _, b := range dateBuckets(resp) {
+		p := &biz.DashboardPoint{Date: bucketDate(b), ReviewCount: b.DocCount}
+		if agg, ok := b.Aggregations["score"].(*types.AvgAggregate); ok {
+			p.AverageScore = float64(agg.Value)
+		}
+		if agg, ok := b.Aggregations["negative"].(*types.FilterAggregate); ok {
+			p.NegativeCount = agg.DocCount
+		}
+		if agg, ok := b.Aggregations["replied"].(*types.FilterAggregate); ok {
+			p.ReplyCount = agg.DocCount
+		}
+		if p.ReviewCount > 0 {
+			p.ReplyRate = float64(p.ReplyCount) / float64(p.ReviewCount)
+		}
+		points = append(points, p)
+		byDate[p.Date] = p
+	}
+
+	resp, err = r.searchDashboard(ctx, statsIndex, param, map[string]types.Aggregations{
+		"reply_seconds": {Avg: &types.AverageAggregation{Field: some.String("reply_seconds")}},
+	})
+	if isESNotFound(err) {
+		return points, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range dateBuckets(resp) {
+		p, ok := byDate[bucketDate(b)]
+		if !ok {
+			continue
+		}
+		if agg, ok := b.Aggregations["reply_seconds"].(*types.AvgAggregate); ok {
+			p.AverageReplySeconds = float64(agg.Value)
+		}
+	}
+	return points, nil
+}
+
+// searchDashboard 按创建时间分桶统计店铺的评价 没有评价的周期也返回空桶
+func (r *reviewRepo) searchDashboard(ctx context.Context, index string, param *biz.DashboardParam, aggs map[string]types.Aggregations) (*search.Response, error) {
+	interval := calendarinterval.Day
+	if param.Interval == biz.IntervalWeek {
+		interval = calendarinterval.Week
+	}
+	start := param.Start.Format(time.DateOnly)
+	end := param.End.Format(time.DateOnly)
+	query := statsFilter(param.StoreID, 0)
+	query.Bool.Filter = append(query.Bool.Filter, types.Query{Range: map[string]types.RangeQuery{
+		"create_at": types.DateRangeQuery{
+			Gte:    some.String(start),
+			Lt:     some.String(param.End.AddDate(0, 0, 1).Format(time.DateOnly)),
+			Format: some.String("yyyy-MM-dd"),
+		},
+	}})
+	return r.data.es.Search().Index(index).Size(0).TypedKeys(true).
+		Query(query).
+		Aggregations(map[string]types.Aggregations{
+			"dates": {
+				DateHistogram: &types.DateHistogramAggregation{
+					Field:            some.String("create_at"),
+					CalendarInterval: &interval,
+					Format:           some.String("yyyy-MM-dd"),
+					MinDocCount:      some.Int(0),
+					ExtendedBounds:   &types.ExtendedBoundsFieldDateMath{Min: start, Max: end},
+				},
+				Aggregations: aggs,
+			},
+		}).Do(ctx)
+}
+
+func dateBuckets(resp *search.Response) []types.DateHistogramBucket {
+	agg, ok := resp.Aggregations["dates"].(*types.DateHistogramAggregate)
+	if !ok {
+		return nil
+	}
+	buckets, _ := agg.Buckets.([]types.DateHistogramBucket)
+	return buckets
+}
+
+func bucketDate(b types.DateHistogramBucket) string {
+	if b.KeyAsString != nil {
+		return *b.KeyAsString
+	}
+	return time.UnixMilli(b.Key).UTC().Format(time.DateOnly)
+}
+
+// emptyDashboard 索引不存在时 每个周期返回一个空的数据点
+func emptyDashboard(param *biz.DashboardParam) []*biz.DashboardPoint {
+	start := param.Start
+	step := 1
+	if param.Interval == biz.IntervalWeek {
+		// 与ES的自然周对齐到周一
+		start = start.AddDate(0, 0, -(int(start.Weekday())+6)%7)
+		step = 7
+	}
+	points := make([]*biz.DashboardPoint, 0)
+	for d := start; !d.After(param.End); d = d.AddDate(0, 0, step) {
+		points = append(points, &biz.DashboardPoint{Date: d.Format(time.DateOnly)})
+	}
+	return points
+}
@@ -0,0 +1,135 @@
+package data
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"review-service/internal/biz"
+	"review-service/internal/conf"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// newTestESRepo 查询发到httptest的ES 按索引返回固定的响应 索引没有响应时返回404
+func newTestESRepo(t *testing.T, responses map[string]string) *reviewRepo {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		index := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")[0]
+		body, ok := responses[index]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			body = `{"error":{"type":"index_not_found_exception","reason":"no such index"},"status":404}`
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	es, cleanup, err := NewESClient(&conf.Elasticsearch{Addresses: []string{srv.URL}})
+	if err != nil {
+		t.Fatalf("NewESClient() error = %v", err)
+	}
+	t.Cleanup(cleanup)
+	return &reviewRepo{data: &Data{es: es}, log: log.NewHelper(log.DefaultLogger)}
+}
+
+func dashboardParam(start, end, interval string) *biz.DashboardParam {
+	s, _ := time.ParseInLocation(time.DateOnly, start, time.Local)
+	e, _ := time.ParseInLocation(time.DateOnly, end, time.Local)
+	return &biz.DashboardParam{StoreID: 1, Start: s, End: e, Interval: interval}
+}
+
+func TestDashboardFromES(t *testing.T) {
+	const reviewResp = `{"took":1,"timed_out":false,"_shards":{"total":1,"successful":1,"skipped":0,"failed":0},
+"hits":{"total":{"value":6,"relation":"eq"},"hits":[]},
+"aggregations":{"date_histogram#dates":{"buckets":[
+{"key_as_string":"2024-01-01","key":1704067200000,"doc_count":4,"avg#score":{"value":3.5},"filter#negative":{"doc_count":1},"filter#replied":{"doc_count":3}},
+{"key_as_string":"2024-01-02","key":1704153600000,"doc_count":0,"avg#score":{"value":null},"filter#negative":{"doc_count":0},"filter#replied":{"doc_count":0}},
+{"key_as_string":"2024-01-03","key":1704240000000,"doc_count":2,"avg#score":{"value":1.5},"filter#negative":{"doc_count":2},"filter#replied":{"doc_count":0}}
+]}}}`
+	const statsResp = `{"took":1,"timed_out":false,"_shards":{"total":1,"successful":1,"skipped":0,"failed":0},
+"hits":{"total":{"value":3,"relation":"eq"},"hits":[]},
+"aggregations":{"date_histogram#dates":{"buckets":[
+{"key_as_string":"2024-01-01","key":1704067200000,"doc_count":3,"avg#reply_seconds":{"value":600}},
+{"key_as_string":"2023-12-31","key":1703980800000,"doc_count":1,"avg#reply_seconds":{"value":60}}
+]}}}`
+	tests := []struct {
+		name      string
+		responses map[string]string
+		param     *biz.DashboardParam
+		want      []*biz.DashboardPoint
+	}{
+		{
+			name:      "merge reply seconds by date",
+			responses: map[string]string{"review": reviewResp, statsIndex: statsResp},
+			param:     dashboardParam("2024-01-01", "2024-01-03", biz.IntervalDay),
+			want: []*biz.DashboardPoint{
+				{Date: "2024-01-01", ReviewCount: 4, AverageScore: 3.5, NegativeCount: 1, ReplyCount: 3, ReplyRate: 0.75, AverageReplySeconds: 600},
+				{Date: "2024-01-02"},
+				{Date: "2024-01-03", ReviewCount: 2, AverageScore: 1.5, NegativeCount: 2},
+			},
+		},
+		{
+			// 还没有回复过 统计索引不存在
+			name:      "no stats index",
+			responses: map[string]string{"review": reviewResp},
+			param:     dashboardParam("2024-01-01", "2024-01-03", biz.IntervalDay),
+			want: []*biz.DashboardPoint{
+				{Date: "2024-01-01", ReviewCount: 4, AverageScore: 3.5, NegativeCount: 1, ReplyCount: 3, ReplyRate: 0.75},
+				{Date: "2024-01-02"},
+				{Date: "2024-01-03", ReviewCount: 2, AverageScore: 1.5, NegativeCount: 2},
+			},
+		},
+		{
+			name:  "no review index",
+			param: dashboardParam("2024-01-01", "2024-01-02", biz.IntervalDay),
+			want:  []*biz.DashboardPoint{{Date: "2024-01-01"}, {Date: "2024-01-02"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestESRepo(t, tt.responses)
+			got, err := repo.dashboardFromES(context.Background(), tt.param)
+			if err != nil {
+				t.Fatalf("dashboardFromES() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				for i := range got {
+					t.Logf("got[%d] = %+v", i, got[i])
+				}
+				t.Fatalf("dashboardFromES() returned unexpected points")
+			}
+		})
+	}
+}
+
+func TestEmptyDashboard(t *testing.T) {
+	tests := []struct {
+		name  string
+		param *biz.DashboardParam
+		want  []string
+	}{
+		{name: "one day", param: dashboardParam("2024-01-03", "2024-01-03", biz.IntervalDay), want: []string{"2024-01-03"}},
+		{name: "days", param: dashboardParam("2024-01-30", "2024-02-02", biz.IntervalDay), want: []string{"2024-01-30", "2024-01-31", "2024-02-01", "2024-02-02"}},
+		// 2024-01-03是周三 对齐到周一
+		{name: "weeks from wednesday", param: dashboardParam("2024-01-03", "2024-01-15", biz.IntervalWeek), want: []string{"2024-01-01", "2024-01-08", "2024-01-15"}},
+		// 2024-01-07是周日
+		{name: "weeks from sunday", param: dashboardParam("2024-01-07", "2024-01-08", biz.IntervalWeek), want: []string{"2024-01-01", "2024-01-08"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, p := range emptyDashboard(tt.param) {
+				got = append(got, p.Date)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("emptyDashboard() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
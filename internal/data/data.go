@@ -1,53 +1,103 @@
 package data
 
 import (
+	"context"
 	"errors"
+	"net/http"
 	"strings"
+	"time"
 
 	"review-service/internal/conf"
+	"review-service/internal/data/migrate"
 	"review-service/internal/data/query"
+	"review-service/pkg/snowflake"
 
 	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/glebarez/sqlite"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/google/wire"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/plugin/opentelemetry/tracing"
 )
 
 // ProviderSet is data providers.
-var ProviderSet = wire.NewSet(NewData, NewReviewRepo, NewDB)
+var ProviderSet = wire.NewSet(NewData, NewReviewRepo, NewDimensionRepo, NewUserRepo, NewHealthRepo, NewDB, NewRedisClient, NewESClient, NewIDGenerator, NewRateLimiter,
+	NewWebhookRepo, NewWebhookSender, NewExportRepo, NewExportStorage, NewEventRepo)
 
 // Data .
 type Data struct {
 	// TODO wrapped database client
 	query *query.Query
+	db    *gorm.DB
 	log   *log.Helper
 	es    *elasticsearch.TypedClient
 	rdb   *redis.Client
+
+	sharding *sharding
+	idgen    snowflake.IDGenerator
 }
 
 // NewRedisClient RedisClient的构造函数
-func NewRedisClient(cfg *conf.Data) *redis.Client {
-	return redis.NewClient(&redis.Options{
+func NewRedisClient(cfg *conf.Data, logger log.Logger) (*redis.Client, func(), error) {
+	rdb := redis.NewClient(&redis.Options{
+		Network:      cfg.Redis.GetNetwork(),
 		Addr:         cfg.Redis.Addr,
 		WriteTimeout: cfg.Redis.WriteTimeout.AsDuration(),
 		ReadTimeout:  cfg.Redis.ReadTimeout.AsDuration(),
+		DialTimeout:  cfg.Redis.GetDialTimeout().AsDuration(),
+		PoolSize:     int(cfg.Redis.GetPoolSize()),
+		MinIdleConns: int(cfg.Redis.GetMinIdleConns()),
+		MaxRetries:   int(cfg.Redis.GetMaxRetries()),
 	})
+	cleanup := func() {
+		if err := rdb.Close(); err != nil {
+			log.NewHelper(logger).Errorf("close redis client failed,err:%v", err)
+		}
+	}
+	// 链路追踪 每条命令一个span
+	if err := redisotel.InstrumentTracing(rdb); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return rdb, cleanup, nil
 }
 
 // NewESClient ES client 的构造函数
-func NewESClient(cfg *conf.Elasticsearch) (*elasticsearch.TypedClient, error) {
+func NewESClient(cfg *conf.Elasticsearch) (*elasticsearch.TypedClient, func(), error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if n := cfg.GetMaxIdleConnsPerHost(); n > 0 {
+		transport.MaxIdleConnsPerHost = int(n)
+	}
+	transport.ResponseHeaderTimeout = cfg.GetResponseHeaderTimeout().AsDuration()
 	c := elasticsearch.Config{
 		Addresses: cfg.GetAddresses(),
+		// 链路追踪 每个HTTP请求一个span
+		Transport: otelhttp.NewTransport(transport),
+		// 重试间隔随重试次数线性增加
+		RetryBackoff: func(attempt int) time.Duration {
+			return time.Duration(attempt) * 100 * time.Millisecond
+		},
+	}
+	if n := cfg.GetMaxRetries(); n > 0 {
+		c.MaxRetries = int(n)
 	}
 	// 创建客户端连接
-	return elasticsearch.NewTypedClient(c)
+	client, err := elasticsearch.NewTypedClient(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	// ES客户端没有Close方法，关闭连接池中的空闲连接
+	return client, transport.CloseIdleConnections, nil
 }
 
 // NewData .
-func NewData(db *gorm.DB, esClient *elasticsearch.TypedClient, rdb *redis.Client, logger log.Logger) (*Data, func(), error) {
+// 各个客户端的cleanup由wire按创建的逆序调用: Redis --> ES --> MySQL
+// 服务(包括退出时刷投票计数的定时任务)在cleanup之前已经停止，这里不会再有新的请求
+func NewData(cfg *conf.Data, db *gorm.DB, esClient *elasticsearch.TypedClient, rdb *redis.Client, idgen snowflake.IDGenerator, logger log.Logger) (*Data, func(), error) {
 	cleanup := func() {
 		log.NewHelper(logger).Info("closing the data resources")
 	}
@@ -55,18 +105,73 @@ func NewData(db *gorm.DB, esClient *elasticsearch.TypedClient, rdb *redis.Client
 	query.SetDefault(db)
 	return &Data{
 		query: query.Q,
+		db:    db,
 		es:    esClient,
 		rdb:   rdb,
 		log:   log.NewHelper(logger),
+
+		sharding: newSharding(cfg.GetSharding()),
+		idgen:    idgen,
 	}, cleanup, nil
 }
 
-func NewDB(cfg *conf.Data) (*gorm.DB, error) {
+func NewDB(cfg *conf.Data, logger log.Logger) (*gorm.DB, func(), error) {
+	var dialector gorm.Dialector
 	switch strings.ToLower(cfg.Database.GetDriver()) {
 	case "mysql":
-		return gorm.Open(mysql.Open(cfg.Database.GetSource()))
+		dialector = mysql.Open(cfg.Database.GetSource())
 	case "sqlite":
-		return gorm.Open(sqlite.Open(cfg.Database.GetSource()))
+		dialector = sqlite.Open(cfg.Database.GetSource())
+	default:
+		return nil, nil, errors.New("connect db failed,unsupported driver")
+	}
+	db, err := gorm.Open(dialector)
+	if err != nil {
+		return nil, nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() {
+		if err := sqlDB.Close(); err != nil {
+			log.NewHelper(logger).Errorf("close db failed,err:%v", err)
+		}
+	}
+	// 连接池
+	setConnPool(sqlDB, cfg.Database)
+	// 链路追踪 每条SQL一个span，指标由服务自己的Prometheus指标负责
+	if err := db.Use(tracing.NewPlugin(tracing.WithoutMetrics())); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	// 表结构迁移 在启用读写分离之前执行，保证都在主库上
+	if cfg.Database.GetAutoMigrate() {
+		if err := autoMigrate(db, logger); err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+	}
+	// 读写分离 没有配置从库时(包括sqlite单机模式)读写都走主库
+	closeReplicas, err := useReplicas(db, cfg.Database)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return db, func() {
+		closeReplicas()
+		cleanup()
+	}, nil
+}
+
+// autoMigrate 执行到最新版本
+func autoMigrate(db *gorm.DB, logger log.Logger) error {
+	m, err := migrate.New(db, logger)
+	if err != nil {
+		return err
+	}
+	if err := m.Up(context.Background(), 0); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
 	}
-	return nil, errors.New("connect db failed,unsupported driver")
+	return nil
 }
@@ -0,0 +1,47 @@
+package data
+
+import (
+	"context"
+
+	"review-service/internal/biz"
+	"review-service/internal/data/model"
+	"review-service/internal/data/query"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+type dimensionRepo struct {
+	data *Data
+	log  *log.Helper
+}
+
+// NewDimensionRepo .
+func NewDimensionRepo(data *Data, logger log.Logger) biz.DimensionRepo {
+	return &dimensionRepo{
+		data: data,
+		log:  log.NewHelper(logger),
+	}
+}
+
+// ListDimensions 类目的评分维度 按类目和展示顺序排序
+func (r *dimensionRepo) ListDimensions(ctx context.Context, categoryIDs ...int64) ([]*model.ReviewDimension, error) {
+	rd := r.data.query.ReviewDimension
+	return rd.WithContext(ctx).
+		Where(rd.CategoryID.In(categoryIDs...)).
+		Order(rd.CategoryID, rd.Sort, rd.ID).
+		Find()
+}
+
+// SaveDimensions 删除类目原有的评分维度后重新写入
+func (r *dimensionRepo) SaveDimensions(ctx context.Context, categoryID int64, dims []*model.ReviewDimension) error {
+	return r.data.query.Transaction(func(tx *query.Query) error {
+		if _, err := tx.ReviewDimension.WithContext(ctx).
+			Where(tx.ReviewDimension.CategoryID.Eq(categoryID)).Delete(); err != nil {
+			return err
+		}
+		if len(dims) == 0 {
+			return nil
+		}
+		return tx.ReviewDimension.WithContext(ctx).Create(dims...)
+	})
+}
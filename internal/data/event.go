@@ -0,0 +1,123 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"review-service/internal/biz"
+	"review-service/internal/data/model"
+	"review-service/internal/data/query"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gen"
+	"gorm.io/plugin/dbresolver"
+)
+
+// eventPayloadMax 事件内容的长度上限 与 review_event.payload 的列定义一致
+const eventPayloadMax = 2048
+
+// addEvent 在事务中写入变更事件 应该是事务中的最后一条语句，缩短事件写入到提交的时间
+// review只需要ReviewID、StoreID、UserID和Anonymous
+func addEvent(ctx context.Context, tx *query.Query, eventType string, review *model.ReviewInfo, targetID int64, payload map[string]interface{}) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if len(b) > eventPayloadMax {
+		b = []byte("{}")
+	}
+	return tx.ReviewEvent.WithContext(ctx).Create(&model.ReviewEvent{
+		CreateAt:  time.Now(),
+		EventType: eventType,
+		ReviewID:  review.ReviewID,
+		StoreID:   review.StoreID,
+		UserID:    review.UserID,
+		TargetID:  targetID,
+		Payload:   string(b),
+		Anonymous: review.Anonymous,
+	})
+}
+
+// eventReview 事件需要的评价信息
+func eventReview(ctx context.Context, tx *query.Query, table string, reviewID int64) (*model.ReviewInfo, error) {
+	ri := tx.ReviewInfo.Table(table)
+	return ri.WithContext(ctx).Select(ri.ReviewID, ri.StoreID, ri.UserID, ri.Anonymous).Where(ri.ReviewID.Eq(reviewID)).First()
+}
+
+type eventRepo struct {
+	data *Data
+	log  *log.Helper
+}
+
+// NewEventRepo .
+func NewEventRepo(data *Data, logger log.Logger) biz.EventRepo {
+	return &eventRepo{
+		data: data,
+		log:  log.NewHelper(logger),
+	}
+}
+
+// ListEventSeqs 读主库 从库延迟时会把已经提交的事件当成序号空洞
+func (r *eventRepo) ListEventSeqs(ctx context.Context, afterSeq int64, limit int) ([]*model.ReviewEvent, error) {
+	re := r.data.query.ReviewEvent
+	return re.WithContext(ctx).Clauses(dbresolver.Write).Select(re.ID, re.CreateAt).
+		Where(re.ID.Gt(afterSeq)).Order(re.ID).Limit(limit).Find()
+}
+
+// ListEvents 读主库 从库延迟时可能读不到已经提交的事件
+func (r *eventRepo) ListEvents(ctx context.Context, filter *biz.WatchFilter, afterSeq, uptoSeq int64, limit int) ([]*model.ReviewEvent, error) {
+	re := r.data.query.ReviewEvent
+	conds := []gen.Condition{re.ID.Gt(afterSeq), re.ID.Lte(uptoSeq)}
+	if filter.StoreID > 0 {
+		conds = append(conds, re.StoreID.Eq(filter.StoreID))
+	}
+	if filter.UserID > 0 {
+		conds = append(conds, re.UserID.Eq(filter.UserID))
+	}
+	return re.WithContext(ctx).Clauses(dbresolver.Write).Where(conds...).Order(re.ID).Limit(limit).Find()
+}
+
+func (r *eventRepo) LastEventSeq(ctx context.Context) (int64, error) {
+	re := r.data.query.ReviewEvent
+	var last struct{ ID int64 }
+	err := re.WithContext(ctx).Clauses(dbresolver.Write).Select(re.ID.Max().As("id")).Scan(&last)
+	return last.ID, err
+}
+
+func (r *eventRepo) EventExists(ctx context.Context, seq int64) (bool, error) {
+	re := r.data.query.ReviewEvent
+	n, err := re.WithContext(ctx).Clauses(dbresolver.Write).Where(re.ID.Eq(seq)).Count()
+	return n > 0, err
+}
+
+// DeleteEventsBefore 按主键分批删除 避免一次删除太多行长时间锁表
+func (r *eventRepo) DeleteEventsBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	re := r.data.query.ReviewEvent
+	var ids []int64
+	if err := re.WithContext(ctx).Clauses(dbresolver.Write).Where(re.CreateAt.Lt(before)).
+		Order(re.ID).Limit(limit).Pluck(re.ID, &ids); err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	info, err := re.WithContext(ctx).Where(re.ID.In(ids...)).Delete()
+	return info.RowsAffected, err
+}
+
+// reviewPayload 评价事件的内容 下游需要完整内容时通过 ReviewID 查询
+func reviewPayload(review *model.ReviewInfo) map[string]interface{} {
+	return map[string]interface{}{
+		"status": review.Status,
+		"score":  review.Score,
+	}
+}
+
+// replyPayload 回复事件的内容
+func replyPayload(reply *model.ReviewReplyInfo) map[string]interface{} {
+	return map[string]interface{}{
+		"parent_id": reply.ParentID,
+		"version":   reply.Version,
+	}
+}
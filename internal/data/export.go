@@ -0,0 +1,260 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"review-service/internal/biz"
+	"review-service/internal/conf"
+	"review-service/internal/data/model"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// 导出文件存储的默认配置
+const (
+	defaultExportDir       = "./data/exports"
+	defaultExportURLPrefix = "/v1/export/files/"
+)
+
+type exportRepo struct {
+	data *Data
+	log  *log.Helper
+}
+
+// NewExportRepo .
+func NewExportRepo(data *Data, logger log.Logger) biz.ExportRepo {
+	return &exportRepo{
+		data: data,
+		log:  log.NewHelper(logger),
+	}
+}
+
+func (r *exportRepo) CreateExportJob(ctx context.Context, job *model.ReviewExportJob) error {
+	now := time.Now()
+	job.CreateAt, job.UpdateAt = now, now
+	return r.data.query.ReviewExportJob.WithContext(ctx).Create(job)
+}
+
+func (r *exportRepo) GetExportJob(ctx context.Context, jobID int64) (*model.ReviewExportJob, error) {
+	ej := r.data.query.ReviewExportJob
+	job, err := ej.WithContext(ctx).Where(ej.JobID.Eq(jobID)).First()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, biz.ErrExportNotFound
+	}
+	return job, err
+}
+
+// ListRunnableExportJobs 待执行的任务和超时没有更新进度的执行中任务 按创建顺序
+func (r *exportRepo) ListRunnableExportJobs(ctx context.Context, staleBefore time.Time, limit int) ([]int64, error) {
+	ej := r.data.query.ReviewExportJob
+	var ids []int64
+	err := ej.WithContext(ctx).Clauses(dbresolver.Write).
+		Where(r.runnable(staleBefore)).
+		Order(ej.ID).Limit(limit).Pluck(ej.JobID, &ids)
+	return ids, err
+}
+
+// ClaimExportJob 条件更新为执行中 多个实例同时抢占时只有一个能更新成功
+func (r *exportRepo) ClaimExportJob(ctx context.Context, jobID int64, staleBefore time.Time) (bool, error) {
+	ej := r.data.query.ReviewExportJob
+	info, err := ej.WithContext(ctx).
+		Where(ej.JobID.Eq(jobID), r.runnable(staleBefore)).
+		UpdateSimple(ej.Status.Value(biz.ExportStatusRunning), ej.RowCount.Value(0), ej.ErrMsg.Value(""), ej.UpdateAt.Value(time.Now()))
+	if err != nil {
+		return false, err
+	}
+	return info.RowsAffected > 0, nil
+}
+
+// runnable 状态为待执行，或者执行中但超过 staleBefore 没有更新
+func (r *exportRepo) runnable(staleBefore time.Time) field.Expr {
+	ej := r.data.query.ReviewExportJob
+	return field.Or(
+		ej.Status.Eq(biz.ExportStatusPending),
+		field.And(ej.Status.Eq(biz.ExportStatusRunning), ej.UpdateAt.Lt(staleBefore)),
+	)
+}
+
+func (r *exportRepo) UpdateExportJob(ctx context.Context, jobID int64, fields map[string]interface{}) error {
+	ej := r.data.query.ReviewExportJob
+	fields["update_at"] = time.Now()
+	_, err := ej.WithContext(ctx).Where(ej.JobID.Eq(jobID)).Updates(fields)
+	return err
+}
+
+// ScanReviews 逐个分表按自增主键分批读取 where id > 上一批的最大id，不用offset，避免越往后越慢
+// 按店铺分表且指定了店铺时只需要读取店铺所在的分表
+func (r *exportRepo) ScanReviews(ctx context.Context, filter *biz.ExportFilter, batchSize int, fn func([]*model.ReviewInfo) error) error {
+	s := r.data.sharding
+	tables := ShardTables(s.tables)
+	if s.enabled() && s.key == ShardKeyStoreID && filter.StoreID > 0 {
+		tables = []string{s.table(filter.StoreID)}
+	}
+	for _, table := range tables {
+		if err := r.scanTable(ctx, table, filter, batchSize, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *exportRepo) scanTable(ctx context.Context, table string, filter *biz.ExportFilter, batchSize int, fn func([]*model.ReviewInfo) error) error {
+	ri := r.data.query.ReviewInfo.Table(table)
+	conds := []gen.Condition{ri.DeleteAt.IsNull()}
+	if filter.StoreID > 0 {
+		conds = append(conds, ri.StoreID.Eq(filter.StoreID))
+	}
+	start, end := filter.TimeRange()
+	if !start.IsZero() {
+		conds = append(conds, ri.CreateAt.Gte(start))
+	}
+	if !end.IsZero() {
+		conds = append(conds, ri.CreateAt.Lt(end))
+	}
+	if len(filter.Statuses) > 0 {
+		conds = append(conds, ri.Status.In(filter.Statuses...))
+	}
+	if len(filter.Scores) > 0 {
+		conds = append(conds, ri.Score.In(filter.Scores...))
+	}
+	var lastID int64
+	for {
+		list, err := ri.WithContext(ctx).Where(conds...).Where(ri.ID.Gt(lastID)).
+			Order(ri.ID).Limit(batchSize).Find()
+		if err != nil {
+			return err
+		}
+		if len(list) == 0 {
+			return nil
+		}
+		if err := fn(list); err != nil {
+			return err
+		}
+		if len(list) < batchSize {
+			return nil
+		}
+		lastID = list[len(list)-1].ID
+	}
+}
+
+// exportStorage 导出文件保存在本地目录 由HTTP服务的 ExportFileServer 提供下载
+// 多实例部署时目录需要挂载共享存储
+type exportStorage struct {
+	dir       string
+	urlPrefix string
+}
+
+// NewExportStorage 本地文件存储
+func NewExportStorage(c *conf.Export) (biz.ExportStorage, error) {
+	dir, prefix := exportConfig(c)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &exportStorage{dir: dir, urlPrefix: prefix}, nil
+}
+
+func exportConfig(c *conf.Export) (dir, prefix string) {
+	dir, prefix = c.GetDir(), c.GetUrlPrefix()
+	if dir == "" {
+		dir = defaultExportDir
+	}
+	if prefix == "" {
+		prefix = defaultExportURLPrefix
+	}
+	return dir, prefix
+}
+
+// Create 先写入临时文件，Close 时再重命名，下载时不会读到没有写完的文件
+func (s *exportStorage) Create(ctx context.Context, key string) (io.WriteCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp(s.dir, ".tmp-"+key+"-*")
+	if err != nil {
+		return nil, err
+	}
+	return &exportFile{File: f, path: path}, nil
+}
+
+func (s *exportStorage) Remove(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *exportStorage) URL(key string) string {
+	return s.urlPrefix + key
+}
+
+// path key只能是文件名 不能包含目录
+func (s *exportStorage) path(key string) (string, error) {
+	if key == "" || key != filepath.Base(key) || strings.HasPrefix(key, ".") {
+		return "", errors.New("invalid export file key")
+	}
+	return filepath.Join(s.dir, key), nil
+}
+
+type exportFile struct {
+	*os.File
+	path   string
+	closed bool
+}
+
+// Close 关闭临时文件并重命名为正式文件 可以重复调用
+func (f *exportFile) Close() error {
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	if err := f.File.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), f.path)
+}
+
+// ExportFileServer 导出文件的下载 url_prefix 不是以/开头的路径时(如CDN地址)由外部提供下载，返回的handler为nil
+func ExportFileServer(c *conf.Export) (string, http.Handler) {
+	dir, prefix := exportConfig(c)
+	if !strings.HasPrefix(prefix, "/") {
+		return "", nil
+	}
+	return prefix, http.StripPrefix(prefix, http.FileServer(exportDir{http.Dir(dir)}))
+}
+
+// exportDir 只能下载文件 不能列出目录，也不能下载临时文件
+type exportDir struct {
+	fs http.FileSystem
+}
+
+func (d exportDir) Open(name string) (http.File, error) {
+	if base := filepath.Base(name); strings.HasPrefix(base, ".") || base == "/" {
+		return nil, os.ErrNotExist
+	}
+	f, err := d.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if st, err := f.Stat(); err != nil || st.IsDir() {
+		_ = f.Close()
+		return nil, os.ErrNotExist
+	}
+	return f, nil
+}
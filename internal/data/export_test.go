@@ -0,0 +1,171 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"review-service/internal/biz"
+	"review-service/internal/conf"
+	"review-service/internal/data/model"
+	"review-service/internal/data/query"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+)
+
+// seedExportReviews 店铺1和店铺2的评价 review_id为 店铺*100+序号
+func seedExportReviews(t *testing.T, db *gorm.DB, tables int64) {
+	t.Helper()
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 12, 0, 0, 0, time.Local) }
+	rows := []*model.ReviewInfo{
+		{ReviewID: 101, StoreID: 1, Score: 5, Status: 20, CreateAt: day(1)},
+		{ReviewID: 102, StoreID: 1, Score: 1, Status: 20, CreateAt: day(2)},
+		{ReviewID: 103, StoreID: 1, Score: 3, Status: 40, CreateAt: day(3)},
+		{ReviewID: 104, StoreID: 1, Score: 4, Status: 10, CreateAt: day(4)},
+		{ReviewID: 105, StoreID: 1, Score: 5, Status: 20, CreateAt: day(5)},
+		{ReviewID: 201, StoreID: 2, Score: 2, Status: 20, CreateAt: day(1)},
+		{ReviewID: 202, StoreID: 2, Score: 5, Status: 20, CreateAt: day(3)},
+	}
+	for _, table := range ShardTables(tables) {
+		if err := createShardTable(context.Background(), db, table); err != nil {
+			t.Fatalf("create %s: %v", table, err)
+		}
+	}
+	for _, row := range rows {
+		row.UserID, row.OrderID, row.Content = row.ReviewID, row.ReviewID, "好"
+		if err := db.Table(ShardTable(row.StoreID, tables)).Create(row).Error; err != nil {
+			t.Fatalf("create review: %v", err)
+		}
+	}
+	// 已删除的评价不导出
+	deleted := &model.ReviewInfo{ReviewID: 106, StoreID: 1, UserID: 106, OrderID: 106, Score: 5, Status: 20, Content: "好", CreateAt: day(2), DeleteAt: ptrTime(day(6))}
+	if err := db.Table(ShardTable(1, tables)).Create(deleted).Error; err != nil {
+		t.Fatalf("create review: %v", err)
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
+
+func TestScanReviews(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter *biz.ExportFilter
+		want   []int64
+	}{
+		{name: "all stores", filter: &biz.ExportFilter{}, want: []int64{101, 102, 103, 104, 105, 201, 202}},
+		{name: "one store", filter: &biz.ExportFilter{StoreID: 2}, want: []int64{201, 202}},
+		{name: "date range includes end date", filter: &biz.ExportFilter{StoreID: 1, StartDate: "2024-01-02", EndDate: "2024-01-04"}, want: []int64{102, 103, 104}},
+		{name: "statuses", filter: &biz.ExportFilter{Statuses: []int32{10, 40}}, want: []int64{103, 104}},
+		{name: "scores", filter: &biz.ExportFilter{Scores: []int32{5}}, want: []int64{101, 105, 202}},
+		{name: "no match", filter: &biz.ExportFilter{StoreID: 3}},
+	}
+	shardings := []*sharding{
+		{tables: 1, key: ShardKeyUserID},
+		{tables: 4, key: ShardKeyStoreID},
+	}
+	for _, s := range shardings {
+		db := newTestDB(t)
+		seedExportReviews(t, db, s.tables)
+		repo := &exportRepo{data: &Data{query: query.Use(db), sharding: s}, log: log.NewHelper(log.DefaultLogger)}
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("tables=%d/%s", s.tables, tt.name), func(t *testing.T) {
+				var (
+					got     []int64
+					batches int
+				)
+				// 每批2条 验证按id分批读取不会漏读或重复
+				err := repo.ScanReviews(context.Background(), tt.filter, 2, func(list []*model.ReviewInfo) error {
+					batches++
+					if len(list) > 2 {
+						t.Fatalf("batch of %d rows", len(list))
+					}
+					for _, r := range list {
+						got = append(got, r.ReviewID)
+					}
+					return nil
+				})
+				if err != nil {
+					t.Fatalf("ScanReviews() error = %v", err)
+				}
+				sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("ScanReviews() = %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestExportFileServer(t *testing.T) {
+	dir := t.TempDir()
+	storage, err := NewExportStorage(&conf.Export{Dir: dir})
+	if err != nil {
+		t.Fatalf("NewExportStorage() error = %v", err)
+	}
+	w, err := storage.Create(context.Background(), "done.csv")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	_, _ = w.Write([]byte("review_id\n1\n"))
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	// 没有写完的临时文件
+	if _, err := storage.Create(context.Background(), "writing.csv"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	prefix, h := ExportFileServer(&conf.Export{Dir: dir})
+	tests := []struct {
+		path string
+		want int
+	}{
+		{path: "done.csv", want: http.StatusOK},
+		{path: "writing.csv", want: http.StatusNotFound},
+		{path: "", want: http.StatusNotFound},
+		{path: "sub/", want: http.StatusNotFound},
+		// 路径先被清理 不会跳出导出目录
+		{path: "../done.csv", want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, prefix+tt.path, nil))
+		if rec.Code != tt.want {
+			t.Errorf("GET %s%s = %d, want %d", prefix, tt.path, rec.Code, tt.want)
+		}
+	}
+	// 临时文件名以.开头
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if e.Name() == "writing.csv" {
+			t.Fatal("unfinished file is visible")
+		}
+	}
+	if _, h := ExportFileServer(&conf.Export{Dir: dir, UrlPrefix: "https://cdn.example.com/exports/"}); h != nil {
+		t.Fatal("ExportFileServer() served files for a CDN prefix")
+	}
+}
+
+func TestExportStoragePath(t *testing.T) {
+	s := &exportStorage{dir: t.TempDir(), urlPrefix: defaultExportURLPrefix}
+	for _, key := range []string{"", ".hidden", "../escape.csv", "a/b.csv"} {
+		if _, err := s.path(key); err == nil {
+			t.Errorf("path(%q) error = nil", key)
+		}
+	}
+	if got := s.URL("a.csv"); got != defaultExportURLPrefix+"a.csv" {
+		t.Errorf("URL() = %s", got)
+	}
+}
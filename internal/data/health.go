@@ -0,0 +1,66 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"review-service/internal/biz"
+)
+
+type healthRepo struct {
+	data *Data
+}
+
+func NewHealthRepo(data *Data) biz.HealthRepo {
+	return &healthRepo{data: data}
+}
+
+// Ping 并发检查MySQL、Redis和ES 超时由调用方的ctx控制
+func (r *healthRepo) Ping(ctx context.Context) map[string]error {
+	checks := map[string]func(context.Context) error{
+		biz.DependencyMySQL:         r.pingDB,
+		biz.DependencyRedis:         r.pingRedis,
+		biz.DependencyElasticsearch: r.pingES,
+	}
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		ret = make(map[string]error, len(checks))
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(context.Context) error) {
+			defer wg.Done()
+			err := check(ctx)
+			mu.Lock()
+			ret[name] = err
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+	return ret
+}
+
+func (r *healthRepo) pingDB(ctx context.Context) error {
+	db, err := r.data.db.DB()
+	if err != nil {
+		return err
+	}
+	return db.PingContext(ctx)
+}
+
+func (r *healthRepo) pingRedis(ctx context.Context) error {
+	return r.data.rdb.Ping(ctx).Err()
+}
+
+func (r *healthRepo) pingES(ctx context.Context) error {
+	ok, err := r.data.es.Ping().IsSuccess(ctx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("elasticsearch ping failed")
+	}
+	return nil
+}
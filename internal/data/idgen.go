@@ -0,0 +1,213 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"time"
+
+	"review-service/internal/conf"
+	"review-service/pkg/snowflake"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/redis/go-redis/v9"
+)
+
+// 雪花算法的机器ID
+// 配置了machine_id时直接使用，否则从Redis租用一个机器ID，多个实例可以共用同一份配置
+// snowflake:machine:{id}       string 租约 值为实例标识，定时续期，实例宕机后过期释放
+// snowflake:machine:{id}:last  string 该机器ID最后生成ID的时间(毫秒)，新实例租到后据此发现时钟回拨
+
+const defaultLeaseTTL = 30 * time.Second
+
+// ErrMachineLeaseLost 机器ID的租约失效 其它实例可能已经在使用该机器ID
+var ErrMachineLeaseLost = errors.New("snowflake machine id lease lost")
+
+func machineLeaseKey(id int64) string {
+	return fmt.Sprintf("snowflake:machine:%d", id)
+}
+
+func machineLastKey(id int64) string {
+	return fmt.Sprintf("snowflake:machine:%d:last", id)
+}
+
+// renewLeaseScript 租约还是自己的时候续期并记录最后生成ID的时间
+// KEYS[1] 租约 KEYS[2] 最后时间 ARGV[1] 实例标识 ARGV[2] 有效期(毫秒) ARGV[3] 最后时间
+var renewLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	redis.call("SET", KEYS[2], ARGV[3])
+	return 1
+end
+return 0
+`)
+
+// releaseLeaseScript 租约还是自己的时候释放并记录最后生成ID的时间
+var releaseLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	redis.call("DEL", KEYS[1])
+	redis.call("SET", KEYS[2], ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// NewIDGenerator 雪花算法ID生成器的构造函数
+func NewIDGenerator(cfg *conf.Snowflake, rdb *redis.Client, logger log.Logger) (snowflake.IDGenerator, func(), error) {
+	opts := []snowflake.Option{clockPolicy(cfg)}
+	if cfg.GetMachineId() > 0 {
+		gen, err := snowflake.NewGenerator(cfg.GetStartTime(), cfg.GetMachineId(), opts...)
+		if err != nil {
+			return nil, nil, err
+		}
+		return gen, func() {}, nil
+	}
+	ttl := cfg.GetLeaseTtl().AsDuration()
+	if ttl <= 0 {
+		ttl = defaultLeaseTTL
+	}
+	l := &machineLease{
+		rdb:  rdb,
+		ttl:  ttl,
+		log:  log.NewHelper(logger),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	last, err := l.acquire(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !last.IsZero() {
+		opts = append(opts, snowflake.WithLastTime(last))
+	}
+	gen, err := snowflake.NewGenerator(cfg.GetStartTime(), l.id, opts...)
+	if err != nil {
+		l.rdb.Del(ctx, machineLeaseKey(l.id))
+		return nil, nil, err
+	}
+	l.gen = gen
+	go l.heartbeat()
+	return gen, l.close, nil
+}
+
+func clockPolicy(cfg *conf.Snowflake) snowflake.Option {
+	maxWait := cfg.GetMaxWait().AsDuration()
+	if maxWait <= 0 {
+		maxWait = time.Second
+	}
+	if cfg.GetClockPolicy() == "fail" {
+		return snowflake.WithClockPolicy(snowflake.ClockFail, maxWait)
+	}
+	return snowflake.WithClockPolicy(snowflake.ClockWait, maxWait)
+}
+
+// machineLease 机器ID的租约
+type machineLease struct {
+	rdb   *redis.Client
+	ttl   time.Duration
+	id    int64
+	token string // 实例标识 区分租约是不是自己的
+	gen   *snowflake.Generator
+	log   *log.Helper
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// acquire 从随机位置开始依次尝试租用机器ID 返回该机器ID上次生成ID的时间
+func (l *machineLease) acquire(ctx context.Context) (time.Time, error) {
+	host, _ := os.Hostname()
+	l.token = fmt.Sprintf("%s-%d-%d", host, os.Getpid(), time.Now().UnixNano())
+	start := rand.Int63n(snowflake.MaxMachineID + 1)
+	for i := int64(0); i <= snowflake.MaxMachineID; i++ {
+		id := (start + i) % (snowflake.MaxMachineID + 1)
+		ok, err := l.rdb.SetNX(ctx, machineLeaseKey(id), l.token, l.ttl).Result()
+		if err != nil {
+			return time.Time{}, err
+		}
+		if !ok {
+			continue
+		}
+		l.id = id
+		l.log.Infow("msg", "snowflake machine id leased", "machine_id", id, "ttl", l.ttl)
+		ms, err := l.rdb.Get(ctx, machineLastKey(id)).Int64()
+		if errors.Is(err, redis.Nil) {
+			return time.Time{}, nil
+		}
+		if err != nil {
+			l.rdb.Del(ctx, machineLeaseKey(id))
+			return time.Time{}, err
+		}
+		return time.UnixMilli(ms), nil
+	}
+	return time.Time{}, errors.New("no snowflake machine id available")
+}
+
+// heartbeat 定时续期
+// 超过有效期没有续期成功时停止生成ID，租约过期后其它实例可能会租到同一个机器ID
+func (l *machineLease) heartbeat() {
+	defer close(l.done)
+	interval := l.ttl / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	renewed := time.Now()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+		ok, err := l.renew()
+		switch {
+		case err == nil && ok:
+			renewed = time.Now()
+			l.gen.Enable()
+		case err == nil:
+			l.log.Errorw("msg", "snowflake machine id lease lost", "machine_id", l.id)
+			l.gen.Disable(ErrMachineLeaseLost)
+		case time.Since(renewed) > l.ttl-interval:
+			l.log.Errorw("msg", "renew snowflake machine id lease failed", "machine_id", l.id, "err", err)
+			l.gen.Disable(ErrMachineLeaseLost)
+		default:
+			l.log.Warnw("msg", "renew snowflake machine id lease failed", "machine_id", l.id, "err", err)
+		}
+	}
+}
+
+// renew 续期 租约已过期但没有被其它实例租走时重新租用
+func (l *machineLease) renew() (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), l.ttl/3)
+	defer cancel()
+	last := strconv.FormatInt(l.gen.LastTime().UnixMilli(), 10)
+	ok, err := renewLeaseScript.Run(ctx, l.rdb,
+		[]string{machineLeaseKey(l.id), machineLastKey(l.id)},
+		l.token, l.ttl.Milliseconds(), last,
+	).Bool()
+	if err != nil || ok {
+		return ok, err
+	}
+	return l.rdb.SetNX(ctx, machineLeaseKey(l.id), l.token, l.ttl).Result()
+}
+
+// release 释放租约
+func (l *machineLease) release(ctx context.Context) {
+	if err := releaseLeaseScript.Run(ctx, l.rdb,
+		[]string{machineLeaseKey(l.id), machineLastKey(l.id)},
+		l.token, l.gen.LastTime().UnixMilli(),
+	).Err(); err != nil {
+		l.log.Errorw("msg", "release snowflake machine id lease failed", "machine_id", l.id, "err", err)
+	}
+}
+
+// close 停止续期并释放租约 在Redis客户端关闭之前调用
+func (l *machineLease) close() {
+	close(l.stop)
+	<-l.done
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	l.release(ctx)
+}
@@ -0,0 +1,102 @@
+package data
+
+import (
+	"context"
+
+	"review-service/internal/biz"
+	"review-service/internal/data/model"
+	"review-service/internal/data/query"
+	"review-service/pkg/snowflake"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
+)
+
+// importKey 导入记录的唯一键
+type importKey struct {
+	storeID int64
+	source  string
+	extID   string
+}
+
+// ImportReviews 导入的评价按分表分组写入 同一个库中的分表可以在一个事务中写入
+// 在同一个事务中先写入 review_import_info，唯一键冲突的(包括并发的导入刚刚写入的)是已经导入过的订单号，跳过对应的评价
+// 提交后写入统计文档，写入失败只记录日志
+func (r *reviewRepo) ImportReviews(ctx context.Context, reviews []*model.ReviewInfo) ([]*model.ReviewInfo, error) {
+	var imported, duplicated []*model.ReviewInfo
+	err := r.data.query.Transaction(func(tx *query.Query) error {
+		imported, duplicated = nil, nil
+		infos := make([]*model.ReviewImportInfo, 0, len(reviews))
+		var (
+			storeIDs []int64
+			sources  []string
+			extIDs   []string
+		)
+		for _, review := range reviews {
+			ext := biz.ParseReviewExt(review)
+			infos = append(infos, &model.ReviewImportInfo{
+				StoreID:         review.StoreID,
+				Source:          ext.Source,
+				ExternalOrderID: ext.ExternalOrderID,
+				ReviewID:        review.ReviewID,
+			})
+			storeIDs = append(storeIDs, review.StoreID)
+			sources = append(sources, ext.Source)
+			extIDs = append(extIDs, ext.ExternalOrderID)
+		}
+		ii := tx.ReviewImportInfo
+		if err := ii.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(infos, len(infos)); err != nil {
+			return err
+		}
+		// 唯一键上记录的评价ID不是本次的评价时，订单号已经被其它导入占用
+		saved, err := ii.WithContext(ctx).Select(ii.StoreID, ii.Source, ii.ExternalOrderID, ii.ReviewID).
+			Where(ii.StoreID.In(storeIDs...), ii.Source.In(sources...), ii.ExternalOrderID.In(extIDs...)).Find()
+		if err != nil {
+			return err
+		}
+		owner := make(map[importKey]int64, len(saved))
+		for _, info := range saved {
+			owner[importKey{info.StoreID, info.Source, info.ExternalOrderID}] = info.ReviewID
+		}
+		tables := make(map[string][]*model.ReviewInfo)
+		for i, review := range reviews {
+			info := infos[i]
+			if owner[importKey{info.StoreID, info.Source, info.ExternalOrderID}] != review.ReviewID {
+				duplicated = append(duplicated, review)
+				continue
+			}
+			imported = append(imported, review)
+			table := ShardTable(snowflake.SlotOf(review.ReviewID), r.data.sharding.tables)
+			tables[table] = append(tables[table], review)
+		}
+		for table, list := range tables {
+			if err := tx.ReviewInfo.Table(table).WithContext(ctx).CreateInBatches(list, len(list)); err != nil {
+				return err
+			}
+		}
+		for _, review := range imported {
+			if err := addEvent(ctx, tx, biz.EventReviewCreated, review, 0, reviewPayload(review)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, review := range imported {
+		r.indexReviewStats(ctx, review)
+	}
+	return duplicated, nil
+}
+
+// ListImportedOrderIDs 店铺从来源平台导入过的订单号
+func (r *reviewRepo) ListImportedOrderIDs(ctx context.Context, storeID int64, source string) ([]string, error) {
+	ii := r.data.query.ReviewImportInfo
+	var ids []string
+	// 读主库 避免重复导入时从库延迟读不到刚导入的评价
+	err := ii.WithContext(ctx).Clauses(dbresolver.Write).
+		Where(ii.StoreID.Eq(storeID), ii.Source.Eq(source)).
+		Pluck(ii.ExternalOrderID, &ids)
+	return ids, err
+}
@@ -0,0 +1,40 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"review-service/internal/biz"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 限流计数在Redis中的存储
+// review:limit:{key} string 窗口内的请求数 窗口结束时过期
+
+// limitScript 计数加一 窗口内的第一次请求设置过期时间
+// KEYS[1] 计数 ARGV[1] 窗口(毫秒)
+var limitScript = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return n
+`)
+
+type rateLimiter struct {
+	data *Data
+}
+
+// NewRateLimiter .
+func NewRateLimiter(data *Data) biz.RateLimiter {
+	return &rateLimiter{data: data}
+}
+
+func (l *rateLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, error) {
+	n, err := limitScript.Run(ctx, l.data.rdb, []string{"review:limit:" + key}, window.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return n <= limit, nil
+}
@@ -0,0 +1,311 @@
+package migrate
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+)
+
+// 数据库表结构迁移
+// 每个数据库方言一个目录，文件名为 {版本号}_{名称}.up.sql 和 {版本号}_{名称}.down.sql
+// 已执行的版本记录在 migrations 表中，up 按版本号从小到大执行，down 按版本号从大到小回滚
+// {版本号}_{名称}.upgrade.sql 用于手工执行 review.sql 建的老库: 第一次迁移时表已经存在，在 up 之后执行补齐表结构
+// mysql 上 up/down 期间持有 GET_LOCK 锁，多个实例同时启动时只有一个在执行迁移
+// 注意: mysql 的 DDL 会隐式提交事务，一个迁移文件执行到一半失败时需要手动处理
+
+//go:embed mysql/*.sql sqlite/*.sql
+var files embed.FS
+
+// TableName 记录已执行版本的表
+const TableName = "migrations"
+
+var ErrNoChange = errors.New("migrate: no change")
+
+const (
+	// legacyTable 手工执行 review.sql 建的库中已有的表
+	legacyTable = "review_info"
+	// lockName mysql 迁移锁 GET_LOCK 对整个 mysql 实例生效
+	lockName = "review_service_migrate"
+	// lockTimeout 等待迁移锁的秒数
+	lockTimeout = 300
+)
+
+// Migration 一个版本的迁移
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+	Upgrade string // 老库升级 只在第一次迁移且 legacyTable 已存在时执行
+}
+
+// Record migrations 表中的一条记录
+type Record struct {
+	Version   int64     `gorm:"column:version;primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"column:name;not null"`
+	AppliedAt time.Time `gorm:"column:applied_at;not null"`
+}
+
+func (Record) TableName() string {
+	return TableName
+}
+
+// Status 一个版本的执行状态
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrator 执行迁移
+type Migrator struct {
+	db         *gorm.DB
+	migrations []*Migration
+	log        *log.Helper
+}
+
+// New 根据数据库方言加载内置的迁移文件
+func New(db *gorm.DB, logger log.Logger) (*Migrator, error) {
+	migrations, err := Load(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+		log:        log.NewHelper(logger),
+	}, nil
+}
+
+// Load 加载某个数据库方言的迁移 按版本号排序
+func Load(dialect string) ([]*Migration, error) {
+	entries, err := fs.ReadDir(files, dialect)
+	if err != nil {
+		return nil, fmt.Errorf("migrate: unsupported dialect %q", dialect)
+	}
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		name := entry.Name()
+		var kind, base string
+		for _, k := range []string{"up", "down", "upgrade"} {
+			if b, ok := strings.CutSuffix(name, "."+k+".sql"); ok {
+				kind, base = k, b
+				break
+			}
+		}
+		if kind == "" {
+			continue
+		}
+		prefix, title, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migrate: invalid file name %s", name)
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migrate: invalid version in %s", name)
+		}
+		b, err := files.ReadFile(path.Join(dialect, name))
+		if err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: title}
+			byVersion[version] = m
+		}
+		if m.Name != title {
+			return nil, fmt.Errorf("migrate: version %d has different names %s and %s", version, m.Name, title)
+		}
+		switch kind {
+		case "up":
+			m.Up = string(b)
+		case "down":
+			m.Down = string(b)
+		default:
+			m.Upgrade = string(b)
+		}
+	}
+	ret := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migrate: version %d has no up file", m.Version)
+		}
+		ret = append(ret, m)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Version < ret[j].Version })
+	return ret, nil
+}
+
+// Up 执行未执行过的迁移直到target版本 target为0时执行到最新版本
+func (m *Migrator) Up(ctx context.Context, target int64) error {
+	return m.locked(ctx, func(db *gorm.DB) error {
+		applied, err := m.applied(db)
+		if err != nil {
+			return err
+		}
+		// 还没有执行过迁移但表已经存在 说明是手工执行 review.sql 建的库
+		legacy := len(applied) == 0 && db.Migrator().HasTable(legacyTable)
+		var n int
+		for _, mg := range m.migrations {
+			if target > 0 && mg.Version > target {
+				break
+			}
+			if _, ok := applied[mg.Version]; ok {
+				continue
+			}
+			sql := mg.Up
+			if legacy && mg.Upgrade != "" {
+				m.log.WithContext(ctx).Infow("msg", "migrate upgrade legacy tables", "version", mg.Version, "name", mg.Name)
+				sql += "\n" + mg.Upgrade
+			}
+			m.log.WithContext(ctx).Infow("msg", "migrate up", "version", mg.Version, "name", mg.Name)
+			if err := m.apply(db, sql, func(tx *gorm.DB) error {
+				return tx.Create(&Record{Version: mg.Version, Name: mg.Name, AppliedAt: time.Now()}).Error
+			}); err != nil {
+				return fmt.Errorf("migrate: up %d_%s: %w", mg.Version, mg.Name, err)
+			}
+			n++
+		}
+		if n == 0 {
+			return ErrNoChange
+		}
+		return nil
+	})
+}
+
+// Down 回滚最近执行的steps个迁移
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.locked(ctx, func(db *gorm.DB) error {
+		applied, err := m.applied(db)
+		if err != nil {
+			return err
+		}
+		var n int
+		for i := len(m.migrations) - 1; i >= 0 && n < steps; i-- {
+			mg := m.migrations[i]
+			if _, ok := applied[mg.Version]; !ok {
+				continue
+			}
+			if mg.Down == "" {
+				return fmt.Errorf("migrate: version %d has no down file", mg.Version)
+			}
+			m.log.WithContext(ctx).Infow("msg", "migrate down", "version", mg.Version, "name", mg.Name)
+			if err := m.apply(db, mg.Down, func(tx *gorm.DB) error {
+				return tx.Delete(&Record{Version: mg.Version}).Error
+			}); err != nil {
+				return fmt.Errorf("migrate: down %d_%s: %w", mg.Version, mg.Name, err)
+			}
+			n++
+		}
+		if n == 0 {
+			return ErrNoChange
+		}
+		return nil
+	})
+}
+
+// locked mysql 上在同一个连接里持有 GET_LOCK 锁执行fn 其他方言直接执行
+// 拿到锁之后 fn 重新读取已执行的版本，等锁的实例会得到 ErrNoChange
+func (m *Migrator) locked(ctx context.Context, fn func(db *gorm.DB) error) error {
+	db := m.db.WithContext(ctx)
+	if db.Dialector.Name() != "mysql" {
+		return fn(db)
+	}
+	// GET_LOCK 是连接级别的锁 加锁、迁移、释放锁都要在同一个连接上
+	return db.Connection(func(conn *gorm.DB) error {
+		var got *int
+		if err := conn.Raw("SELECT GET_LOCK(?, ?)", lockName, lockTimeout).Scan(&got).Error; err != nil {
+			return fmt.Errorf("migrate: get lock: %w", err)
+		}
+		if got == nil || *got != 1 {
+			return fmt.Errorf("migrate: get lock %s timeout after %ds", lockName, lockTimeout)
+		}
+		defer func() {
+			if err := conn.Exec("SELECT RELEASE_LOCK(?)", lockName).Error; err != nil {
+				m.log.WithContext(ctx).Errorw("msg", "migrate release lock", "err", err)
+			}
+		}()
+		return fn(conn)
+	})
+}
+
+// Status 所有版本的执行状态
+func (m *Migrator) Status(ctx context.Context) ([]*Status, error) {
+	applied, err := m.applied(m.db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Status, 0, len(m.migrations))
+	for _, mg := range m.migrations {
+		s := &Status{Version: mg.Version, Name: mg.Name}
+		if r, ok := applied[mg.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = r.AppliedAt
+		}
+		ret = append(ret, s)
+	}
+	return ret, nil
+}
+
+// applied 已执行的版本 migrations表不存在时先创建
+func (m *Migrator) applied(db *gorm.DB) (map[int64]*Record, error) {
+	if err := db.AutoMigrate(&Record{}); err != nil {
+		return nil, err
+	}
+	var records []*Record
+	if err := db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	ret := make(map[int64]*Record, len(records))
+	for _, r := range records {
+		ret[r.Version] = r
+	}
+	return ret, nil
+}
+
+// apply 在一个事务中逐条执行SQL并更新migrations表
+func (m *Migrator) apply(db *gorm.DB, sql string, record func(tx *gorm.DB) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range splitStatements(sql) {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return record(tx)
+	})
+}
+
+// splitStatements 按行尾的分号拆分SQL语句 忽略 -- 开头的注释行
+func splitStatements(sql string) []string {
+	var (
+		ret []string
+		buf strings.Builder
+	)
+	for _, line := range strings.Split(sql, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		buf.WriteString(line)
+		buf.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			ret = append(ret, strings.TrimSpace(buf.String()))
+			buf.Reset()
+		}
+	}
+	if s := strings.TrimSpace(buf.String()); s != "" {
+		ret = append(ret, s)
+	}
+	return ret
+}
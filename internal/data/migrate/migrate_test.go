@@ -0,0 +1,158 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+)
+
+func newTestMigrator(t *testing.T) (*Migrator, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "review.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	m, err := New(db, log.DefaultLogger)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return m, db
+}
+
+func appliedVersions(t *testing.T, m *Migrator) []int64 {
+	t.Helper()
+	status, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	var ret []int64
+	for _, s := range status {
+		if s.Applied {
+			ret = append(ret, s.Version)
+		}
+	}
+	return ret
+}
+
+func TestLoad(t *testing.T) {
+	for _, dialect := range []string{"mysql", "sqlite"} {
+		t.Run(dialect, func(t *testing.T) {
+			migrations, err := Load(dialect)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			for i, mg := range migrations {
+				if mg.Version != int64(i+1) {
+					t.Fatalf("migration %d has version %d", i, mg.Version)
+				}
+				if mg.Up == "" || mg.Down == "" {
+					t.Fatalf("version %d misses up or down file", mg.Version)
+				}
+			}
+		})
+	}
+	if _, err := Load("oracle"); err == nil {
+		t.Fatal("Load(oracle) error = nil")
+	}
+}
+
+func TestLoadSameVersions(t *testing.T) {
+	my, _ := Load("mysql")
+	lite, _ := Load("sqlite")
+	if len(my) != len(lite) {
+		t.Fatalf("mysql has %d migrations, sqlite has %d", len(my), len(lite))
+	}
+	for i := range my {
+		if my[i].Version != lite[i].Version || my[i].Name != lite[i].Name {
+			t.Fatalf("mysql %d_%s != sqlite %d_%s", my[i].Version, my[i].Name, lite[i].Version, lite[i].Name)
+		}
+	}
+}
+
+func TestLegacyUpgrade(t *testing.T) {
+	migrations, err := Load("mysql")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if migrations[0].Upgrade == "" {
+		t.Fatal("mysql 0001 has no upgrade file")
+	}
+	upgrade := strings.Join(splitStatements(migrations[0].Upgrade), "\n")
+	// review.sql 建的库和 0001_init 相比缺少的结构
+	for _, want := range []string{"`helpful_count`", "`unhelpful_count`", "`parent_id`", "`user_id`", "`author_type`", "`seq`", "`status`", "`idx_review_id_seq`", "`uk_review_id`"} {
+		if !strings.Contains(upgrade, want) {
+			t.Errorf("upgrade misses %s", want)
+		}
+	}
+}
+
+func TestUpDown(t *testing.T) {
+	ctx := context.Background()
+	m, db := newTestMigrator(t)
+	latest := m.migrations[len(m.migrations)-1].Version
+
+	steps := []struct {
+		name    string
+		run     func() error
+		wantErr error
+		want    []int64
+	}{
+		{name: "up to 2", run: func() error { return m.Up(ctx, 2) }, want: []int64{1, 2}},
+		{name: "up to 2 again", run: func() error { return m.Up(ctx, 2) }, wantErr: ErrNoChange, want: []int64{1, 2}},
+		{name: "up to latest", run: func() error { return m.Up(ctx, 0) }, want: versions(1, latest)},
+		{name: "up to latest again", run: func() error { return m.Up(ctx, 0) }, wantErr: ErrNoChange, want: versions(1, latest)},
+		{name: "down 1", run: func() error { return m.Down(ctx, 1) }, want: versions(1, latest-1)},
+		{name: "down all", run: func() error { return m.Down(ctx, len(m.migrations)) }},
+		{name: "down nothing", run: func() error { return m.Down(ctx, 1) }, wantErr: ErrNoChange},
+		{name: "up again", run: func() error { return m.Up(ctx, 0) }, want: versions(1, latest)},
+	}
+	for _, step := range steps {
+		err := step.run()
+		if !errors.Is(err, step.wantErr) {
+			t.Fatalf("%s: error = %v, want %v", step.name, err, step.wantErr)
+		}
+		if got := appliedVersions(t, m); !reflect.DeepEqual(got, step.want) {
+			t.Fatalf("%s: applied = %v, want %v", step.name, got, step.want)
+		}
+	}
+	for _, table := range []string{"review_info", "review_reply_info", "review_appeal_info", "review_report_info"} {
+		if !db.Migrator().HasTable(table) {
+			t.Errorf("table %s not created", table)
+		}
+	}
+}
+
+func versions(from, to int64) []int64 {
+	var ret []int64
+	for v := from; v <= to; v++ {
+		ret = append(ret, v)
+	}
+	return ret
+}
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{name: "empty", sql: "\n-- comment\n"},
+		{name: "single", sql: "SELECT 1;", want: []string{"SELECT 1;"}},
+		{name: "multi line", sql: "-- comment\nCREATE TABLE t (\n  id int\n);\n\nDROP TABLE t;\n", want: []string{"CREATE TABLE t (\n  id int\n);", "DROP TABLE t;"}},
+		{name: "no trailing semicolon", sql: "SELECT 1;\nSELECT 2", want: []string{"SELECT 1;", "SELECT 2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitStatements(tt.sql); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("splitStatements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
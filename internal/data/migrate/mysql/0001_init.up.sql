@@ -1,4 +1,5 @@
-CREATE TABLE review_info (
+-- 评价服务初始表结构
+CREATE TABLE IF NOT EXISTS review_info (
     `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
     `create_by` varchar(48) NOT NULL DEFAULT ' ' COMMENT '创建方标识',
     `update_by` varchar(48) NOT NULL DEFAULT ' ' COMMENT '更新方标识',
@@ -24,6 +25,8 @@ CREATE TABLE review_info (
     `status` tinyint(4) NOT NULL DEFAULT '0' COMMENT '状态:10待审核;20审核通过;30审核不通过;40隐藏',
     `is_default` tinyint(4) NOT NULL DEFAULT '0' COMMENT '是否默认评价',
     `has_reply` tinyint(4) NOT NULL DEFAULT '0' COMMENT '是否有商家回复:0无;1有',
+    `helpful_count` int(10) unsigned NOT NULL DEFAULT '0' COMMENT '有用数',
+    `unhelpful_count` int(10) unsigned NOT NULL DEFAULT '0' COMMENT '无用数',
     `op_reason` varchar(512) NOT NULL DEFAULT ' ' COMMENT '运营审核拒绝原因',
     `op_remarks` varchar(512) NOT NULL DEFAULT ' ' COMMENT '运营备注',
     `op_user` varchar(64) NOT NULL DEFAULT ' ' COMMENT '运营者标识',
@@ -37,7 +40,7 @@ CREATE TABLE review_info (
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '评价表';
 
 
-CREATE TABLE review_reply_info (
+CREATE TABLE IF NOT EXISTS review_reply_info (
     `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
     `create_by` varchar(48) NOT NULL DEFAULT ' ' COMMENT '创建方标识',
     `update_by` varchar(48) NOT NULL DEFAULT ' ' COMMENT '更新方标识',
@@ -48,7 +51,12 @@ CREATE TABLE review_reply_info (
 
     `reply_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '回复id',
     `review_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '评价id',
+    `parent_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '被回复的回复id:0表示商家首条回复',
     `store_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '店铺id',
+    `user_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '用户id',
+    `author_type` tinyint(4) NOT NULL DEFAULT '1' COMMENT '作者类型:1商家;2用户',
+    `seq` int(10) unsigned NOT NULL DEFAULT '0' COMMENT '对话中的楼层序号',
+    `status` tinyint(4) NOT NULL DEFAULT '20' COMMENT '状态:10待审核;20审核通过;30审核不通过',
     `content` varchar(512) NOT NULL COMMENT '评价内容',
     `pic_info` varchar(1024) NOT NULL DEFAULT ' ' COMMENT '媒体信息:图片',
     `video_info` varchar(1024) NOT NULL DEFAULT ' ' COMMENT '媒体信息:视频',
@@ -57,11 +65,26 @@ CREATE TABLE review_reply_info (
     `ctrl_json` varchar(1024) NOT NULL DEFAULT ' ' COMMENT '控制扩展',
     PRIMARY KEY(`id`),
     KEY `idx_reply_id` (`reply_id`) COMMENT '回复id索引',
-    KEY `idx_review_id` (`review_id`) COMMENT '评价id索引',
+    KEY `idx_review_id_seq` (`review_id`, `seq`) COMMENT '评价id+楼层索引',
     KEY `idx_store_id` (`store_id`) COMMENT '店铺id索引'
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '评价商家回复表';
 
-CREATE TABLE review_appeal_info (
+CREATE TABLE IF NOT EXISTS review_reply_history (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
+    `create_by` varchar(48) NOT NULL DEFAULT ' ' COMMENT '创建方标识',
+    `create_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `reply_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '回复id',
+    `review_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '评价id',
+    `store_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '店铺id',
+    `version` int(10) unsigned NOT NULL DEFAULT '0' COMMENT '修改前的回复版本号',
+    `content` varchar(512) NOT NULL COMMENT '修改前的回复内容',
+    `pic_info` varchar(1024) NOT NULL DEFAULT ' ' COMMENT '修改前的媒体信息:图片',
+    `video_info` varchar(1024) NOT NULL DEFAULT ' ' COMMENT '修改前的媒体信息:视频',
+    PRIMARY KEY(`id`),
+    KEY `idx_reply_id` (`reply_id`) COMMENT '回复id索引'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '评价商家回复修改历史表';
+
+CREATE TABLE IF NOT EXISTS review_appeal_info (
     `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
     `create_by` varchar(48) NOT NULL DEFAULT ' ' COMMENT '创建方标识',
     `update_by` varchar(48) NOT NULL DEFAULT ' ' COMMENT '更新方标识',
@@ -85,6 +108,24 @@ CREATE TABLE review_appeal_info (
     PRIMARY KEY(`id`),
     KEY `idx_delete_at` (`delete_at`) COMMENT '逻辑删除索引',
     KEY `idx_appeal_id` (`appeal_id`) COMMENT '申诉d索引',
-    KEY `idx_review_id` (`review_id`) COMMENT '评价id索引',
+    UNIQUE KEY `uk_review_id` (`review_id`) COMMENT '一条评价只保留一条申诉，申诉按review_id做 ON DUPLICATE KEY 更新',
     KEY `idx_store_id` (`store_id`) COMMENT '店铺id索引'
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '评价商家申诉表';
+
+CREATE TABLE IF NOT EXISTS review_report_info (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
+    `create_by` varchar(48) NOT NULL DEFAULT ' ' COMMENT '创建方标识',
+    `update_by` varchar(48) NOT NULL DEFAULT ' ' COMMENT '更新方标识',
+    `create_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `update_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `report_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '举报id',
+    `review_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '评价id',
+    `user_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '举报用户id',
+    `reason` varchar(255) NOT NULL COMMENT '举报原因类别',
+    `content` varchar(255) NOT NULL DEFAULT ' ' COMMENT '举报内容描述',
+    `status` tinyint(4) NOT NULL DEFAULT '10' COMMENT '状态:10待处理;20已处理',
+    `op_user` varchar(64) NOT NULL DEFAULT ' ' COMMENT '运营者标识',
+    PRIMARY KEY(`id`),
+    UNIQUE KEY `uk_review_id_user_id` (`review_id`, `user_id`) COMMENT '同一用户对同一评价只能举报一次',
+    KEY `idx_report_id` (`report_id`) COMMENT '举报id索引'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '评价举报表';
@@ -0,0 +1,24 @@
+-- 库是之前手工执行 review.sql 建的时，0001_init 的 CREATE TABLE IF NOT EXISTS 不会改动已有的表
+-- 这里把已有的表补齐到 0001_init 的结构 只在 migrations 表为空且 review_info 已存在时和 0001_init 一起执行
+ALTER TABLE review_info
+    ADD COLUMN `helpful_count` int(10) unsigned NOT NULL DEFAULT '0' COMMENT '有用数' AFTER `has_reply`,
+    ADD COLUMN `unhelpful_count` int(10) unsigned NOT NULL DEFAULT '0' COMMENT '无用数' AFTER `helpful_count`;
+
+ALTER TABLE review_reply_info
+    ADD COLUMN `parent_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '被回复的回复id:0表示商家首条回复' AFTER `review_id`,
+    ADD COLUMN `user_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '用户id' AFTER `store_id`,
+    ADD COLUMN `author_type` tinyint(4) NOT NULL DEFAULT '1' COMMENT '作者类型:1商家;2用户' AFTER `user_id`,
+    ADD COLUMN `seq` int(10) unsigned NOT NULL DEFAULT '0' COMMENT '对话中的楼层序号' AFTER `author_type`,
+    ADD COLUMN `status` tinyint(4) NOT NULL DEFAULT '20' COMMENT '状态:10待审核;20审核通过;30审核不通过' AFTER `seq`,
+    DROP KEY `idx_review_id`,
+    ADD KEY `idx_review_id_seq` (`review_id`, `seq`) COMMENT '评价id+楼层索引';
+
+-- 已有的回复都是商家的首条回复
+UPDATE review_reply_info SET `seq` = 1 WHERE `parent_id` = 0;
+
+-- 之前 review_id 上不是唯一索引，同一评价可能有多条申诉，只保留最新的一条
+DELETE a FROM review_appeal_info a JOIN review_appeal_info b ON a.review_id = b.review_id AND a.id < b.id;
+
+ALTER TABLE review_appeal_info
+    DROP KEY `idx_review_id`,
+    ADD UNIQUE KEY `uk_review_id` (`review_id`) COMMENT '一条评价只保留一条申诉，申诉按review_id做 ON DUPLICATE KEY 更新';
@@ -0,0 +1,13 @@
+-- 类目的评分维度 评价的各维度评分保存在 review_info.ext_json 中
+CREATE TABLE IF NOT EXISTS review_dimension (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
+    `create_by` varchar(48) NOT NULL DEFAULT ' ' COMMENT '创建方标识',
+    `create_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `category_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '商品类目id',
+    `code` varchar(32) NOT NULL COMMENT '维度编码',
+    `name` varchar(64) NOT NULL COMMENT '维度名称',
+    `required` tinyint(4) NOT NULL DEFAULT '0' COMMENT '是否必填:0否;1是',
+    `sort` int(10) NOT NULL DEFAULT '0' COMMENT '展示顺序',
+    PRIMARY KEY(`id`),
+    UNIQUE KEY `uk_category_code` (`category_id`, `code`) COMMENT '类目下维度编码唯一'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '评分维度表';
@@ -0,0 +1,30 @@
+-- 商家配置的webhook 差评通知推送到这些地址
+CREATE TABLE IF NOT EXISTS store_webhook (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
+    `create_by` varchar(48) NOT NULL DEFAULT ' ' COMMENT '创建方标识',
+    `create_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `update_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `store_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '店铺id',
+    `url` varchar(512) NOT NULL COMMENT '推送地址',
+    `secret` varchar(64) NOT NULL COMMENT '签名密钥',
+    `max_score` tinyint(4) NOT NULL DEFAULT '2' COMMENT '评分不高于该值时通知',
+    `enabled` tinyint(4) NOT NULL DEFAULT '1' COMMENT '是否启用:0否;1是',
+    PRIMARY KEY(`id`),
+    KEY `idx_store_id` (`store_id`) COMMENT '店铺id索引'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '商家webhook表';
+
+-- 多次重试后仍然推送失败的通知
+CREATE TABLE IF NOT EXISTS webhook_dead_letter (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
+    `create_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `webhook_id` bigint unsigned NOT NULL DEFAULT '0' COMMENT 'webhook id',
+    `store_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '店铺id',
+    `event_id` varchar(64) NOT NULL COMMENT '事件id',
+    `event` varchar(32) NOT NULL COMMENT '事件类型',
+    `url` varchar(512) NOT NULL COMMENT '推送地址',
+    `payload` text NOT NULL COMMENT '推送内容',
+    `attempts` int(10) NOT NULL DEFAULT '0' COMMENT '已推送次数',
+    `last_error` varchar(512) NOT NULL DEFAULT ' ' COMMENT '最后一次失败原因',
+    PRIMARY KEY(`id`),
+    KEY `idx_store_id` (`store_id`) COMMENT '店铺id索引'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT 'webhook死信表';
@@ -0,0 +1,18 @@
+-- 评价导出任务 导出的文件保存在存储中，表中只记录文件的key
+CREATE TABLE IF NOT EXISTS review_export_job (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
+    `job_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '任务id',
+    `create_by` varchar(48) NOT NULL DEFAULT ' ' COMMENT '创建方标识',
+    `create_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `update_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `store_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '店铺id 0表示全部店铺',
+    `filter` varchar(1024) NOT NULL DEFAULT ' ' COMMENT '导出条件json',
+    `format` varchar(8) NOT NULL COMMENT '文件格式:csv;xlsx;jsonl',
+    `status` tinyint(4) NOT NULL DEFAULT '10' COMMENT '状态:10待执行;20执行中;30已完成;40失败',
+    `row_count` int(10) NOT NULL DEFAULT '0' COMMENT '已导出的行数',
+    `file_key` varchar(256) NOT NULL DEFAULT ' ' COMMENT '导出文件的key',
+    `err_msg` varchar(512) NOT NULL DEFAULT ' ' COMMENT '失败原因',
+    PRIMARY KEY(`id`),
+    UNIQUE KEY `uk_job_id` (`job_id`) COMMENT '任务id唯一',
+    KEY `idx_status` (`status`) COMMENT '状态索引'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '评价导出任务表';
@@ -0,0 +1,16 @@
+-- 评价变更事件 评价、回复、申诉变更时与业务数据在同一个事务中写入，WatchReviews 按自增主键顺序推送给下游
+-- 只保留最近一段时间的事件，由后台任务定期清理
+CREATE TABLE IF NOT EXISTS review_event (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键 即事件序号',
+    `create_at` timestamp(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3) COMMENT '创建时间',
+    `event_type` varchar(32) NOT NULL COMMENT '事件类型',
+    `review_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '评价id',
+    `store_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '店铺id',
+    `user_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '用户id',
+    `target_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '回复id或申诉id',
+    `payload` varchar(2048) NOT NULL DEFAULT ' ' COMMENT '变更内容json',
+    PRIMARY KEY(`id`),
+    KEY `idx_store_id` (`store_id`, `id`) COMMENT '店铺索引',
+    KEY `idx_user_id` (`user_id`, `id`) COMMENT '用户索引',
+    KEY `idx_create_at` (`create_at`) COMMENT '清理过期事件'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '评价变更事件表';
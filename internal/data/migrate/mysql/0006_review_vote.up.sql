@@ -0,0 +1,12 @@
+-- 评价投票 每个用户对一条评价的投票，与投票计数一起由定时任务从Redis刷回，Redis中的数据丢失时从这里重新加载
+-- 取消投票时删除记录
+CREATE TABLE IF NOT EXISTS review_vote_info (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
+    `create_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `update_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `review_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '评价id',
+    `user_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '投票用户id',
+    `vote` tinyint(4) NOT NULL DEFAULT '0' COMMENT '投票类型:1有用;2无用',
+    PRIMARY KEY(`id`),
+    UNIQUE KEY `uk_review_id_user_id` (`review_id`, `user_id`) COMMENT '同一用户对同一评价只保留一票'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '评价投票表';
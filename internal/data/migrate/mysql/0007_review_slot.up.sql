@@ -0,0 +1,10 @@
+-- 评价所在的槽位 启用分表前创建的评价ID中没有槽位，由分表迁移工具在迁移时写入，按评价ID查询时用来定位分表
+-- 记录槽位而不是分表名，调整分表数后不需要更新
+CREATE TABLE IF NOT EXISTS review_slot_info (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
+    `create_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `review_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '评价id',
+    `slot` int(11) NOT NULL DEFAULT '0' COMMENT '槽位',
+    PRIMARY KEY(`id`),
+    UNIQUE KEY `uk_review_id` (`review_id`) COMMENT '评价id'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '评价槽位表';
@@ -0,0 +1,11 @@
+-- 导入的评价 与评价在同一个事务中写入，唯一键保证同一店铺、同一来源的订单号只导入一次，并发导入时也不会重复
+CREATE TABLE IF NOT EXISTS review_import_info (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
+    `create_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `store_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '店铺id',
+    `source` varchar(32) NOT NULL DEFAULT '' COMMENT '来源平台',
+    `external_order_id` varchar(64) NOT NULL DEFAULT '' COMMENT '来源平台的订单号',
+    `review_id` bigint(32) NOT NULL DEFAULT '0' COMMENT '导入后的评价id',
+    PRIMARY KEY(`id`),
+    UNIQUE KEY `uk_store_id_source_external_order_id` (`store_id`, `source`, `external_order_id`) COMMENT '同一店铺、同一来源的订单号只导入一次'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '评价导入表';
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS review_report_info;
+DROP TABLE IF EXISTS review_appeal_info;
+DROP TABLE IF EXISTS review_reply_history;
+DROP TABLE IF EXISTS review_reply_info;
+DROP TABLE IF EXISTS review_info;
@@ -0,0 +1,123 @@
+-- 评价服务初始表结构 与mysql的表结构保持一致，用于本地开发和CI
+CREATE TABLE IF NOT EXISTS review_info (
+    `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+    `create_by` VARCHAR(48) NOT NULL DEFAULT ' ',
+    `update_by` VARCHAR(48) NOT NULL DEFAULT ' ',
+    `create_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `delete_at` DATETIME,
+    `version` INTEGER NOT NULL DEFAULT 0,
+    `review_id` INTEGER NOT NULL DEFAULT 0,
+    `content` VARCHAR(512) NOT NULL,
+    `score` INTEGER NOT NULL DEFAULT 0,
+    `service_score` INTEGER NOT NULL DEFAULT 0,
+    `express_score` INTEGER NOT NULL DEFAULT 0,
+    `has_media` INTEGER NOT NULL DEFAULT 0,
+    `order_id` INTEGER NOT NULL DEFAULT 0,
+    `sku_id` INTEGER NOT NULL DEFAULT 0,
+    `spu_id` INTEGER NOT NULL DEFAULT 0,
+    `store_id` INTEGER NOT NULL DEFAULT 0,
+    `user_id` INTEGER NOT NULL DEFAULT 0,
+    `anonymous` INTEGER NOT NULL DEFAULT 0,
+    `tags` VARCHAR(1024) NOT NULL DEFAULT ' ',
+    `pic_info` VARCHAR(1024) NOT NULL DEFAULT ' ',
+    `video_info` VARCHAR(1024) NOT NULL DEFAULT ' ',
+    `status` INTEGER NOT NULL DEFAULT 0,
+    `is_default` INTEGER NOT NULL DEFAULT 0,
+    `has_reply` INTEGER NOT NULL DEFAULT 0,
+    `helpful_count` INTEGER NOT NULL DEFAULT 0,
+    `unhelpful_count` INTEGER NOT NULL DEFAULT 0,
+    `op_reason` VARCHAR(512) NOT NULL DEFAULT ' ',
+    `op_remarks` VARCHAR(512) NOT NULL DEFAULT ' ',
+    `op_user` VARCHAR(64) NOT NULL DEFAULT ' ',
+    `goods_snapshot` VARCHAR(2048) NOT NULL DEFAULT ' ',
+    `ext_json` VARCHAR(1024) NOT NULL DEFAULT ' ',
+    `ctrl_json` VARCHAR(1024) NOT NULL DEFAULT ' '
+);
+CREATE INDEX IF NOT EXISTS idx_review_info_review_id ON review_info (`review_id`);
+CREATE INDEX IF NOT EXISTS idx_review_info_order_id ON review_info (`order_id`);
+CREATE INDEX IF NOT EXISTS idx_review_info_user_id ON review_info (`user_id`);
+
+CREATE TABLE IF NOT EXISTS review_reply_info (
+    `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+    `create_by` VARCHAR(48) NOT NULL DEFAULT ' ',
+    `update_by` VARCHAR(48) NOT NULL DEFAULT ' ',
+    `create_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `version` INTEGER NOT NULL DEFAULT 0,
+    `is_del` INTEGER NOT NULL DEFAULT 0,
+    `reply_id` INTEGER NOT NULL DEFAULT 0,
+    `review_id` INTEGER NOT NULL DEFAULT 0,
+    `parent_id` INTEGER NOT NULL DEFAULT 0,
+    `store_id` INTEGER NOT NULL DEFAULT 0,
+    `user_id` INTEGER NOT NULL DEFAULT 0,
+    `author_type` INTEGER NOT NULL DEFAULT 1,
+    `seq` INTEGER NOT NULL DEFAULT 0,
+    `status` INTEGER NOT NULL DEFAULT 20,
+    `content` VARCHAR(512) NOT NULL,
+    `pic_info` VARCHAR(1024) NOT NULL DEFAULT ' ',
+    `video_info` VARCHAR(1024) NOT NULL DEFAULT ' ',
+    `ext_json` VARCHAR(1024) NOT NULL DEFAULT ' ',
+    `ctrl_json` VARCHAR(1024) NOT NULL DEFAULT ' '
+);
+CREATE INDEX IF NOT EXISTS idx_review_reply_info_reply_id ON review_reply_info (`reply_id`);
+CREATE INDEX IF NOT EXISTS idx_review_reply_info_review_id_seq ON review_reply_info (`review_id`, `seq`);
+CREATE INDEX IF NOT EXISTS idx_review_reply_info_store_id ON review_reply_info (`store_id`);
+
+CREATE TABLE IF NOT EXISTS review_reply_history (
+    `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+    `create_by` VARCHAR(48) NOT NULL DEFAULT ' ',
+    `create_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `reply_id` INTEGER NOT NULL DEFAULT 0,
+    `review_id` INTEGER NOT NULL DEFAULT 0,
+    `store_id` INTEGER NOT NULL DEFAULT 0,
+    `version` INTEGER NOT NULL DEFAULT 0,
+    `content` VARCHAR(512) NOT NULL,
+    `pic_info` VARCHAR(1024) NOT NULL DEFAULT ' ',
+    `video_info` VARCHAR(1024) NOT NULL DEFAULT ' '
+);
+CREATE INDEX IF NOT EXISTS idx_review_reply_history_reply_id ON review_reply_history (`reply_id`);
+
+CREATE TABLE IF NOT EXISTS review_appeal_info (
+    `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+    `create_by` VARCHAR(48) NOT NULL DEFAULT ' ',
+    `update_by` VARCHAR(48) NOT NULL DEFAULT ' ',
+    `create_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `delete_at` DATETIME,
+    `version` INTEGER NOT NULL DEFAULT 0,
+    `appeal_id` INTEGER NOT NULL DEFAULT 0,
+    `review_id` INTEGER NOT NULL DEFAULT 0,
+    `store_id` INTEGER NOT NULL DEFAULT 0,
+    `status` INTEGER NOT NULL DEFAULT 10,
+    `reason` VARCHAR(255) NOT NULL,
+    `content` VARCHAR(255) NOT NULL,
+    `pic_info` VARCHAR(1024) NOT NULL DEFAULT ' ',
+    `video_info` VARCHAR(1024) NOT NULL DEFAULT ' ',
+    `op_remarks` VARCHAR(512) NOT NULL DEFAULT ' ',
+    `op_user` VARCHAR(64) NOT NULL DEFAULT ' ',
+    `ext_json` VARCHAR(1024) NOT NULL DEFAULT ' ',
+    `ctrl_json` VARCHAR(1024) NOT NULL DEFAULT ' '
+);
+CREATE INDEX IF NOT EXISTS idx_review_appeal_info_delete_at ON review_appeal_info (`delete_at`);
+CREATE INDEX IF NOT EXISTS idx_review_appeal_info_appeal_id ON review_appeal_info (`appeal_id`);
+-- 申诉按review_id做 ON CONFLICT 更新，sqlite要求冲突列上有唯一索引
+CREATE UNIQUE INDEX IF NOT EXISTS uk_review_appeal_info_review_id ON review_appeal_info (`review_id`);
+CREATE INDEX IF NOT EXISTS idx_review_appeal_info_store_id ON review_appeal_info (`store_id`);
+
+CREATE TABLE IF NOT EXISTS review_report_info (
+    `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+    `create_by` VARCHAR(48) NOT NULL DEFAULT ' ',
+    `update_by` VARCHAR(48) NOT NULL DEFAULT ' ',
+    `create_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `report_id` INTEGER NOT NULL DEFAULT 0,
+    `review_id` INTEGER NOT NULL DEFAULT 0,
+    `user_id` INTEGER NOT NULL DEFAULT 0,
+    `reason` VARCHAR(255) NOT NULL,
+    `content` VARCHAR(255) NOT NULL DEFAULT ' ',
+    `status` INTEGER NOT NULL DEFAULT 10,
+    `op_user` VARCHAR(64) NOT NULL DEFAULT ' '
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uk_review_report_info_review_id_user_id ON review_report_info (`review_id`, `user_id`);
+CREATE INDEX IF NOT EXISTS idx_review_report_info_report_id ON review_report_info (`report_id`);
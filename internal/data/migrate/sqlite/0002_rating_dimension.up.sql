@@ -0,0 +1,12 @@
+-- 类目的评分维度 评价的各维度评分保存在 review_info.ext_json 中
+CREATE TABLE IF NOT EXISTS review_dimension (
+    `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+    `create_by` VARCHAR(48) NOT NULL DEFAULT ' ',
+    `create_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `category_id` INTEGER NOT NULL DEFAULT 0,
+    `code` VARCHAR(32) NOT NULL,
+    `name` VARCHAR(64) NOT NULL,
+    `required` INTEGER NOT NULL DEFAULT 0,
+    `sort` INTEGER NOT NULL DEFAULT 0
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uk_category_code ON review_dimension (`category_id`, `code`);
@@ -0,0 +1,28 @@
+-- 商家配置的webhook 差评通知推送到这些地址
+CREATE TABLE IF NOT EXISTS store_webhook (
+    `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+    `create_by` VARCHAR(48) NOT NULL DEFAULT ' ',
+    `create_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `store_id` INTEGER NOT NULL DEFAULT 0,
+    `url` VARCHAR(512) NOT NULL,
+    `secret` VARCHAR(64) NOT NULL,
+    `max_score` INTEGER NOT NULL DEFAULT 2,
+    `enabled` INTEGER NOT NULL DEFAULT 1
+);
+CREATE INDEX IF NOT EXISTS idx_store_webhook_store_id ON store_webhook (`store_id`);
+
+-- 多次重试后仍然推送失败的通知
+CREATE TABLE IF NOT EXISTS webhook_dead_letter (
+    `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+    `create_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `webhook_id` INTEGER NOT NULL DEFAULT 0,
+    `store_id` INTEGER NOT NULL DEFAULT 0,
+    `event_id` VARCHAR(64) NOT NULL,
+    `event` VARCHAR(32) NOT NULL,
+    `url` VARCHAR(512) NOT NULL,
+    `payload` TEXT NOT NULL,
+    `attempts` INTEGER NOT NULL DEFAULT 0,
+    `last_error` VARCHAR(512) NOT NULL DEFAULT ' '
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_dead_letter_store_id ON webhook_dead_letter (`store_id`);
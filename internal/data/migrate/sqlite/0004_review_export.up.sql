@@ -0,0 +1,17 @@
+-- 评价导出任务 导出的文件保存在存储中，表中只记录文件的key
+CREATE TABLE IF NOT EXISTS review_export_job (
+    `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+    `job_id` INTEGER NOT NULL DEFAULT 0,
+    `create_by` VARCHAR(48) NOT NULL DEFAULT ' ',
+    `create_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `store_id` INTEGER NOT NULL DEFAULT 0,
+    `filter` VARCHAR(1024) NOT NULL DEFAULT ' ',
+    `format` VARCHAR(8) NOT NULL,
+    `status` INTEGER NOT NULL DEFAULT 10,
+    `row_count` INTEGER NOT NULL DEFAULT 0,
+    `file_key` VARCHAR(256) NOT NULL DEFAULT ' ',
+    `err_msg` VARCHAR(512) NOT NULL DEFAULT ' '
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uk_review_export_job_job_id ON review_export_job (`job_id`);
+CREATE INDEX IF NOT EXISTS idx_review_export_job_status ON review_export_job (`status`);
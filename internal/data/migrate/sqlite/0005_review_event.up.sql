@@ -0,0 +1,15 @@
+-- 评价变更事件 评价、回复、申诉变更时与业务数据在同一个事务中写入，WatchReviews 按自增主键顺序推送给下游
+-- 只保留最近一段时间的事件，由后台任务定期清理
+CREATE TABLE IF NOT EXISTS review_event (
+    `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+    `create_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `event_type` VARCHAR(32) NOT NULL,
+    `review_id` INTEGER NOT NULL DEFAULT 0,
+    `store_id` INTEGER NOT NULL DEFAULT 0,
+    `user_id` INTEGER NOT NULL DEFAULT 0,
+    `target_id` INTEGER NOT NULL DEFAULT 0,
+    `payload` VARCHAR(2048) NOT NULL DEFAULT ' '
+);
+CREATE INDEX IF NOT EXISTS idx_review_event_store_id ON review_event (`store_id`, `id`);
+CREATE INDEX IF NOT EXISTS idx_review_event_user_id ON review_event (`user_id`, `id`);
+CREATE INDEX IF NOT EXISTS idx_review_event_create_at ON review_event (`create_at`);
@@ -0,0 +1,11 @@
+-- 评价投票 每个用户对一条评价的投票，与投票计数一起由定时任务从Redis刷回，Redis中的数据丢失时从这里重新加载
+-- 取消投票时删除记录
+CREATE TABLE IF NOT EXISTS review_vote_info (
+    `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+    `create_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `review_id` INTEGER NOT NULL DEFAULT 0,
+    `user_id` INTEGER NOT NULL DEFAULT 0,
+    `vote` INTEGER NOT NULL DEFAULT 0
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uk_review_vote_info_review_id_user_id ON review_vote_info (`review_id`, `user_id`);
@@ -0,0 +1,9 @@
+-- 评价所在的槽位 启用分表前创建的评价ID中没有槽位，由分表迁移工具在迁移时写入，按评价ID查询时用来定位分表
+-- 记录槽位而不是分表名，调整分表数后不需要更新
+CREATE TABLE IF NOT EXISTS review_slot_info (
+    `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+    `create_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `review_id` INTEGER NOT NULL DEFAULT 0,
+    `slot` INTEGER NOT NULL DEFAULT 0
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uk_review_slot_info_review_id ON review_slot_info (`review_id`);
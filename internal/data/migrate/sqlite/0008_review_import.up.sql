@@ -0,0 +1,10 @@
+-- 导入的评价 与评价在同一个事务中写入，唯一键保证同一店铺、同一来源的订单号只导入一次，并发导入时也不会重复
+CREATE TABLE IF NOT EXISTS review_import_info (
+    `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+    `create_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `store_id` INTEGER NOT NULL DEFAULT 0,
+    `source` VARCHAR(32) NOT NULL DEFAULT '',
+    `external_order_id` VARCHAR(64) NOT NULL DEFAULT '',
+    `review_id` INTEGER NOT NULL DEFAULT 0
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uk_review_import_info_store_id_source_external_order_id ON review_import_info (`store_id`, `source`, `external_order_id`);
@@ -0,0 +1 @@
+ALTER TABLE review_event DROP COLUMN `anonymous`;
@@ -0,0 +1,2 @@
+-- 变更事件记录评价是否匿名 匿名评价的用户id只推送给运营和评价的作者本人
+ALTER TABLE review_event ADD COLUMN `anonymous` INTEGER NOT NULL DEFAULT 0;
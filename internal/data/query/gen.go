@@ -16,44 +16,94 @@ import (
 )
 
 var (
-	Q                = new(Query)
-	ReviewAppealInfo *reviewAppealInfo
-	ReviewInfo       *reviewInfo
-	ReviewReplyInfo  *reviewReplyInfo
+	Q                  = new(Query)
+	ReviewAppealInfo   *reviewAppealInfo
+	ReviewDimension    *reviewDimension
+	ReviewEvent        *reviewEvent
+	ReviewExportJob    *reviewExportJob
+	ReviewImportInfo   *reviewImportInfo
+	ReviewInfo         *reviewInfo
+	ReviewReplyHistory *reviewReplyHistory
+	ReviewReplyInfo    *reviewReplyInfo
+	ReviewReportInfo   *reviewReportInfo
+	ReviewSlotInfo     *reviewSlotInfo
+	ReviewVoteInfo     *reviewVoteInfo
+	StoreWebhook       *storeWebhook
+	WebhookDeadLetter  *webhookDeadLetter
 )
 
 func SetDefault(db *gorm.DB, opts ...gen.DOOption) {
 	*Q = *Use(db, opts...)
 	ReviewAppealInfo = &Q.ReviewAppealInfo
+	ReviewDimension = &Q.ReviewDimension
+	ReviewEvent = &Q.ReviewEvent
+	ReviewExportJob = &Q.ReviewExportJob
+	ReviewImportInfo = &Q.ReviewImportInfo
 	ReviewInfo = &Q.ReviewInfo
+	ReviewReplyHistory = &Q.ReviewReplyHistory
 	ReviewReplyInfo = &Q.ReviewReplyInfo
+	ReviewReportInfo = &Q.ReviewReportInfo
+	ReviewSlotInfo = &Q.ReviewSlotInfo
+	ReviewVoteInfo = &Q.ReviewVoteInfo
+	StoreWebhook = &Q.StoreWebhook
+	WebhookDeadLetter = &Q.WebhookDeadLetter
 }
 
 func Use(db *gorm.DB, opts ...gen.DOOption) *Query {
 	return &Query{
-		db:               db,
-		ReviewAppealInfo: newReviewAppealInfo(db, opts...),
-		ReviewInfo:       newReviewInfo(db, opts...),
-		ReviewReplyInfo:  newReviewReplyInfo(db, opts...),
+		db:                 db,
+		ReviewAppealInfo:   newReviewAppealInfo(db, opts...),
+		ReviewDimension:    newReviewDimension(db, opts...),
+		ReviewEvent:        newReviewEvent(db, opts...),
+		ReviewExportJob:    newReviewExportJob(db, opts...),
+		ReviewImportInfo:   newReviewImportInfo(db, opts...),
+		ReviewInfo:         newReviewInfo(db, opts...),
+		ReviewReplyHistory: newReviewReplyHistory(db, opts...),
+		ReviewReplyInfo:    newReviewReplyInfo(db, opts...),
+		ReviewReportInfo:   newReviewReportInfo(db, opts...),
+		ReviewSlotInfo:     newReviewSlotInfo(db, opts...),
+		ReviewVoteInfo:     newReviewVoteInfo(db, opts...),
+		StoreWebhook:       newStoreWebhook(db, opts...),
+		WebhookDeadLetter:  newWebhookDeadLetter(db, opts...),
 	}
 }
 
 type Query struct {
 	db *gorm.DB
 
-	ReviewAppealInfo reviewAppealInfo
-	ReviewInfo       reviewInfo
-	ReviewReplyInfo  reviewReplyInfo
+	ReviewAppealInfo   reviewAppealInfo
+	ReviewDimension    reviewDimension
+	ReviewEvent        reviewEvent
+	ReviewExportJob    reviewExportJob
+	ReviewImportInfo   reviewImportInfo
+	ReviewInfo         reviewInfo
+	ReviewReplyHistory reviewReplyHistory
+	ReviewReplyInfo    reviewReplyInfo
+	ReviewReportInfo   reviewReportInfo
+	ReviewSlotInfo     reviewSlotInfo
+	ReviewVoteInfo     reviewVoteInfo
+	StoreWebhook       storeWebhook
+	WebhookDeadLetter  webhookDeadLetter
 }
 
 func (q *Query) Available() bool { return q.db != nil }
 
 func (q *Query) clone(db *gorm.DB) *Query {
 	return &Query{
-		db:               db,
-		ReviewAppealInfo: q.ReviewAppealInfo.clone(db),
-		ReviewInfo:       q.ReviewInfo.clone(db),
-		ReviewReplyInfo:  q.ReviewReplyInfo.clone(db),
+		db:                 db,
+		ReviewAppealInfo:   q.ReviewAppealInfo.clone(db),
+		ReviewDimension:    q.ReviewDimension.clone(db),
+		ReviewEvent:        q.ReviewEvent.clone(db),
+		ReviewExportJob:    q.ReviewExportJob.clone(db),
+		ReviewImportInfo:   q.ReviewImportInfo.clone(db),
+		ReviewInfo:         q.ReviewInfo.clone(db),
+		ReviewReplyHistory: q.ReviewReplyHistory.clone(db),
+		ReviewReplyInfo:    q.ReviewReplyInfo.clone(db),
+		ReviewReportInfo:   q.ReviewReportInfo.clone(db),
+		ReviewSlotInfo:     q.ReviewSlotInfo.clone(db),
+		ReviewVoteInfo:     q.ReviewVoteInfo.clone(db),
+		StoreWebhook:       q.StoreWebhook.clone(db),
+		WebhookDeadLetter:  q.WebhookDeadLetter.clone(db),
 	}
 }
 
@@ -67,24 +117,54 @@ func (q *Query) WriteDB() *Query {
 
 func (q *Query) ReplaceDB(db *gorm.DB) *Query {
 	return &Query{
-		db:               db,
-		ReviewAppealInfo: q.ReviewAppealInfo.replaceDB(db),
-		ReviewInfo:       q.ReviewInfo.replaceDB(db),
-		ReviewReplyInfo:  q.ReviewReplyInfo.replaceDB(db),
+		db:                 db,
+		ReviewAppealInfo:   q.ReviewAppealInfo.replaceDB(db),
+		ReviewDimension:    q.ReviewDimension.replaceDB(db),
+		ReviewEvent:        q.ReviewEvent.replaceDB(db),
+		ReviewExportJob:    q.ReviewExportJob.replaceDB(db),
+		ReviewImportInfo:   q.ReviewImportInfo.replaceDB(db),
+		ReviewInfo:         q.ReviewInfo.replaceDB(db),
+		ReviewReplyHistory: q.ReviewReplyHistory.replaceDB(db),
+		ReviewReplyInfo:    q.ReviewReplyInfo.replaceDB(db),
+		ReviewReportInfo:   q.ReviewReportInfo.replaceDB(db),
+		ReviewSlotInfo:     q.ReviewSlotInfo.replaceDB(db),
+		ReviewVoteInfo:     q.ReviewVoteInfo.replaceDB(db),
+		StoreWebhook:       q.StoreWebhook.replaceDB(db),
+		WebhookDeadLetter:  q.WebhookDeadLetter.replaceDB(db),
 	}
 }
 
 type queryCtx struct {
-	ReviewAppealInfo IReviewAppealInfoDo
-	ReviewInfo       IReviewInfoDo
-	ReviewReplyInfo  IReviewReplyInfoDo
+	ReviewAppealInfo   IReviewAppealInfoDo
+	ReviewDimension    IReviewDimensionDo
+	ReviewEvent        IReviewEventDo
+	ReviewExportJob    IReviewExportJobDo
+	ReviewImportInfo   IReviewImportInfoDo
+	ReviewInfo         IReviewInfoDo
+	ReviewReplyHistory IReviewReplyHistoryDo
+	ReviewReplyInfo    IReviewReplyInfoDo
+	ReviewReportInfo   IReviewReportInfoDo
+	ReviewSlotInfo     IReviewSlotInfoDo
+	ReviewVoteInfo     IReviewVoteInfoDo
+	StoreWebhook       IStoreWebhookDo
+	WebhookDeadLetter  IWebhookDeadLetterDo
 }
 
 func (q *Query) WithContext(ctx context.Context) *queryCtx {
 	return &queryCtx{
-		ReviewAppealInfo: q.ReviewAppealInfo.WithContext(ctx),
-		ReviewInfo:       q.ReviewInfo.WithContext(ctx),
-		ReviewReplyInfo:  q.ReviewReplyInfo.WithContext(ctx),
+		ReviewAppealInfo:   q.ReviewAppealInfo.WithContext(ctx),
+		ReviewDimension:    q.ReviewDimension.WithContext(ctx),
+		ReviewEvent:        q.ReviewEvent.WithContext(ctx),
+		ReviewExportJob:    q.ReviewExportJob.WithContext(ctx),
+		ReviewImportInfo:   q.ReviewImportInfo.WithContext(ctx),
+		ReviewInfo:         q.ReviewInfo.WithContext(ctx),
+		ReviewReplyHistory: q.ReviewReplyHistory.WithContext(ctx),
+		ReviewReplyInfo:    q.ReviewReplyInfo.WithContext(ctx),
+		ReviewReportInfo:   q.ReviewReportInfo.WithContext(ctx),
+		ReviewSlotInfo:     q.ReviewSlotInfo.WithContext(ctx),
+		ReviewVoteInfo:     q.ReviewVoteInfo.WithContext(ctx),
+		StoreWebhook:       q.StoreWebhook.WithContext(ctx),
+		WebhookDeadLetter:  q.WebhookDeadLetter.WithContext(ctx),
 	}
 }
 
@@ -52,6 +52,8 @@ func newReviewInfo(db *gorm.DB, opts ...gen.DOOption) reviewInfo {
 	_reviewInfo.Status = field.NewInt32(tableName, "status")
 	_reviewInfo.IsDefault = field.NewInt32(tableName, "is_default")
 	_reviewInfo.HasReply = field.NewInt32(tableName, "has_reply")
+	_reviewInfo.HelpfulCount = field.NewInt32(tableName, "helpful_count")
+	_reviewInfo.UnhelpfulCount = field.NewInt32(tableName, "unhelpful_count")
 	_reviewInfo.OpReason = field.NewString(tableName, "op_reason")
 	_reviewInfo.OpRemarks = field.NewString(tableName, "op_remarks")
 	_reviewInfo.OpUser = field.NewString(tableName, "op_user")
@@ -67,38 +69,40 @@ func newReviewInfo(db *gorm.DB, opts ...gen.DOOption) reviewInfo {
 type reviewInfo struct {
 	reviewInfoDo reviewInfoDo
 
-	ALL           field.Asterisk
-	ID            field.Int64  // 主键
-	CreateBy      field.String // 创建方标识
-	UpdateBy      field.String // 更新方标识
-	CreateAt      field.Time   // 创建时间
-	UpdateAt      field.Time   // 更新时间
-	DeleteAt      field.Time   // 逻辑删除标记
-	Version       field.Int32  // 乐观锁标记
-	ReviewID      field.Int64  // 评价id
-	Content       field.String // 评价内容
-	Score         field.Int32  // 评分
-	ServiceScore  field.Int32  // 商家评分
-	ExpressScore  field.Int32  // 评分
-	HasMedia      field.Int32  // 是否有图或视频
-	OrderID       field.Int64  // 订单id
-	SkuID         field.Int64  // sku id
-	SpuID         field.Int64  // spu id
-	StoreID       field.Int64  // 店铺id
-	UserID        field.Int64  // 用户id
-	Anonymous     field.Int32  // 是否匿名
-	Tags          field.String // 标签json
-	PicInfo       field.String // 媒体信息:图片
-	VideoInfo     field.String // 媒体信息:视频
-	Status        field.Int32  // 状态:10待审核;20审核通过;30审核不通过;40隐藏
-	IsDefault     field.Int32  // 是否默认评价
-	HasReply      field.Int32  // 是否有商家回复:0无;1有
-	OpReason      field.String // 运营审核拒绝原因
-	OpRemarks     field.String // 运营备注
-	OpUser        field.String // 运营者标识
-	GoodsSnapshot field.String // 商品快照信息
-	ExtJSON       field.String // 信息扩展
-	CtrlJSON      field.String // 控制扩展
+	ALL            field.Asterisk
+	ID             field.Int64  // 主键
+	CreateBy       field.String // 创建方标识
+	UpdateBy       field.String // 更新方标识
+	CreateAt       field.Time   // 创建时间
+	UpdateAt       field.Time   // 更新时间
+	DeleteAt       field.Time   // 逻辑删除标记
+	Version        field.Int32  // 乐观锁标记
+	ReviewID       field.Int64  // 评价id
+	Content        field.String // 评价内容
+	Score          field.Int32  // 评分
+	ServiceScore   field.Int32  // 商家评分
+	ExpressScore   field.Int32  // 评分
+	HasMedia       field.Int32  // 是否有图或视频
+	OrderID        field.Int64  // 订单id
+	SkuID          field.Int64  // sku id
+	SpuID          field.Int64  // spu id
+	StoreID        field.Int64  // 店铺id
+	UserID         field.Int64  // 用户id
+	Anonymous      field.Int32  // 是否匿名
+	Tags           field.String // 标签json
+	PicInfo        field.String // 媒体信息:图片
+	VideoInfo      field.String // 媒体信息:视频
+	Status         field.Int32  // 状态:10待审核;20审核通过;30审核不通过;40隐藏
+	IsDefault      field.Int32  // 是否默认评价
+	HasReply       field.Int32  // 是否有商家回复:0无;1有
+	HelpfulCount   field.Int32  // 有用数
+	UnhelpfulCount field.Int32  // 无用数
+	OpReason       field.String // 运营审核拒绝原因
+	OpRemarks      field.String // 运营备注
+	OpUser         field.String // 运营者标识
+	GoodsSnapshot  field.String // 商品快照信息
+	ExtJSON        field.String // 信息扩展
+	CtrlJSON       field.String // 控制扩展
 
 	fieldMap map[string]field.Expr
 }
@@ -140,6 +144,8 @@ func (r *reviewInfo) updateTableName(table string) *reviewInfo {
 	r.Status = field.NewInt32(table, "status")
 	r.IsDefault = field.NewInt32(table, "is_default")
 	r.HasReply = field.NewInt32(table, "has_reply")
+	r.HelpfulCount = field.NewInt32(table, "helpful_count")
+	r.UnhelpfulCount = field.NewInt32(table, "unhelpful_count")
 	r.OpReason = field.NewString(table, "op_reason")
 	r.OpRemarks = field.NewString(table, "op_remarks")
 	r.OpUser = field.NewString(table, "op_user")
@@ -172,7 +178,7 @@ func (r *reviewInfo) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
 }
 
 func (r *reviewInfo) fillFieldMap() {
-	r.fieldMap = make(map[string]field.Expr, 31)
+	r.fieldMap = make(map[string]field.Expr, 33)
 	r.fieldMap["id"] = r.ID
 	r.fieldMap["create_by"] = r.CreateBy
 	r.fieldMap["update_by"] = r.UpdateBy
@@ -198,6 +204,8 @@ func (r *reviewInfo) fillFieldMap() {
 	r.fieldMap["status"] = r.Status
 	r.fieldMap["is_default"] = r.IsDefault
 	r.fieldMap["has_reply"] = r.HasReply
+	r.fieldMap["helpful_count"] = r.HelpfulCount
+	r.fieldMap["unhelpful_count"] = r.UnhelpfulCount
 	r.fieldMap["op_reason"] = r.OpReason
 	r.fieldMap["op_remarks"] = r.OpRemarks
 	r.fieldMap["op_user"] = r.OpUser
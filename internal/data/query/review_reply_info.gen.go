@@ -36,7 +36,12 @@ func newReviewReplyInfo(db *gorm.DB, opts ...gen.DOOption) reviewReplyInfo {
 	_reviewReplyInfo.IsDel = field.NewInt32(tableName, "is_del")
 	_reviewReplyInfo.ReplyID = field.NewInt64(tableName, "reply_id")
 	_reviewReplyInfo.ReviewID = field.NewInt64(tableName, "review_id")
+	_reviewReplyInfo.ParentID = field.NewInt64(tableName, "parent_id")
 	_reviewReplyInfo.StoreID = field.NewInt64(tableName, "store_id")
+	_reviewReplyInfo.UserID = field.NewInt64(tableName, "user_id")
+	_reviewReplyInfo.AuthorType = field.NewInt32(tableName, "author_type")
+	_reviewReplyInfo.Seq = field.NewInt32(tableName, "seq")
+	_reviewReplyInfo.Status = field.NewInt32(tableName, "status")
 	_reviewReplyInfo.Content = field.NewString(tableName, "content")
 	_reviewReplyInfo.PicInfo = field.NewString(tableName, "pic_info")
 	_reviewReplyInfo.VideoInfo = field.NewString(tableName, "video_info")
@@ -51,22 +56,27 @@ func newReviewReplyInfo(db *gorm.DB, opts ...gen.DOOption) reviewReplyInfo {
 type reviewReplyInfo struct {
 	reviewReplyInfoDo reviewReplyInfoDo
 
-	ALL       field.Asterisk
-	ID        field.Int64  // 主键
-	CreateBy  field.String // 创建方标识
-	UpdateBy  field.String // 更新方标识
-	CreateAt  field.Time   // 创建时间
-	UpdateAt  field.Time   // 更新时间
-	Version   field.Int32  // 乐观锁标记
-	IsDel     field.Int32  // 逻辑删除标记: 0正常 1删除
-	ReplyID   field.Int64  // 回复id
-	ReviewID  field.Int64  // 评价id
-	StoreID   field.Int64  // 店铺id
-	Content   field.String // 评价内容
-	PicInfo   field.String // 媒体信息:图片
-	VideoInfo field.String // 媒体信息:视频
-	ExtJSON   field.String // 信息扩展
-	CtrlJSON  field.String // 控制扩展
+	ALL        field.Asterisk
+	ID         field.Int64  // 主键
+	CreateBy   field.String // 创建方标识
+	UpdateBy   field.String // 更新方标识
+	CreateAt   field.Time   // 创建时间
+	UpdateAt   field.Time   // 更新时间
+	Version    field.Int32  // 乐观锁标记
+	IsDel      field.Int32  // 逻辑删除标记: 0正常 1删除
+	ReplyID    field.Int64  // 回复id
+	ReviewID   field.Int64  // 评价id
+	ParentID   field.Int64  // 被回复的回复id:0表示商家首条回复
+	StoreID    field.Int64  // 店铺id
+	UserID     field.Int64  // 用户id
+	AuthorType field.Int32  // 作者类型:1商家;2用户
+	Seq        field.Int32  // 对话中的楼层序号
+	Status     field.Int32  // 状态:10待审核;20审核通过;30审核不通过
+	Content    field.String // 评价内容
+	PicInfo    field.String // 媒体信息:图片
+	VideoInfo  field.String // 媒体信息:视频
+	ExtJSON    field.String // 信息扩展
+	CtrlJSON   field.String // 控制扩展
 
 	fieldMap map[string]field.Expr
 }
@@ -92,7 +102,12 @@ func (r *reviewReplyInfo) updateTableName(table string) *reviewReplyInfo {
 	r.IsDel = field.NewInt32(table, "is_del")
 	r.ReplyID = field.NewInt64(table, "reply_id")
 	r.ReviewID = field.NewInt64(table, "review_id")
+	r.ParentID = field.NewInt64(table, "parent_id")
 	r.StoreID = field.NewInt64(table, "store_id")
+	r.UserID = field.NewInt64(table, "user_id")
+	r.AuthorType = field.NewInt32(table, "author_type")
+	r.Seq = field.NewInt32(table, "seq")
+	r.Status = field.NewInt32(table, "status")
 	r.Content = field.NewString(table, "content")
 	r.PicInfo = field.NewString(table, "pic_info")
 	r.VideoInfo = field.NewString(table, "video_info")
@@ -126,7 +141,7 @@ func (r *reviewReplyInfo) GetFieldByName(fieldName string) (field.OrderExpr, boo
 }
 
 func (r *reviewReplyInfo) fillFieldMap() {
-	r.fieldMap = make(map[string]field.Expr, 15)
+	r.fieldMap = make(map[string]field.Expr, 20)
 	r.fieldMap["id"] = r.ID
 	r.fieldMap["create_by"] = r.CreateBy
 	r.fieldMap["update_by"] = r.UpdateBy
@@ -136,7 +151,12 @@ func (r *reviewReplyInfo) fillFieldMap() {
 	r.fieldMap["is_del"] = r.IsDel
 	r.fieldMap["reply_id"] = r.ReplyID
 	r.fieldMap["review_id"] = r.ReviewID
+	r.fieldMap["parent_id"] = r.ParentID
 	r.fieldMap["store_id"] = r.StoreID
+	r.fieldMap["user_id"] = r.UserID
+	r.fieldMap["author_type"] = r.AuthorType
+	r.fieldMap["seq"] = r.Seq
+	r.fieldMap["status"] = r.Status
 	r.fieldMap["content"] = r.Content
 	r.fieldMap["pic_info"] = r.PicInfo
 	r.fieldMap["video_info"] = r.VideoInfo
@@ -0,0 +1,126 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+
+	"review-service/internal/conf"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// 读写分离
+// 配置了从库时读请求随机路由到从库，写请求和事务走主库
+// 从库有复制延迟，同一个请求中写过数据之后的读请求强制走主库，保证能读到自己刚写的数据
+
+// rwStateKey 请求上下文中记录本次请求是否写过数据
+type rwStateKey struct{}
+
+type rwState struct {
+	wrote atomic.Bool
+}
+
+// ReadYourWrites 为每个请求创建读写状态 需要加在服务端的中间件中
+func ReadYourWrites() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			return handler(context.WithValue(ctx, rwStateKey{}, &rwState{}), req)
+		}
+	}
+}
+
+// useReplicas 配置了从库时启用dbresolver 返回关闭从库连接池的函数
+func useReplicas(db *gorm.DB, cfg *conf.Data_Database) (func(), error) {
+	var pools []*sql.DB
+	closeAll := func() {
+		for _, p := range pools {
+			_ = p.Close()
+		}
+	}
+	if len(cfg.GetReplicas()) == 0 {
+		return closeAll, nil
+	}
+	replicas := make([]gorm.Dialector, 0, len(cfg.GetReplicas()))
+	for _, dsn := range cfg.GetReplicas() {
+		// 自己打开连接池，从库和主库使用同样的连接池配置，退出时也能关闭
+		sqlDB, err := sql.Open("mysql", dsn)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		setConnPool(sqlDB, cfg)
+		pools = append(pools, sqlDB)
+		replicas = append(replicas, mysql.New(mysql.Config{Conn: sqlDB}))
+	}
+	if err := db.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   dbresolver.RandomPolicy{},
+	})); err != nil {
+		closeAll()
+		return nil, err
+	}
+	if err := registerReadYourWrites(db); err != nil {
+		closeAll()
+		return nil, err
+	}
+	return closeAll, nil
+}
+
+// registerReadYourWrites 写操作成功后标记请求，之后的读操作在dbresolver选库之前强制走主库
+func registerReadYourWrites(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().After("gorm:create").Register("review:mark_write", markWrite); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("review:mark_write", markWrite); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("review:mark_write", markWrite); err != nil {
+		return err
+	}
+	if err := cb.Raw().After("gorm:raw").Register("review:mark_write", markWrite); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:db_resolver").Register("review:read_your_writes", readPrimary); err != nil {
+		return err
+	}
+	return cb.Row().Before("gorm:db_resolver").Register("review:read_your_writes", readPrimary)
+}
+
+func markWrite(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Context == nil {
+		return
+	}
+	if s, ok := db.Statement.Context.Value(rwStateKey{}).(*rwState); ok {
+		s.wrote.Store(true)
+	}
+}
+
+func readPrimary(db *gorm.DB) {
+	if db.Statement.Context == nil {
+		return
+	}
+	if s, ok := db.Statement.Context.Value(rwStateKey{}).(*rwState); ok && s.wrote.Load() {
+		dbresolver.Write.ModifyStatement(db.Statement)
+	}
+}
+
+// setConnPool 连接池配置 不配置时使用database/sql的默认值
+func setConnPool(sqlDB *sql.DB, cfg *conf.Data_Database) {
+	if n := cfg.GetMaxOpenConns(); n > 0 {
+		sqlDB.SetMaxOpenConns(int(n))
+	}
+	if n := cfg.GetMaxIdleConns(); n > 0 {
+		sqlDB.SetMaxIdleConns(int(n))
+	}
+	if d := cfg.GetConnMaxLifetime().AsDuration(); d > 0 {
+		sqlDB.SetConnMaxLifetime(d)
+	}
+	if d := cfg.GetConnMaxIdleTime().AsDuration(); d > 0 {
+		sqlDB.SetConnMaxIdleTime(d)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -12,9 +13,11 @@ import (
 	"review-service/internal/biz"
 	"review-service/internal/data/model"
 	"review-service/internal/data/query"
+	"review-service/internal/metrics"
 	"review-service/pkg/snowflake"
 
 	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/sortorder"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/redis/go-redis/v9"
 	"golang.org/x/sync/singleflight"
@@ -23,39 +26,120 @@ import (
 )
 
 type reviewRepo struct {
-	data *Data
-	log  *log.Helper
+	data    *Data
+	runtime *biz.Runtime
+	log     *log.Helper
 }
 
 // NewGreeterRepo .
-func NewReviewRepo(data *Data, logger log.Logger) biz.ReviewRepo {
+func NewReviewRepo(data *Data, runtime *biz.Runtime, logger log.Logger) biz.ReviewRepo {
 	return &reviewRepo{
-		data: data,
-		log:  log.NewHelper(logger),
+		data:    data,
+		runtime: runtime,
+		log:     log.NewHelper(logger),
 	}
 }
 
+// reviewTable 评价ID所在的分表
+// 启用分表前创建的评价ID中没有槽位，槽位由分表迁移工具记录在 review_slot_info 中
+func (r *reviewRepo) reviewTable(ctx context.Context, reviewID int64) (string, error) {
+	s := r.data.sharding
+	if !s.enabled() {
+		return model.TableNameReviewInfo, nil
+	}
+	if reviewID > s.legacyMaxID {
+		return ShardTable(snowflake.SlotOf(reviewID), s.tables), nil
+	}
+	rs := r.data.query.ReviewSlotInfo
+	slot, err := rs.WithContext(ctx).Select(rs.Slot).Where(rs.ReviewID.Eq(reviewID)).First()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", biz.ErrReviewNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return ShardTable(int64(slot.Slot), s.tables), nil
+}
+
+// NewReviewID 生成评价ID 分表时ID中带上评价所在的槽位
+func (r *reviewRepo) NewReviewID(review *model.ReviewInfo) (int64, error) {
+	if !r.data.sharding.enabled() {
+		return r.data.idgen.NextID()
+	}
+	return r.data.idgen.NextSlotID(ReviewSlot(review, r.data.sharding.key))
+}
+
 // SaveReview 保存评价到数据库中
 func (r *reviewRepo) SaveReview(ctx context.Context, review *model.ReviewInfo) (*model.ReviewInfo, error) {
-	err := r.data.query.ReviewInfo.WithContext(ctx).Save(review)
-	return review, err
+	table := ShardTable(snowflake.SlotOf(review.ReviewID), r.data.sharding.tables)
+	err := r.data.query.Transaction(func(tx *query.Query) error {
+		if err := tx.ReviewInfo.Table(table).WithContext(ctx).Save(review); err != nil {
+			return err
+		}
+		return addEvent(ctx, tx, biz.EventReviewCreated, review, 0, reviewPayload(review))
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.indexReviewStats(ctx, review)
+	return review, nil
 }
 
-// GetReviewByOrderID 根据订单ID查询评价
-func (r *reviewRepo) GetReviewByOrderID(ctx context.Context, id int64) ([]*model.ReviewInfo, error) {
-	return r.data.query.ReviewInfo.WithContext(ctx).Where(r.data.query.ReviewInfo.OrderID.Eq(id)).Find()
+// GetReviewByOrderID 查询评价对应的订单已有的评价 按评价的分片键定位分表
+func (r *reviewRepo) GetReviewByOrderID(ctx context.Context, review *model.ReviewInfo) ([]*model.ReviewInfo, error) {
+	ri := r.data.query.ReviewInfo.Table(ShardTable(ReviewSlot(review, r.data.sharding.key), r.data.sharding.tables))
+	return ri.WithContext(ctx).Where(ri.OrderID.Eq(review.OrderID)).Find()
 }
 
 // GetReviewByReviewID 根据评价ID获取评价
 func (r *reviewRepo) GetReviewByReviewID(ctx context.Context, id int64) (*model.ReviewInfo, error) {
-	return r.data.query.ReviewInfo.WithContext(ctx).Where(r.data.query.ReviewInfo.ReviewID.Eq(id)).First()
+	table, err := r.reviewTable(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ri := r.data.query.ReviewInfo.Table(table)
+	review, err := ri.WithContext(ctx).Where(ri.ReviewID.Eq(id)).First()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, biz.ErrReviewNotFound
+	}
+	return review, err
 }
 
 // ListReviewByUserID 通过用户ID查询用户评价列表
 func (r *reviewRepo) ListReviewByUserID(ctx context.Context, userID int64, offset, limit int) ([]*model.ReviewInfo, error) {
-	return r.data.query.ReviewInfo.WithContext(ctx).Where(r.data.query.ReviewInfo.UserID.Eq(userID)).
-		Order(r.data.query.ReviewInfo.ID.Desc()).
-		Offset(offset).Limit(limit).Find()
+	s := r.data.sharding
+	if !s.enabled() {
+		return r.data.query.ReviewInfo.WithContext(ctx).Where(r.data.query.ReviewInfo.UserID.Eq(userID)).
+			Order(r.data.query.ReviewInfo.ID.Desc()).
+			Offset(offset).Limit(limit).Find()
+	}
+	// 各分表的自增ID互相独立，分表后按review_id(生成时间)排序
+	if s.key == ShardKeyUserID {
+		ri := r.data.query.ReviewInfo.Table(s.table(userID))
+		return ri.WithContext(ctx).Where(ri.UserID.Eq(userID)).
+			Order(ri.ReviewID.Desc()).
+			Offset(offset).Limit(limit).Find()
+	}
+	// 按店铺分表时用户的评价分散在各分表中，每个分表取前offset+limit条合并后再分页
+	var list []*model.ReviewInfo
+	for _, table := range ShardTables(s.tables) {
+		ri := r.data.query.ReviewInfo.Table(table)
+		ret, err := ri.WithContext(ctx).Where(ri.UserID.Eq(userID)).
+			Order(ri.ReviewID.Desc()).
+			Limit(offset + limit).Find()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, ret...)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ReviewID > list[j].ReviewID })
+	if offset >= len(list) {
+		return []*model.ReviewInfo{}, nil
+	}
+	if end := offset + limit; end < len(list) {
+		list = list[:end]
+	}
+	return list[offset:], nil
 }
 
 // SaveReply 保存商家回复到数据库中
@@ -63,53 +147,275 @@ func (r *reviewRepo) SaveReply(ctx context.Context, reply *model.ReviewReplyInfo
 	//1.数据校验
 	//1.1 数据合法性校验 (已经回复的评价不允许商家再次回复)
 	// 根据reviewID查询数据库，查看是否存已回复
-	review, err := r.data.query.ReviewInfo.WithContext(ctx).
-		Where(r.data.query.ReviewInfo.ReviewID.Eq(reply.ReviewID)).First()
+	table, err := r.reviewTable(ctx, reply.ReviewID)
+	if err != nil {
+		return nil, err
+	}
+	ri := r.data.query.ReviewInfo.Table(table)
+	review, err := ri.WithContext(ctx).Where(ri.ReviewID.Eq(reply.ReviewID)).First()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, biz.ErrReviewNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 	//判断是否已经回复
 	if review.HasReply == 1 {
-		return nil, errors.New("评价已回复")
+		return nil, biz.ErrReviewReplied
 	}
 	//1.2 水平越权校验 (A商家只能回复自己的，不能回复B商家的评价
 	// 举例子：用户A删除订单，userID + orderID，当条件去查询然后删除
 	if review.StoreID != reply.StoreID {
-		return nil, errors.New("水平越权")
+		return nil, biz.ErrForbidden
 	}
 	//2. 同时更新数据库中的数据 (评价表和评价回复表要同时更新，涉及到事务操作)
-	r.data.query.Transaction(func(tx *query.Query) error {
+	err = r.data.query.Transaction(func(tx *query.Query) error {
 		// 评价表更新hasReply字段
-		if _, err := tx.ReviewInfo.WithContext(ctx).
-			Where(tx.ReviewInfo.ReviewID.Eq(review.ReviewID)).Update(tx.ReviewInfo.HasReply, 1); err != nil {
-			r.log.WithContext(ctx).Errorf("UpdateReview review update fail,err:%v\n")
+		// 上面的检查没有加锁，带上 has_reply=0 条件更新，并发回复时只有一个能更新成功
+		ri := tx.ReviewInfo.Table(table)
+		info, err := ri.WithContext(ctx).
+			Where(ri.ReviewID.Eq(review.ReviewID), ri.HasReply.Eq(0)).Update(ri.HasReply, 1)
+		if err != nil {
+			r.log.WithContext(ctx).Errorf("UpdateReview review update fail,err:%v\n", err)
 			return err
 		}
+		if info.RowsAffected == 0 {
+			return biz.ErrReviewReplied
+		}
 		// 回复表插入一条数据
-		if err := r.data.query.ReviewReplyInfo.WithContext(ctx).Save(reply); err != nil {
+		if err := tx.ReviewReplyInfo.WithContext(ctx).Save(reply); err != nil {
 			r.log.WithContext(ctx).Errorf("SaveReply save reply fail,err:%v\n", err)
 			return err
 		}
-		return nil
+		return addEvent(ctx, tx, biz.EventReplyCreated, review, reply.ReplyID, replyPayload(reply))
 	})
+	if err != nil {
+		return nil, err
+	}
+	r.updateReviewStats(ctx, review.ReviewID, replyStats(review, time.Now()))
 	//3. 返回数据
 	return reply, nil
 }
 
+// GetReplyByReplyID 根据回复ID获取回复
+func (r *reviewRepo) GetReplyByReplyID(ctx context.Context, id int64) (*model.ReviewReplyInfo, error) {
+	reply, err := r.data.query.ReviewReplyInfo.WithContext(ctx).Where(r.data.query.ReviewReplyInfo.ReplyID.Eq(id)).First()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, biz.ErrReplyNotFound
+	}
+	return reply, err
+}
+
+// BatchGetReply 批量查询评价的商家首条回复
+func (r *reviewRepo) BatchGetReply(ctx context.Context, reviewIDs []int64) ([]*model.ReviewReplyInfo, error) {
+	return r.data.query.ReviewReplyInfo.WithContext(ctx).
+		Where(r.data.query.ReviewReplyInfo.ReviewID.In(reviewIDs...),
+			r.data.query.ReviewReplyInfo.ParentID.Eq(0),
+			r.data.query.ReviewReplyInfo.IsDel.Eq(0),
+		).Find()
+}
+
+// BatchGetAppeal 批量查询评价的申诉
+func (r *reviewRepo) BatchGetAppeal(ctx context.Context, reviewIDs []int64) ([]*model.ReviewAppealInfo, error) {
+	return r.data.query.ReviewAppealInfo.WithContext(ctx).
+		Where(r.data.query.ReviewAppealInfo.ReviewID.In(reviewIDs...)).Find()
+}
+
+// SaveThreadReply 在评价的回复对话中追加一条回复
+// 锁住评价记录后再统计楼层，保证并发追加时楼层序号不重复且不超过上限
+func (r *reviewRepo) SaveThreadReply(ctx context.Context, reply *model.ReviewReplyInfo, maxLength int) (*model.ReviewReplyInfo, error) {
+	table, err := r.reviewTable(ctx, reply.ReviewID)
+	if err != nil {
+		return nil, err
+	}
+	err = r.data.query.Transaction(func(tx *query.Query) error {
+		ri := tx.ReviewInfo.Table(table)
+		review, err := ri.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where(ri.ReviewID.Eq(reply.ReviewID)).First()
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return biz.ErrReviewNotFound
+			}
+			return err
+		}
+		// 已删除的回复不占楼层数，但楼层序号继续递增
+		count, err := tx.ReviewReplyInfo.WithContext(ctx).
+			Where(tx.ReviewReplyInfo.ReviewID.Eq(reply.ReviewID), tx.ReviewReplyInfo.IsDel.Eq(0)).Count()
+		if err != nil {
+			return err
+		}
+		if int(count) >= maxLength {
+			return biz.ErrThreadFull
+		}
+		var last struct{ Seq int32 }
+		if err := tx.ReviewReplyInfo.WithContext(ctx).Select(tx.ReviewReplyInfo.Seq.Max().As("seq")).
+			Where(tx.ReviewReplyInfo.ReviewID.Eq(reply.ReviewID)).Scan(&last); err != nil {
+			return err
+		}
+		reply.Seq = last.Seq + 1
+		if err := tx.ReviewReplyInfo.WithContext(ctx).Create(reply); err != nil {
+			return err
+		}
+		return addEvent(ctx, tx, biz.EventThreadReplied, review, reply.ReplyID, replyPayload(reply))
+	})
+	if err != nil {
+		r.log.WithContext(ctx).Errorf("SaveThreadReply fail,err:%v\n", err)
+		return nil, err
+	}
+	return reply, nil
+}
+
+// ListThreadReply 查询评价的回复对话 只返回未删除且审核通过的回复
+func (r *reviewRepo) ListThreadReply(ctx context.Context, reviewID int64) ([]*model.ReviewReplyInfo, error) {
+	return r.data.query.ReviewReplyInfo.WithContext(ctx).
+		Where(r.data.query.ReviewReplyInfo.ReviewID.Eq(reviewID),
+			r.data.query.ReviewReplyInfo.IsDel.Eq(0),
+			r.data.query.ReviewReplyInfo.Status.Eq(biz.ReplyStatusApproved),
+		).
+		Order(r.data.query.ReviewReplyInfo.Seq, r.data.query.ReviewReplyInfo.ID).Find()
+}
+
+// BatchListThreadReply 批量查询评价的回复对话
+func (r *reviewRepo) BatchListThreadReply(ctx context.Context, reviewIDs []int64) ([]*model.ReviewReplyInfo, error) {
+	return r.data.query.ReviewReplyInfo.WithContext(ctx).
+		Where(r.data.query.ReviewReplyInfo.ReviewID.In(reviewIDs...),
+			r.data.query.ReviewReplyInfo.IsDel.Eq(0),
+			r.data.query.ReviewReplyInfo.Status.Eq(biz.ReplyStatusApproved),
+		).
+		Order(r.data.query.ReviewReplyInfo.ReviewID, r.data.query.ReviewReplyInfo.Seq, r.data.query.ReviewReplyInfo.ID).Find()
+}
+
+// DeleteThreadReply 逻辑删除对话中的回复
+func (r *reviewRepo) DeleteThreadReply(ctx context.Context, replyID int64) error {
+	reply, err := r.GetReplyByReplyID(ctx, replyID)
+	if err != nil {
+		return err
+	}
+	table, err := r.reviewTable(ctx, reply.ReviewID)
+	if err != nil {
+		return err
+	}
+	return r.data.query.Transaction(func(tx *query.Query) error {
+		if _, err := tx.ReviewReplyInfo.WithContext(ctx).
+			Where(tx.ReviewReplyInfo.ReplyID.Eq(replyID)).
+			Update(tx.ReviewReplyInfo.IsDel, 1); err != nil {
+			return err
+		}
+		review, err := eventReview(ctx, tx, table, reply.ReviewID)
+		if err != nil {
+			return err
+		}
+		return addEvent(ctx, tx, biz.EventReplyDeleted, review, replyID, map[string]interface{}{"parent_id": reply.ParentID})
+	})
+}
+
+// UpdateReply 修改商家回复
+// 修改历史和回复内容在同一个事务中写入，通过version乐观锁防止并发修改互相覆盖
+func (r *reviewRepo) UpdateReply(ctx context.Context, reply *model.ReviewReplyInfo, history *model.ReviewReplyHistory) error {
+	table, err := r.reviewTable(ctx, reply.ReviewID)
+	if err != nil {
+		return err
+	}
+	return r.data.query.Transaction(func(tx *query.Query) error {
+		if err := tx.ReviewReplyHistory.WithContext(ctx).Create(history); err != nil {
+			return err
+		}
+		info, err := tx.ReviewReplyInfo.WithContext(ctx).
+			Where(tx.ReviewReplyInfo.ReplyID.Eq(reply.ReplyID), tx.ReviewReplyInfo.Version.Eq(history.Version)).
+			Updates(map[string]interface{}{
+				"content":    reply.Content,
+				"pic_info":   reply.PicInfo,
+				"video_info": reply.VideoInfo,
+				"status":     reply.Status,
+				"version":    history.Version + 1,
+			})
+		if err != nil {
+			return err
+		}
+		if info.RowsAffected == 0 {
+			return biz.ErrReplyModified
+		}
+		reply.Version = history.Version + 1
+		review, err := eventReview(ctx, tx, table, reply.ReviewID)
+		if err != nil {
+			return err
+		}
+		return addEvent(ctx, tx, biz.EventReplyUpdated, review, reply.ReplyID, replyPayload(reply))
+	})
+}
+
+// DeleteReply 删除商家回复 (逻辑删除)
+// 首条回复被删除时同时删除整个对话，并重置评价表的has_reply字段
+func (r *reviewRepo) DeleteReply(ctx context.Context, reply *model.ReviewReplyInfo) error {
+	table, err := r.reviewTable(ctx, reply.ReviewID)
+	if err != nil {
+		return err
+	}
+	err = r.data.query.Transaction(func(tx *query.Query) error {
+		if reply.ParentID != 0 {
+			if _, err := tx.ReviewReplyInfo.WithContext(ctx).
+				Where(tx.ReviewReplyInfo.ReplyID.Eq(reply.ReplyID)).
+				Update(tx.ReviewReplyInfo.IsDel, 1); err != nil {
+				return err
+			}
+		} else {
+			if _, err := tx.ReviewReplyInfo.WithContext(ctx).
+				Where(tx.ReviewReplyInfo.ReviewID.Eq(reply.ReviewID)).
+				Update(tx.ReviewReplyInfo.IsDel, 1); err != nil {
+				return err
+			}
+			ri := tx.ReviewInfo.Table(table)
+			if _, err := ri.WithContext(ctx).
+				Where(ri.ReviewID.Eq(reply.ReviewID)).
+				Update(ri.HasReply, 0); err != nil {
+				return err
+			}
+		}
+		review, err := eventReview(ctx, tx, table, reply.ReviewID)
+		if err != nil {
+			return err
+		}
+		return addEvent(ctx, tx, biz.EventReplyDeleted, review, reply.ReplyID, map[string]interface{}{"parent_id": reply.ParentID})
+	})
+	if err != nil {
+		return err
+	}
+	if reply.ParentID == 0 {
+		r.updateReviewStats(ctx, reply.ReviewID, map[string]interface{}{"reply_at": nil, "reply_seconds": nil})
+	}
+	return nil
+}
+
 // AppealReview
 func (r *reviewRepo) AppealReview(ctx context.Context, param *biz.AppealReviewParam) (*model.ReviewAppealInfo, error) {
+	// 0. 水平越权校验 商家只能申诉自己店铺的评价
+	table, err := r.reviewTable(ctx, param.ReviewID)
+	if err != nil {
+		return nil, err
+	}
+	ri := r.data.query.ReviewInfo.Table(table)
+	review, err := ri.WithContext(ctx).Where(ri.ReviewID.Eq(param.ReviewID)).First()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, biz.ErrReviewNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if review.StoreID != param.StoreID {
+		return nil, biz.ErrForbidden
+	}
 	// 1. 先查询有没有申诉
 	ret, err := r.data.query.ReviewAppealInfo.WithContext(ctx).
 		Where(r.data.query.ReviewAppealInfo.ReviewID.Eq(param.ReviewID),
 			r.data.query.ReviewAppealInfo.StoreID.Eq(param.StoreID),
 		).First()
-	r.log.Debugf("AppealReview Query,ret:%v,err:%v\n", ret, err)
+	r.log.WithContext(ctx).Debugw("msg", "AppealReview query", "found", ret != nil, "err", err)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		// 其它查询错误
 		return nil, err
 	}
 	if err == nil && ret.Status > 10 {
-		return nil, errors.New("该评价已有审核过的申诉记录")
+		return nil, biz.ErrAppealAudited
 	}
 	// 查询不到审核过的申诉记录
 	// 1.有申诉记录但是处于待审核状态，需要更新
@@ -133,40 +439,155 @@ func (r *reviewRepo) AppealReview(ctx context.Context, param *biz.AppealReviewPa
 		appeal.AppealID = ret.AppealID
 	} else {
 		// 没有查到申诉记录，ret==nil 通过雪花算法生成AppealID
-		appeal.AppealID = snowflake.GenID()
+		if appeal.AppealID, err = r.data.idgen.NextID(); err != nil {
+			return nil, err
+		}
 	}
-	err = r.data.query.ReviewAppealInfo.WithContext(ctx).Clauses(clause.OnConflict{
-		Columns: []clause.Column{
-			{Name: "review_id"}, // ON DUPLICATE KEY
+	err = r.data.query.Transaction(func(tx *query.Query) error {
+		err := tx.ReviewAppealInfo.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns: []clause.Column{
+				{Name: "review_id"}, // ON DUPLICATE KEY
+			},
+			DoUpdates: clause.Assignments(map[string]interface{}{ // UPDATE
+				"status":     appeal.Status,
+				"content":    appeal.Content,
+				"reason":     appeal.Reason,
+				"pic_info":   appeal.PicInfo,
+				"video_info": appeal.VideoInfo,
+			}),
 		},
-		DoUpdates: clause.Assignments(map[string]interface{}{ // UPDATE
-			"status":     appeal.Status,
-			"content":    appeal.Content,
-			"reason":     appeal.Reason,
-			"pic_info":   appeal.PicInfo,
-			"video_info": appeal.VideoInfo,
-		}),
-	},
-	).Create(appeal) // INSERT
-	r.log.Debugf("AppealReview,err:%v\n", err)
+		).Create(appeal) // INSERT
+		if err != nil {
+			return err
+		}
+		review, err := eventReview(ctx, tx, table, param.ReviewID)
+		if err != nil {
+			return err
+		}
+		return addEvent(ctx, tx, biz.EventAppealCreated, review, appeal.AppealID, map[string]interface{}{"reason": appeal.Reason})
+	})
+	r.log.WithContext(ctx).Debugw("msg", "AppealReview", "appeal_id", appeal.AppealID, "err", err)
 	return appeal, err
 }
 
 // AuditReview 审核用户评价 (运营对用户的评价进行审核)
+// 审核的同时处理掉该评价下待处理的举报
 func (r *reviewRepo) AuditReview(ctx context.Context, param *biz.AuditReviewParam) error {
-	_, err := r.data.query.ReviewInfo.WithContext(ctx).Where(r.data.query.ReviewInfo.ReviewID.Eq(param.ReviewID)).
-		Updates(map[string]interface{}{
-			"status":     param.Status,
-			"op_user":    param.OpUser,
-			"op_reason":  param.OpReason,
-			"op_remarks": param.OpRemarks,
+	table, err := r.reviewTable(ctx, param.ReviewID)
+	if err != nil {
+		return err
+	}
+	err = r.data.query.Transaction(func(tx *query.Query) error {
+		ri := tx.ReviewInfo.Table(table)
+		if _, err := ri.WithContext(ctx).Where(ri.ReviewID.Eq(param.ReviewID)).
+			Updates(map[string]interface{}{
+				"status":     param.Status,
+				"op_user":    param.OpUser,
+				"op_reason":  param.OpReason,
+				"op_remarks": param.OpRemarks,
+			}); err != nil {
+			return err
+		}
+		if _, err := tx.ReviewReportInfo.WithContext(ctx).
+			Where(tx.ReviewReportInfo.ReviewID.Eq(param.ReviewID), tx.ReviewReportInfo.Status.Eq(10)).
+			Updates(map[string]interface{}{
+				"status":  20,
+				"op_user": param.OpUser,
+			}); err != nil {
+			return err
+		}
+		review, err := eventReview(ctx, tx, table, param.ReviewID)
+		if err != nil {
+			return err
+		}
+		return addEvent(ctx, tx, biz.EventReviewAudited, review, 0, map[string]interface{}{
+			"status":    param.Status,
+			"op_reason": param.OpReason,
 		})
-	return err
+	})
+	if err != nil {
+		return err
+	}
+	r.updateReviewStats(ctx, param.ReviewID, map[string]interface{}{"status": param.Status})
+	return nil
+}
+
+// SaveReport 保存用户的举报
+// 待处理的举报数达到阈值时隐藏评价，等运营审核后再决定是否恢复
+func (r *reviewRepo) SaveReport(ctx context.Context, report *model.ReviewReportInfo, threshold int) (*model.ReviewReportInfo, error) {
+	table, err := r.reviewTable(ctx, report.ReviewID)
+	if err != nil {
+		return nil, err
+	}
+	var hidden bool
+	err = r.data.query.Transaction(func(tx *query.Query) error {
+		// 同一用户对同一评价只能举报一次
+		n, err := tx.ReviewReportInfo.WithContext(ctx).
+			Where(tx.ReviewReportInfo.ReviewID.Eq(report.ReviewID), tx.ReviewReportInfo.UserID.Eq(report.UserID)).Count()
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			return biz.ErrReviewReported
+		}
+		if err := tx.ReviewReportInfo.WithContext(ctx).Create(report); err != nil {
+			return err
+		}
+		pending, err := tx.ReviewReportInfo.WithContext(ctx).
+			Where(tx.ReviewReportInfo.ReviewID.Eq(report.ReviewID), tx.ReviewReportInfo.Status.Eq(10)).Count()
+		if err != nil {
+			return err
+		}
+		if int(pending) < threshold {
+			return nil
+		}
+		// 评价表 状态改为隐藏
+		ri := tx.ReviewInfo.Table(table)
+		info, err := ri.WithContext(ctx).
+			Where(ri.ReviewID.Eq(report.ReviewID), ri.Status.Neq(40)).
+			Updates(map[string]interface{}{
+				"status":    40,
+				"op_user":   "system",
+				"op_reason": "举报数达到阈值自动隐藏",
+			})
+		if err != nil {
+			return err
+		}
+		if hidden = info.RowsAffected > 0; !hidden {
+			return nil
+		}
+		review, err := eventReview(ctx, tx, table, report.ReviewID)
+		if err != nil {
+			return err
+		}
+		return addEvent(ctx, tx, biz.EventReviewHidden, review, report.ReportID, map[string]interface{}{"status": 40})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if hidden {
+		r.updateReviewStats(ctx, report.ReviewID, map[string]interface{}{"status": 40})
+	}
+	return report, nil
+}
+
+// ListPendingReports 待处理的举报 按举报时间从早到晚
+func (r *reviewRepo) ListPendingReports(ctx context.Context, reviewID int64, offset, limit int) ([]*model.ReviewReportInfo, error) {
+	rr := r.data.query.ReviewReportInfo
+	q := rr.WithContext(ctx).Where(rr.Status.Eq(10))
+	if reviewID > 0 {
+		q = q.Where(rr.ReviewID.Eq(reviewID))
+	}
+	return q.Order(rr.ID).Offset(offset).Limit(limit).Find()
 }
 
 // AuditAppeal 审核商家申诉 (运营对商家的申诉进行审核 ,审核通过会隐藏该评价)
 func (r *reviewRepo) AuditAppeal(ctx context.Context, param *biz.AuditAppealParam) error {
-	err := r.data.query.Transaction(func(tx *query.Query) error {
+	table, err := r.reviewTable(ctx, param.ReviewID)
+	if err != nil {
+		return err
+	}
+	err = r.data.query.Transaction(func(tx *query.Query) error {
 		// 申诉表
 		if _, err := tx.ReviewAppealInfo.WithContext(ctx).Where(tx.ReviewAppealInfo.AppealID.Eq(param.AppealID)).
 			Updates(map[string]interface{}{
@@ -178,19 +599,30 @@ func (r *reviewRepo) AuditAppeal(ctx context.Context, param *biz.AuditAppealPara
 		// 评价表
 		// 申诉通过需要隐藏评价
 		if param.Status == 20 {
-			if _, err := tx.ReviewInfo.WithContext(ctx).Where(tx.ReviewInfo.ReviewID.Eq(param.ReviewID)).
-				Update(tx.ReviewInfo.Status, 40); err != nil {
+			ri := tx.ReviewInfo.Table(table)
+			if _, err := ri.WithContext(ctx).Where(ri.ReviewID.Eq(param.ReviewID)).
+				Update(ri.Status, 40); err != nil {
 				return err
 			}
 		}
-		return nil
+		review, err := eventReview(ctx, tx, table, param.ReviewID)
+		if err != nil {
+			return err
+		}
+		return addEvent(ctx, tx, biz.EventAppealAudited, review, param.AppealID, map[string]interface{}{"status": param.Status})
 	})
-	return err
+	if err != nil {
+		return err
+	}
+	if param.Status == 20 {
+		r.updateReviewStats(ctx, param.ReviewID, map[string]interface{}{"status": 40})
+	}
+	return nil
 }
 
-func (r *reviewRepo) ListReviewByStoreID(ctx context.Context, storeID int64, offset, limit int) ([]*biz.MyReviewInfo, error) {
+func (r *reviewRepo) ListReviewByStoreID(ctx context.Context, storeID int64, offset, limit int, sort, sentiment string) ([]*biz.MyReviewInfo, error) {
 	// return r.getData1(ctx,storeID,offset,limit) // 第一版 直接查es
-	return r.getData2(ctx, storeID, offset, limit) // 第二版 增加缓存和singleflight
+	return r.getData2(ctx, storeID, offset, limit, sort, sentiment) // 第二版 增加缓存和singleflight
 }
 
 func (r *reviewRepo) getData1(ctx context.Context, storeID int64, offset, limit int) ([]*biz.MyReviewInfo, error) {
@@ -207,11 +639,10 @@ func (r *reviewRepo) getData1(ctx context.Context, storeID int64, offset, limit
 				},
 			},
 		}).Do(ctx)
-	fmt.Printf("--> es search: %v %v\n", resp, err)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Printf("es result total:%v\n", resp.Hits.Total.Value)
+	r.log.WithContext(ctx).Debugw("msg", "es search result", "total", resp.Hits.Total.Value)
 	// 反序列化数据
 	list := make([]*biz.MyReviewInfo, 0, resp.Hits.Total.Value)
 	for _, hit := range resp.Hits.Hits {
@@ -227,12 +658,12 @@ func (r *reviewRepo) getData1(ctx context.Context, storeID int64, offset, limit
 }
 
 // getData2 升级版 带缓存版本的查询函数
-func (r *reviewRepo) getData2(ctx context.Context, storeID int64, offset, limit int) ([]*biz.MyReviewInfo, error) {
+func (r *reviewRepo) getData2(ctx context.Context, storeID int64, offset, limit int, sort, sentiment string) ([]*biz.MyReviewInfo, error) {
 	// 取数据
 	// 1.先查询Redis缓存
 	// 2.缓存没有则查询 ES
 	// 3.通过singleflight 合并短时间内大量的并发请求
-	key := fmt.Sprintf("review:%d:%d:%d", storeID, offset, limit)
+	key := fmt.Sprintf("review:%d:%d:%d:%s:%s", storeID, offset, limit, sort, sentiment)
 	b, err := r.getDataBySingleflight(ctx, key)
 	if err != nil {
 		return nil, err
@@ -257,30 +688,35 @@ func (r *reviewRepo) getData2(ctx context.Context, storeID int64, offset, limit
 
 var g singleflight.Group
 
-// key review:76089:1:10 --> "[{},{},{}]"
+// key review:76089:1:10:: --> "[{},{},{}]"
 // josn.Unmarshal([]byte)
 // getDataBySingleflight
 func (r *reviewRepo) getDataBySingleflight(ctx context.Context, key string) ([]byte, error) {
 	v, err, shared := g.Do(key, func() (interface{}, error) {
 		// 查缓存
 		data, err := r.getDataFromCache(ctx, key)
-		r.log.Debugf("r.getDataFromCache(ctx,key) data:%s,err:%v\n", data, err)
+		r.log.WithContext(ctx).Debugw("msg", "getDataFromCache result", "key", key, "size", len(data), "err", err)
 		if err == nil {
+			metrics.CacheRequests.WithLabelValues("hit").Inc()
 			return data, nil
 		}
 		// 只有返回缓存中没有这个key的错误时 才查询es
 		if errors.Is(err, redis.Nil) {
+			metrics.CacheRequests.WithLabelValues("miss").Inc()
 			// 缓存中没有这个key，说明缓存失效了，要查询es
 			data, err := r.getDataFromES(ctx, key)
 			if err == nil {
 				// 设置缓存
 				return data, r.setCache(ctx, key, data)
 			}
+			return nil, err
 		}
 		// 查询缓存失败了，直接返回错误，不继续向下传导压力
+		metrics.CacheRequests.WithLabelValues("error").Inc()
 		return nil, err
 	})
-	r.log.Debugf("singleflight result: v:%v err:%v shared:%v\n", v, err, shared)
+	r.log.WithContext(ctx).Debugw("msg", "singleflight result", "key", key, "shared", shared, "err", err)
+	metrics.SingleflightRequests.WithLabelValues(strconv.FormatBool(shared)).Inc()
 	if err != nil {
 		return nil, err
 	}
@@ -289,23 +725,23 @@ func (r *reviewRepo) getDataBySingleflight(ctx context.Context, key string) ([]b
 
 // getDataFromCache 读缓存
 func (r *reviewRepo) getDataFromCache(ctx context.Context, key string) ([]byte, error) {
-	r.log.Debugf("getDataFromCache key:%v\n", key)
+	r.log.WithContext(ctx).Debugw("msg", "getDataFromCache", "key", key)
 	return r.data.rdb.Get(ctx, key).Bytes()
 }
 
 // setCache 设置缓存
 func (r *reviewRepo) setCache(ctx context.Context, key string, data []byte) error {
-	r.log.Debugf("setCahce key:%v\t,data:%s\n", key, data)
-	return r.data.rdb.Set(ctx, key, data, time.Second*60).Err()
+	r.log.WithContext(ctx).Debugw("msg", "setCache", "key", key, "size", len(data))
+	return r.data.rdb.Set(ctx, key, data, r.runtime.Load().ListCacheTTL).Err()
 }
 
 // getDataFromES 从es中查询
 func (r *reviewRepo) getDataFromES(ctx context.Context, key string) ([]byte, error) {
 	values := strings.Split(key, ":")
-	if len(values) < 4 {
+	if len(values) < 6 {
 		return nil, errors.New("invalid key")
 	}
-	index, storeID, offsetStr, limitStr := values[0], values[1], values[2], values[3]
+	index, storeID, offsetStr, limitStr, sort, sentiment := values[0], values[1], values[2], values[3], values[4], values[5]
 	offset, err := strconv.Atoi(offsetStr)
 	if err != nil {
 		return nil, err
@@ -314,7 +750,14 @@ func (r *reviewRepo) getDataFromES(ctx context.Context, key string) ([]byte, err
 	if err != nil {
 		return nil, err
 	}
-	resp, err := r.data.es.Search().Index(index).From(offset).Size(limit).
+	if sentiment != "" {
+		id, err := strconv.ParseInt(storeID, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return r.searchBySentiment(ctx, id, sentiment, offset, limit)
+	}
+	req := r.data.es.Search().Index(index).From(offset).Size(limit).
 		Query(&types.Query{
 			Bool: &types.BoolQuery{
 				Filter: []types.Query{
@@ -325,7 +768,15 @@ func (r *reviewRepo) getDataFromES(ctx context.Context, key string) ([]byte, err
 					},
 				},
 			},
-		}).Do(ctx)
+		})
+	if sort == biz.SortHelpful {
+		// 有用数相同时按创建时间倒序
+		req = req.Sort(
+			types.SortOptions{SortOptions: map[string]types.FieldSort{"helpful_count": {Order: &sortorder.Desc}}},
+			types.SortOptions{SortOptions: map[string]types.FieldSort{"create_at": {Order: &sortorder.Desc}}},
+		)
+	}
+	resp, err := req.Do(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,212 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"review-service/internal/conf"
+	"review-service/internal/data/model"
+	"review-service/pkg/snowflake"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
+)
+
+// 评价表分表
+// 分片键(user_id 或 store_id)对槽位数取模得到槽位，槽位对分表数取模得到分表 review_info_{nn}
+// 创建评价时把槽位编码在review_id的低位，按评价ID查询时不需要额外查询就能定位分表
+// B端按店铺查询评价走ES，不依赖分表；不分表时所有评价都在review_info表中
+// 调整分表数时槽位不变，只需要迁移槽位所在分表发生变化的数据，见 Rebalance
+// 启用分表前创建的评价ID中没有槽位，迁移时把槽位写入 review_slot_info，按评价ID查询时从中读取
+
+// 分片键
+const (
+	ShardKeyUserID  = "user_id"
+	ShardKeyStoreID = "store_id"
+)
+
+type sharding struct {
+	tables      int64
+	key         string
+	legacyMaxID int64 // 启用分表前的评价ID中没有槽位
+}
+
+func newSharding(cfg *conf.Data_Sharding) *sharding {
+	s := &sharding{
+		tables:      int64(cfg.GetTables()),
+		key:         cfg.GetKey(),
+		legacyMaxID: cfg.GetLegacyMaxId(),
+	}
+	if s.key == "" {
+		s.key = ShardKeyUserID
+	}
+	return s
+}
+
+func (s *sharding) enabled() bool {
+	return s.tables > 1
+}
+
+// table 分片键的值所在的分表
+func (s *sharding) table(value int64) string {
+	return ShardTable(value%snowflake.SlotCount, s.tables)
+}
+
+// ReviewSlot 评价的槽位 由分片键决定
+func ReviewSlot(review *model.ReviewInfo, key string) int64 {
+	if key == ShardKeyStoreID {
+		return review.StoreID % snowflake.SlotCount
+	}
+	return review.UserID % snowflake.SlotCount
+}
+
+// ShardTable 槽位所在的分表 分表数不大于1时为review_info
+func ShardTable(slot, tables int64) string {
+	if tables <= 1 {
+		return model.TableNameReviewInfo
+	}
+	return fmt.Sprintf("%s_%02d", model.TableNameReviewInfo, slot%tables)
+}
+
+// ShardTables 所有分表
+func ShardTables(tables int64) []string {
+	if tables <= 1 {
+		return []string{model.TableNameReviewInfo}
+	}
+	ret := make([]string, 0, tables)
+	for i := int64(0); i < tables; i++ {
+		ret = append(ret, ShardTable(i, tables))
+	}
+	return ret
+}
+
+// Rebalance 调整分表数时迁移评价数据 from/to 为调整前后的分表数 1表示不分表
+// 迁移期间需要停止写入评价，迁移完成后修改配置中的分表数再启动服务
+// 从不分表迁移时返回review_info表中最大的review_id，需要配置到 legacy_max_id，并记录每条评价的槽位
+func Rebalance(ctx context.Context, db *gorm.DB, key string, from, to int64, batch int, logger log.Logger) (int64, error) {
+	helper := log.NewHelper(logger)
+	for _, table := range ShardTables(to) {
+		if err := createShardTable(ctx, db, table); err != nil {
+			return 0, err
+		}
+	}
+	var legacyMaxID int64
+	if from <= 1 {
+		if err := db.WithContext(ctx).Clauses(dbresolver.Write).Table(model.TableNameReviewInfo).
+			Select("COALESCE(MAX(review_id), 0)").Scan(&legacyMaxID).Error; err != nil {
+			return 0, err
+		}
+	}
+	for _, src := range ShardTables(from) {
+		moved, err := rebalanceTable(ctx, db, src, key, to, batch)
+		helper.WithContext(ctx).Infow("msg", "rebalance table", "table", src, "moved", moved, "err", err)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return legacyMaxID, nil
+}
+
+// createShardTable 按review_info的表结构创建分表
+func createShardTable(ctx context.Context, db *gorm.DB, table string) error {
+	if table == model.TableNameReviewInfo {
+		return nil
+	}
+	// mysql 连同索引一起复制，其它数据库按模型建表
+	if db.Dialector.Name() == "mysql" {
+		return db.WithContext(ctx).Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS `%s` LIKE `%s`", table, model.TableNameReviewInfo)).Error
+	}
+	return db.WithContext(ctx).Table(table).AutoMigrate(&model.ReviewInfo{})
+}
+
+// rebalanceTable 把分表中不属于该分表的数据迁到目标分表 返回迁移的条数
+// 自增主键在各分表中独立，迁移时由目标分表重新生成
+// 从review_info迁出的是启用分表前的评价，在同一个事务中记录它们的槽位
+func rebalanceTable(ctx context.Context, db *gorm.DB, src, key string, to int64, batch int) (int, error) {
+	var (
+		lastID int64
+		moved  int
+	)
+	for {
+		var rows []*model.ReviewInfo
+		if err := db.WithContext(ctx).Clauses(dbresolver.Write).Table(src).
+			Where("id > ?", lastID).Order("id").Limit(batch).Find(&rows).Error; err != nil {
+			return moved, err
+		}
+		if len(rows) == 0 {
+			return moved, nil
+		}
+		lastID = rows[len(rows)-1].ID
+		targets := make(map[string][]*model.ReviewInfo)
+		for _, row := range rows {
+			if dst := ShardTable(ReviewSlot(row, key), to); dst != src {
+				targets[dst] = append(targets[dst], row)
+			}
+		}
+		err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			for dst, list := range targets {
+				ids := make([]int64, 0, len(list))
+				for _, row := range list {
+					ids = append(ids, row.ID)
+				}
+				if err := tx.Table(dst).Omit("id").Create(list).Error; err != nil {
+					return err
+				}
+				if err := tx.Table(src).Where("id IN ?", ids).Delete(&model.ReviewInfo{}).Error; err != nil {
+					return err
+				}
+				if src == model.TableNameReviewInfo {
+					if err := saveReviewSlots(tx, list, key); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return moved, err
+		}
+		for _, list := range targets {
+			moved += len(list)
+		}
+	}
+}
+
+// saveReviewSlots 记录启用分表前创建的评价的槽位 已经记录过的跳过
+func saveReviewSlots(tx *gorm.DB, list []*model.ReviewInfo, key string) error {
+	slots := make([]*model.ReviewSlotInfo, 0, len(list))
+	for _, row := range list {
+		slots = append(slots, &model.ReviewSlotInfo{ReviewID: row.ReviewID, Slot: int32(ReviewSlot(row, key))})
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(slots).Error
+}
+
+// BackfillReviewSlots 为已经迁到分表、还没有记录槽位的旧评价补写槽位 返回处理的条数
+// 用于升级前已经完成分表迁移的部署，重复执行是安全的
+func BackfillReviewSlots(ctx context.Context, db *gorm.DB, key string, tables, legacyMaxID int64, batch int, logger log.Logger) (int, error) {
+	helper := log.NewHelper(logger)
+	var total int
+	for _, table := range ShardTables(tables) {
+		var lastID int64
+		for {
+			var rows []*model.ReviewInfo
+			if err := db.WithContext(ctx).Clauses(dbresolver.Write).Table(table).
+				Select("id", "review_id", "user_id", "store_id").
+				Where("id > ? AND review_id <= ?", lastID, legacyMaxID).Order("id").Limit(batch).Find(&rows).Error; err != nil {
+				return total, err
+			}
+			if len(rows) == 0 {
+				break
+			}
+			lastID = rows[len(rows)-1].ID
+			if err := saveReviewSlots(db.WithContext(ctx), rows, key); err != nil {
+				return total, err
+			}
+			total += len(rows)
+		}
+		helper.WithContext(ctx).Infow("msg", "backfill review slots", "table", table, "total", total)
+	}
+	return total, nil
+}
@@ -0,0 +1,178 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"review-service/internal/biz"
+	"review-service/internal/data/migrate"
+	"review-service/internal/data/model"
+	"review-service/internal/data/query"
+	"review-service/pkg/snowflake"
+
+	"github.com/glebarez/sqlite"
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+)
+
+// newTestDB 建好最新表结构的sqlite库
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "review.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	m, err := migrate.New(db, log.DefaultLogger)
+	if err != nil {
+		t.Fatalf("migrate.New() error = %v", err)
+	}
+	if err := m.Up(context.Background(), 0); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+	return db
+}
+
+func TestShardTable(t *testing.T) {
+	tests := []struct {
+		slot, tables int64
+		want         string
+	}{
+		{slot: 5, tables: 0, want: "review_info"},
+		{slot: 5, tables: 1, want: "review_info"},
+		{slot: 5, tables: 4, want: "review_info_01"},
+		{slot: 63, tables: 16, want: "review_info_15"},
+		{slot: 0, tables: 64, want: "review_info_00"},
+	}
+	for _, tt := range tests {
+		if got := ShardTable(tt.slot, tt.tables); got != tt.want {
+			t.Errorf("ShardTable(%d, %d) = %s, want %s", tt.slot, tt.tables, got, tt.want)
+		}
+	}
+	if got := ShardTables(1); len(got) != 1 || got[0] != model.TableNameReviewInfo {
+		t.Errorf("ShardTables(1) = %v", got)
+	}
+	if got := ShardTables(4); len(got) != 4 || got[3] != "review_info_03" {
+		t.Errorf("ShardTables(4) = %v", got)
+	}
+}
+
+func TestReviewSlot(t *testing.T) {
+	review := &model.ReviewInfo{UserID: 130, StoreID: 67}
+	tests := []struct {
+		key  string
+		want int64
+	}{
+		{key: ShardKeyUserID, want: 130 % snowflake.SlotCount},
+		{key: ShardKeyStoreID, want: 67 % snowflake.SlotCount},
+		// 未配置时按用户分片
+		{key: "", want: 130 % snowflake.SlotCount},
+	}
+	for _, tt := range tests {
+		if got := ReviewSlot(review, tt.key); got != tt.want {
+			t.Errorf("ReviewSlot(%q) = %d, want %d", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestRebalance(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	userIDs := []int64{1, 2, 3, 4, 5, 64, 65, 130, 1000, 1001}
+	for i, userID := range userIDs {
+		if err := db.Create(&model.ReviewInfo{ReviewID: int64(i + 1), UserID: userID, StoreID: 9, OrderID: int64(i + 1), Content: "好"}).Error; err != nil {
+			t.Fatalf("create review: %v", err)
+		}
+	}
+	legacyMaxID := int64(len(userIDs))
+
+	steps := []struct {
+		name     string
+		from, to int64
+	}{
+		{name: "split review_info into 4", from: 1, to: 4},
+		{name: "shrink 4 to 2", from: 4, to: 2},
+		{name: "grow 2 to 8", from: 2, to: 8},
+	}
+	for _, step := range steps {
+		got, err := Rebalance(ctx, db, ShardKeyUserID, step.from, step.to, 3, log.DefaultLogger)
+		if err != nil {
+			t.Fatalf("%s: Rebalance() error = %v", step.name, err)
+		}
+		// 只有从不分表迁移时返回旧评价的最大ID
+		var want int64
+		if step.from <= 1 {
+			want = legacyMaxID
+		}
+		if got != want {
+			t.Fatalf("%s: legacyMaxID = %d, want %d", step.name, got, want)
+		}
+		repo := &reviewRepo{data: &Data{
+			query:    query.Use(db),
+			sharding: &sharding{tables: step.to, key: ShardKeyUserID, legacyMaxID: legacyMaxID},
+		}}
+		var total int64
+		for _, table := range ShardTables(step.to) {
+			var rows []*model.ReviewInfo
+			if err := db.Table(table).Find(&rows).Error; err != nil {
+				t.Fatalf("%s: find %s: %v", step.name, table, err)
+			}
+			for _, row := range rows {
+				if want := ShardTable(row.UserID%snowflake.SlotCount, step.to); want != table {
+					t.Errorf("%s: review %d of user %d in %s, want %s", step.name, row.ReviewID, row.UserID, table, want)
+				}
+				// 旧评价的ID中没有槽位 通过 review_slot_info 定位
+				if got, err := repo.reviewTable(ctx, row.ReviewID); err != nil || got != table {
+					t.Errorf("%s: reviewTable(%d) = %s, %v, want %s", step.name, row.ReviewID, got, err, table)
+				}
+			}
+			total += int64(len(rows))
+		}
+		if total != legacyMaxID {
+			t.Fatalf("%s: %d reviews after rebalance, want %d", step.name, total, legacyMaxID)
+		}
+	}
+	var slots int64
+	db.Model(&model.ReviewSlotInfo{}).Count(&slots)
+	if slots != legacyMaxID {
+		t.Fatalf("review_slot_info has %d rows, want %d", slots, legacyMaxID)
+	}
+}
+
+func TestReviewTable(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	if err := db.Create(&model.ReviewSlotInfo{ReviewID: 7, Slot: 5}).Error; err != nil {
+		t.Fatalf("create slot: %v", err)
+	}
+	// 启用分表后生成的ID低位是槽位
+	slotID := int64(1)<<40 | 3<<snowflake.SlotBits | 13
+	tests := []struct {
+		name     string
+		tables   int64
+		reviewID int64
+		want     string
+		wantErr  error
+	}{
+		{name: "not sharded", tables: 1, reviewID: slotID, want: "review_info"},
+		{name: "slot in id", tables: 4, reviewID: slotID, want: "review_info_01"},
+		{name: "legacy id", tables: 4, reviewID: 7, want: "review_info_01"},
+		{name: "legacy id without slot", tables: 4, reviewID: 8, wantErr: biz.ErrReviewNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &reviewRepo{data: &Data{
+				query:    query.Use(db),
+				sharding: &sharding{tables: tt.tables, key: ShardKeyUserID, legacyMaxID: 100},
+			}}
+			got, err := repo.reviewTable(ctx, tt.reviewID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("reviewTable() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("reviewTable() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
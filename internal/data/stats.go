@@ -0,0 +1,217 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"review-service/internal/biz"
+	"review-service/internal/data/model"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/search"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/some"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+)
+
+// 评分统计
+// review 索引中的评价文档由同步任务按 review_info 整行写入，写入时会覆盖文档中的其它字段，
+// 所以统计需要的类目、维度评分和商家首次回复的时间单独写入 review_stats 索引，文档ID为review_id
+// 评价状态变化、商家回复和删除回复时同步更新文档
+// {"review_id":1,"store_id":1,"category_id":1,"status":10,"score":5,"service_score":5,"express_score":5,
+//  "scores":{"taste":5},"create_at":"2023-12-17 20:03:54","reply_at":"2023-12-17 21:03:54","reply_seconds":3600,
+//  "sentiment":"negative","sentiment_score":-0.6,"keywords":["送餐","慢"]}
+// 情感和关键词在内容分析后写入，使用ES的动态映射(text + keyword子字段)，筛选和聚合时使用keyword子字段
+// 审核不通过(30)和隐藏(40)的评价不参与统计
+
+const statsIndex = "review_stats"
+
+// maxStoreCategories 统计店铺的类目时最多返回的类目数
+const maxStoreCategories = 100
+
+type statsDoc struct {
+	ReviewID     int64            `json:"review_id"`
+	StoreID      int64            `json:"store_id"`
+	CategoryID   int64            `json:"category_id"`
+	Status       int32            `json:"status"`
+	Score        int32            `json:"score"`
+	ServiceScore int32            `json:"service_score"`
+	ExpressScore int32            `json:"express_score"`
+	Scores       map[string]int32 `json:"scores"`
+	CreateAt     string           `json:"create_at"`
+	ReplyAt      *string          `json:"reply_at"`
+	ReplySeconds *int64           `json:"reply_seconds"`
+	// 导入的评价写入前已经分析过内容
+	Sentiment      string   `json:"sentiment,omitempty"`
+	SentimentScore float64  `json:"sentiment_score,omitempty"`
+	Keywords       []string `json:"keywords,omitempty"`
+}
+
+// indexReviewStats 创建评价后写入统计文档 写入失败只记录日志，不影响评价的创建
+func (r *reviewRepo) indexReviewStats(ctx context.Context, review *model.ReviewInfo) {
+	ext := biz.ParseReviewExt(review)
+	createAt := review.CreateAt
+	if createAt.IsZero() {
+		createAt = time.Now()
+	}
+	doc := &statsDoc{
+		ReviewID:     review.ReviewID,
+		StoreID:      review.StoreID,
+		CategoryID:   ext.CategoryID,
+		Status:       review.Status,
+		Score:        review.Score,
+		ServiceScore: review.ServiceScore,
+		ExpressScore: review.ExpressScore,
+		Scores:       ext.DimensionScores,
+		CreateAt:     createAt.Format(time.DateTime),
+
+		Sentiment:      ext.Sentiment,
+		SentimentScore: ext.SentimentScore,
+		Keywords:       ext.Keywords,
+	}
+	if _, err := r.data.es.Index(statsIndex).Id(strconv.FormatInt(review.ReviewID, 10)).
+		Document(doc).Do(ctx); err != nil {
+		r.log.WithContext(ctx).Errorw("msg", "index review stats failed", "review_id", review.ReviewID, "err", err)
+	}
+}
+
+// updateReviewStats 更新统计文档中的部分字段 功能上线前创建的评价没有统计文档，忽略
+func (r *reviewRepo) updateReviewStats(ctx context.Context, reviewID int64, fields map[string]interface{}) {
+	_, err := r.data.es.Update(statsIndex, strconv.FormatInt(reviewID, 10)).
+		Doc(fields).Do(ctx)
+	if err != nil && !isESNotFound(err) {
+		r.log.WithContext(ctx).Errorw("msg", "update review stats failed", "review_id", reviewID, "err", err)
+	}
+}
+
+// replyStats 商家首次回复的时间和回复耗时
+func replyStats(review *model.ReviewInfo, replyAt time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"reply_at":      replyAt.Format(time.DateTime),
+		"reply_seconds": int64(replyAt.Sub(review.CreateAt).Seconds()),
+	}
+}
+
+// statsFilter 统计的过滤条件
+func statsFilter(storeID, categoryID int64) *types.Query {
+	filter := []types.Query{
+		{Term: map[string]types.TermQuery{"store_id": {Value: storeID}}},
+	}
+	if categoryID > 0 {
+		filter = append(filter, types.Query{Term: map[string]types.TermQuery{"category_id": {Value: categoryID}}})
+	}
+	return &types.Query{
+		Bool: &types.BoolQuery{
+			Filter: filter,
+			MustNot: []types.Query{
+				{Terms: &types.TermsQuery{TermsQuery: map[string]types.TermsQueryField{"status": []int32{30, 40}}}},
+			},
+		},
+	}
+}
+
+// ListStoreCategories 店铺有评价的类目
+func (r *reviewRepo) ListStoreCategories(ctx context.Context, storeID int64) ([]int64, error) {
+	resp, err := r.data.es.Search().Index(statsIndex).Size(0).TypedKeys(true).
+		Query(statsFilter(storeID, 0)).
+		Aggregations(map[string]types.Aggregations{
+			"categories": {Terms: &types.TermsAggregation{Field: some.String("category_id"), Size: some.Int(maxStoreCategories)}},
+		}).Do(ctx)
+	if isESNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	agg, ok := resp.Aggregations["categories"].(*types.LongTermsAggregate)
+	if !ok {
+		return nil, nil
+	}
+	buckets, _ := agg.Buckets.([]types.LongTermsBucket)
+	ret := make([]int64, 0, len(buckets))
+	for _, b := range buckets {
+		// 0表示评价没有类目
+		if b.Key > 0 {
+			ret = append(ret, b.Key)
+		}
+	}
+	return ret, nil
+}
+
+// GetReviewStats 店铺的评分统计
+// 不限类目时总分从 review 索引统计，限定类目时只能从 review_dimension 索引统计
+func (r *reviewRepo) GetReviewStats(ctx context.Context, storeID, categoryID int64, codes []string) (*biz.ReviewStats, error) {
+	stats := &biz.ReviewStats{}
+	index := "review"
+	if categoryID > 0 {
+		index = statsIndex
+	}
+	resp, err := r.searchStats(ctx, index, storeID, categoryID, map[string]types.Aggregations{
+		"score":         {Avg: &types.AverageAggregation{Field: some.String("score")}},
+		"service_score": {Avg: &types.AverageAggregation{Field: some.String("service_score")}},
+		"express_score": {Avg: &types.AverageAggregation{Field: some.String("express_score")}},
+	})
+	if isESNotFound(err) {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.Hits.Total != nil {
+		stats.Total = resp.Hits.Total.Value
+	}
+	stats.AverageScore = avgValue(resp, "score")
+	stats.AverageServiceScore = avgValue(resp, "service_score")
+	stats.AverageExpressScore = avgValue(resp, "express_score")
+	if len(codes) == 0 {
+		return stats, nil
+	}
+	aggs := make(map[string]types.Aggregations, len(codes)*2)
+	for _, code := range codes {
+		field := "scores." + code
+		aggs["avg_"+code] = types.Aggregations{Avg: &types.AverageAggregation{Field: some.String(field)}}
+		aggs["count_"+code] = types.Aggregations{ValueCount: &types.ValueCountAggregation{Field: some.String(field)}}
+	}
+	resp, err = r.searchStats(ctx, statsIndex, storeID, categoryID, aggs)
+	if isESNotFound(err) {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, code := range codes {
+		var count int64
+		if agg, ok := resp.Aggregations["count_"+code].(*types.ValueCountAggregate); ok {
+			count = int64(agg.Value)
+		}
+		if count == 0 {
+			continue
+		}
+		stats.Dimensions = append(stats.Dimensions, &biz.DimensionStats{
+			Code:    code,
+			Average: avgValue(resp, "avg_"+code),
+			Count:   count,
+		})
+	}
+	return stats, nil
+}
+
+func (r *reviewRepo) searchStats(ctx context.Context, index string, storeID, categoryID int64, aggs map[string]types.Aggregations) (*search.Response, error) {
+	return r.data.es.Search().Index(index).Size(0).TypedKeys(true).TrackTotalHits(true).
+		Query(statsFilter(storeID, categoryID)).
+		Aggregations(aggs).Do(ctx)
+}
+
+func avgValue(resp *search.Response, name string) float64 {
+	if agg, ok := resp.Aggregations[name].(*types.AvgAggregate); ok {
+		return float64(agg.Value)
+	}
+	return 0
+}
+
+// isESNotFound 文档或索引不存在 还没有写入过维度评分时索引不存在
+func isESNotFound(err error) bool {
+	var esErr *types.ElasticsearchError
+	return errors.As(err, &esErr) && esErr.Status == http.StatusNotFound
+}
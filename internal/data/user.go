@@ -0,0 +1,27 @@
+package data
+
+import (
+	"context"
+
+	"review-service/internal/biz"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+type userRepo struct {
+	log *log.Helper
+}
+
+// NewUserRepo .
+func NewUserRepo(logger log.Logger) biz.UserRepo {
+	return &userRepo{
+		log: log.NewHelper(logger),
+	}
+}
+
+// BatchGetUser 批量查询用户的昵称和头像
+// TODO 对接用户服务，目前没有可用的用户服务，返回空结果由上层使用默认展示
+func (r *userRepo) BatchGetUser(ctx context.Context, userIDs []int64) (map[int64]*biz.UserInfo, error) {
+	r.log.WithContext(ctx).Debugw("msg", "BatchGetUser", "count", len(userIDs))
+	return map[int64]*biz.UserInfo{}, nil
+}
@@ -0,0 +1,276 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"review-service/internal/biz"
+	"review-service/internal/data/model"
+	"review-service/internal/data/query"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
+)
+
+// 评价投票在Redis中的存储
+// review:vote:{reviewID}       hash  helpful/unhelpful --> 计数，userID --> 投票类型(0为取消)
+// review:vote:dirty:{reviewID} set   投票有变化、等待刷回MySQL的userID
+// review:vote:dirty            set   计数有变化、等待刷回MySQL的reviewID
+// review:vote:flush:lock      string 刷回的锁 同一时间只有一个实例在刷回
+// 计数和用户的投票放在同一个hash中，一起被淘汰，丢失后从MySQL重新加载，不会出现重复投票
+// hash中没有的用户以 review_vote_info 中的投票为准，刷回时计数和用户的投票在同一个事务中写入
+// 有未刷回的投票时hash不过期，全部刷回后设置过期时间，不再投票的评价不会一直占用内存
+const (
+	voteDirtyKey     = "review:vote:dirty"
+	voteFlushLockKey = "review:vote:flush:lock"
+	voteTTL          = 24 * time.Hour
+	voteFlushLockTTL = time.Minute
+)
+
+func voteKey(reviewID int64) string {
+	return fmt.Sprintf("review:vote:%d", reviewID)
+}
+
+func voteDirtyUserKey(reviewID int64) string {
+	return fmt.Sprintf("review:vote:dirty:%d", reviewID)
+}
+
+// voteMiss 投票脚本需要先从MySQL加载计数或用户的投票
+const voteMiss = "miss"
+
+// voteScript 原子地完成投票、改票和取消投票，并维护计数 有未刷回的投票时去掉hash的过期时间
+// 计数不在缓存中时用ARGV中MySQL的计数初始化，用户的投票不在缓存中时以ARGV中MySQL的投票为准
+// 没有传入MySQL中的数据(ARGV[4]不为1)且需要时返回miss
+// KEYS[1] 投票hash KEYS[2] 待刷新的用户集合 KEYS[3] 待刷新的评价集合
+// ARGV[1] userID ARGV[2] 投票类型 ARGV[3] reviewID
+// ARGV[4] 是否传入了MySQL中的数据 ARGV[5] 有用数 ARGV[6] 无用数 ARGV[7] 用户的投票
+var voteScript = redis.NewScript(`
+local field = {["1"] = "helpful", ["2"] = "unhelpful"}
+local loaded = ARGV[4] == "1"
+if redis.call("HEXISTS", KEYS[1], "helpful") == 0 then
+	if not loaded then
+		return {"miss"}
+	end
+	redis.call("HSET", KEYS[1], "helpful", ARGV[5], "unhelpful", ARGV[6])
+end
+local old = redis.call("HGET", KEYS[1], ARGV[1])
+if not old then
+	if not loaded then
+		return {"miss"}
+	end
+	old = ARGV[7]
+end
+local vote = ARGV[2]
+if old == vote then
+	vote = "0"
+end
+if field[old] then
+	redis.call("HINCRBY", KEYS[1], field[old], -1)
+end
+if field[vote] then
+	redis.call("HINCRBY", KEYS[1], field[vote], 1)
+end
+redis.call("HSET", KEYS[1], ARGV[1], vote)
+redis.call("PERSIST", KEYS[1])
+redis.call("SADD", KEYS[2], ARGV[1])
+redis.call("SADD", KEYS[3], ARGV[3])
+local counts = redis.call("HMGET", KEYS[1], "helpful", "unhelpful")
+return {vote, counts[1], counts[2]}
+`)
+
+// VoteReview 用户给评价投票，投票和计数维护在Redis中，由定时任务刷回MySQL
+// 缓存中没有计数或该用户的投票时，从MySQL加载后再投一次
+func (r *reviewRepo) VoteReview(ctx context.Context, review *model.ReviewInfo, userID int64, vote int32) (*biz.VoteResult, error) {
+	keys := []string{voteKey(review.ReviewID), voteDirtyUserKey(review.ReviewID), voteDirtyKey}
+	ret, err := voteScript.Run(ctx, r.data.rdb, keys, userID, vote, review.ReviewID, 0).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+	if ret[0] == voteMiss {
+		helpful, unhelpful, old, err := r.loadVote(ctx, review.ReviewID, userID)
+		if err != nil {
+			return nil, err
+		}
+		ret, err = voteScript.Run(ctx, r.data.rdb, keys, userID, vote, review.ReviewID, 1, helpful, unhelpful, old).StringSlice()
+		if err != nil {
+			return nil, err
+		}
+	}
+	result := &biz.VoteResult{}
+	values := []*int32{&result.Vote, &result.HelpfulCount, &result.UnhelpfulCount}
+	for i, v := range values {
+		tmp, err := strconv.ParseInt(ret[i], 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		*v = int32(tmp)
+	}
+	return result, nil
+}
+
+// loadVote 从主库读取评价的投票计数和用户的投票 用户没有投票时为0
+func (r *reviewRepo) loadVote(ctx context.Context, reviewID, userID int64) (helpful, unhelpful, vote int32, err error) {
+	table, err := r.reviewTable(ctx, reviewID)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	ri := r.data.query.ReviewInfo.Table(table)
+	review, err := ri.WithContext(ctx).Clauses(dbresolver.Write).
+		Select(ri.HelpfulCount, ri.UnhelpfulCount).Where(ri.ReviewID.Eq(reviewID)).First()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, 0, 0, biz.ErrReviewNotFound
+	}
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	rv := r.data.query.ReviewVoteInfo
+	v, err := rv.WithContext(ctx).Clauses(dbresolver.Write).
+		Where(rv.ReviewID.Eq(reviewID), rv.UserID.Eq(userID)).First()
+	if err == nil {
+		vote = v.Vote
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, 0, 0, err
+	}
+	return review.HelpfulCount, review.UnhelpfulCount, vote, nil
+}
+
+// voteFlushScript 取出评价的计数和有变化的用户投票，并清空待刷新的用户集合
+// KEYS[1] 投票hash KEYS[2] 待刷新的用户集合
+// 返回 {有用数, 无用数, userID, 投票类型, userID, 投票类型, ...} 计数不在缓存中时返回空
+var voteFlushScript = redis.NewScript(`
+local users = redis.call("SMEMBERS", KEYS[2])
+redis.call("DEL", KEYS[2])
+local counts = redis.call("HMGET", KEYS[1], "helpful", "unhelpful")
+if not counts[1] then
+	return {}
+end
+local ret = {counts[1], counts[2]}
+for _, u in ipairs(users) do
+	local v = redis.call("HGET", KEYS[1], u)
+	if v then
+		table.insert(ret, u)
+		table.insert(ret, v)
+	end
+end
+return ret
+`)
+
+// voteExpireScript 刷回后没有新的投票时设置hash的过期时间
+// KEYS[1] 投票hash KEYS[2] 待刷新的用户集合 ARGV[1] 过期毫秒数
+var voteExpireScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[2]) == 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return 0
+`)
+
+// voteUnlockScript 只释放自己持有的刷回锁
+var voteUnlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// FlushVoteCount 把有变化的投票和计数刷回MySQL 返回本次处理的评价数
+// 多个实例同时刷回同一条评价时，先取出的旧计数可能覆盖后取出的新计数，所以刷回前先加锁
+// 锁被其它实例持有时本轮不处理
+func (r *reviewRepo) FlushVoteCount(ctx context.Context, batch int) (int, error) {
+	token := strconv.FormatInt(time.Now().UnixNano(), 10)
+	ok, err := r.data.rdb.SetNX(ctx, voteFlushLockKey, token, voteFlushLockTTL).Result()
+	if err != nil || !ok {
+		return 0, err
+	}
+	defer func() {
+		if err := voteUnlockScript.Run(context.WithoutCancel(ctx), r.data.rdb, []string{voteFlushLockKey}, token).Err(); err != nil {
+			r.log.WithContext(ctx).Errorf("FlushVoteCount unlock fail,err:%v\n", err)
+		}
+	}()
+	ids, err := r.data.rdb.SPopN(ctx, voteDirtyKey, int64(batch)).Result()
+	if err != nil {
+		return 0, err
+	}
+	for i, id := range ids {
+		if err := r.flushVoteCount(ctx, id); err != nil {
+			// 没刷成功的放回集合等下一轮
+			r.data.rdb.SAdd(ctx, voteDirtyKey, ids[i:])
+			return i, err
+		}
+	}
+	return len(ids), nil
+}
+
+func (r *reviewRepo) flushVoteCount(ctx context.Context, id string) error {
+	reviewID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		r.log.WithContext(ctx).Errorf("flushVoteCount invalid reviewID:%v\n", id)
+		return nil
+	}
+	table, err := r.reviewTable(ctx, reviewID)
+	if errors.Is(err, biz.ErrReviewNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	ret, err := voteFlushScript.Run(ctx, r.data.rdb, []string{voteKey(reviewID), voteDirtyUserKey(reviewID)}).StringSlice()
+	if err != nil {
+		return err
+	}
+	// 计数已经不在缓存中 下次投票时从MySQL重新加载
+	if len(ret) < 2 {
+		return nil
+	}
+	values := make([]int64, len(ret))
+	for i, s := range ret {
+		if values[i], err = strconv.ParseInt(s, 10, 64); err != nil {
+			return err
+		}
+	}
+	var users []string
+	for i := 2; i+1 < len(ret); i += 2 {
+		users = append(users, ret[i])
+	}
+	err = r.data.query.Transaction(func(tx *query.Query) error {
+		rv := tx.ReviewVoteInfo
+		for i := 2; i+1 < len(values); i += 2 {
+			userID, vote := values[i], int32(values[i+1])
+			if vote == 0 {
+				if _, err := rv.WithContext(ctx).Where(rv.ReviewID.Eq(reviewID), rv.UserID.Eq(userID)).Delete(); err != nil {
+					return err
+				}
+				continue
+			}
+			err := rv.WithContext(ctx).Clauses(clause.OnConflict{
+				Columns: []clause.Column{
+					{Name: "review_id"}, // ON DUPLICATE KEY
+					{Name: "user_id"},
+				},
+				DoUpdates: clause.Assignments(map[string]interface{}{"vote": vote}), // UPDATE
+			}).Create(&model.ReviewVoteInfo{ReviewID: reviewID, UserID: userID, Vote: vote})
+			if err != nil {
+				return err
+			}
+		}
+		ri := tx.ReviewInfo.Table(table)
+		_, err := ri.WithContext(ctx).Where(ri.ReviewID.Eq(reviewID)).Updates(map[string]interface{}{
+			"helpful_count":   values[0],
+			"unhelpful_count": values[1],
+		})
+		return err
+	})
+	if err != nil {
+		if len(users) > 0 {
+			// 用户的投票放回集合 和评价ID一起等下一轮
+			r.data.rdb.SAdd(ctx, voteDirtyUserKey(reviewID), users)
+		}
+		return err
+	}
+	// 刷回后hash中的用户投票和MySQL一致，过期后从MySQL重新加载
+	return voteExpireScript.Run(ctx, r.data.rdb, []string{voteKey(reviewID), voteDirtyUserKey(reviewID)}, voteTTL.Milliseconds()).Err()
+}
@@ -0,0 +1,186 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+
+	"review-service/internal/biz"
+	"review-service/internal/conf"
+	"review-service/internal/data/model"
+	"review-service/pkg/webhook"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"gorm.io/gorm"
+)
+
+// webhookTimeout 单次推送的超时时间 接收方应该先返回再异步处理
+const webhookTimeout = 5 * time.Second
+
+// webhookMaxBody 读取响应内容的长度上限 响应内容不记录也不返回
+const webhookMaxBody = 4096
+
+type webhookRepo struct {
+	data *Data
+	log  *log.Helper
+}
+
+// NewWebhookRepo .
+func NewWebhookRepo(data *Data, logger log.Logger) biz.WebhookRepo {
+	return &webhookRepo{
+		data: data,
+		log:  log.NewHelper(logger),
+	}
+}
+
+// ListWebhooks 店铺的webhook 按创建顺序排序
+func (r *webhookRepo) ListWebhooks(ctx context.Context, storeID int64) ([]*model.StoreWebhook, error) {
+	sw := r.data.query.StoreWebhook
+	return sw.WithContext(ctx).Where(sw.StoreID.Eq(storeID)).Order(sw.ID).Find()
+}
+
+func (r *webhookRepo) GetWebhook(ctx context.Context, id int64) (*model.StoreWebhook, error) {
+	sw := r.data.query.StoreWebhook
+	hook, err := sw.WithContext(ctx).Where(sw.ID.Eq(id)).First()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, biz.ErrWebhookNotFound
+	}
+	return hook, err
+}
+
+// SaveWebhook ID为0时新增，否则更新
+func (r *webhookRepo) SaveWebhook(ctx context.Context, hook *model.StoreWebhook) error {
+	hook.UpdateAt = time.Now()
+	if hook.ID == 0 {
+		return r.data.query.StoreWebhook.WithContext(ctx).Create(hook)
+	}
+	return r.data.query.StoreWebhook.WithContext(ctx).Save(hook)
+}
+
+func (r *webhookRepo) DeleteWebhook(ctx context.Context, id int64) error {
+	sw := r.data.query.StoreWebhook
+	_, err := sw.WithContext(ctx).Where(sw.ID.Eq(id)).Delete()
+	return err
+}
+
+func (r *webhookRepo) SaveDeadLetter(ctx context.Context, letter *model.WebhookDeadLetter) error {
+	return r.data.query.WebhookDeadLetter.WithContext(ctx).Create(letter)
+}
+
+type webhookSender struct {
+	client   *http.Client
+	resolver *net.Resolver
+	allowIP  func(netip.Addr) bool
+	log      *log.Helper
+}
+
+// NewWebhookSender 通过HTTP POST推送事件 请求体用webhook的密钥签名
+// 只推送到公网地址，保存webhook时和每次建立连接时都会检查，见 publicIP
+func NewWebhookSender(c *conf.Webhook, logger log.Logger) biz.WebhookSender {
+	allowIP := publicIP
+	if c.GetAllowPrivateNetwork() {
+		log.NewHelper(logger).Warnw("msg", "[data] webhook allows private network, do not enable in production")
+		allowIP = func(netip.Addr) bool { return true }
+	}
+	return newWebhookSender(allowIP, logger)
+}
+
+func newWebhookSender(allowIP func(netip.Addr) bool, logger log.Logger) *webhookSender {
+	s := &webhookSender{
+		resolver: net.DefaultResolver,
+		allowIP:  allowIP,
+		log:      log.NewHelper(logger),
+	}
+	dialer := &net.Dialer{Timeout: webhookTimeout, Control: s.control}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// 不使用代理 经过代理时连接目标地址的是代理，下面的检查不起作用
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	s.client = &http.Client{
+		Timeout: webhookTimeout,
+		// 链路追踪 每次推送一个span
+		Transport: otelhttp.NewTransport(transport),
+		// 不跟随重定向 避免签名的内容被转发到商家没有配置的地址
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return s
+}
+
+// errWebhookAddr 推送地址不是公网地址
+var errWebhookAddr = errors.New("webhook address is not a public address")
+
+// sharedAddrSpace 运营商级NAT的地址 云厂商内网也会使用
+var sharedAddrSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// publicIP 是否是公网地址
+// 回环、内网、链路本地(包括云厂商的元数据服务 169.254.169.254)、组播和未指定地址都不允许推送
+func publicIP(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddrSpace.Contains(addr)
+}
+
+// control 建立连接前检查实际连接的IP
+// 域名在保存webhook之后改为解析到内网地址(DNS rebinding)时同样拒绝
+func (s *webhookSender) control(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !s.allowIP(addr) {
+		return errWebhookAddr
+	}
+	return nil
+}
+
+// CheckURL 解析推送地址的域名 任何一个IP不是公网地址时返回错误
+func (s *webhookSender) CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	ips, err := s.resolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return err
+	}
+	for _, ip := range ips {
+		if !s.allowIP(ip) {
+			return errWebhookAddr
+		}
+	}
+	return nil
+}
+
+// Send 推送一次事件 失败时只返回状态码，不返回接收方的响应内容
+func (s *webhookSender) Send(ctx context.Context, hook *model.StoreWebhook, d *biz.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	webhook.SetHeaders(req.Header, hook.Secret, d.Event, d.ID, time.Now(), d.Payload)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		if errors.Is(err, errWebhookAddr) {
+			return 0, errWebhookAddr
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// 读完响应内容以便复用连接
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, webhookMaxBody))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
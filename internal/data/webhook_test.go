@@ -0,0 +1,132 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"review-service/internal/biz"
+	"review-service/internal/data/model"
+	"review-service/pkg/webhook"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// newTestSender 允许推送到httptest的回环地址
+func newTestSender() *webhookSender {
+	return newWebhookSender(func(netip.Addr) bool { return true }, log.DefaultLogger)
+}
+
+func testDelivery() *biz.WebhookDelivery {
+	return &biz.WebhookDelivery{ID: "1001", Event: webhook.EventPing, Payload: []byte(`{"id":"1001","type":"ping"}`)}
+}
+
+func TestWebhookSendSignature(t *testing.T) {
+	const secret = "s3cr3t"
+	var verifyErr error
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		verifyErr = webhook.Verify(secret, r.Header, body, 0)
+		if r.Header.Get(webhook.HeaderEvent) != webhook.EventPing || r.Header.Get(webhook.HeaderDelivery) != "1001" {
+			verifyErr = errors.New("missing event headers")
+		}
+	}))
+	defer srv.Close()
+
+	code, err := newTestSender().Send(context.Background(), &model.StoreWebhook{URL: srv.URL, Secret: secret}, testDelivery())
+	if err != nil || code != http.StatusOK {
+		t.Fatalf("Send() = %d, %v", code, err)
+	}
+	if verifyErr != nil {
+		t.Fatalf("Verify() = %v", verifyErr)
+	}
+}
+
+func TestWebhookSendNoRedirect(t *testing.T) {
+	var hits atomic.Int32
+	target := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		hits.Add(1)
+	}))
+	defer target.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer srv.Close()
+
+	code, err := newTestSender().Send(context.Background(), &model.StoreWebhook{URL: srv.URL, Secret: "x"}, testDelivery())
+	if err == nil || code != http.StatusTemporaryRedirect {
+		t.Fatalf("Send() = %d, %v, want 307 and an error", code, err)
+	}
+	if n := hits.Load(); n != 0 {
+		t.Fatalf("redirect target hit %d times", n)
+	}
+}
+
+func TestWebhookSendHidesBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("db password is hunter2"))
+	}))
+	defer srv.Close()
+
+	code, err := newTestSender().Send(context.Background(), &model.StoreWebhook{URL: srv.URL, Secret: "x"}, testDelivery())
+	if err == nil || code != http.StatusInternalServerError {
+		t.Fatalf("Send() = %d, %v", code, err)
+	}
+	if strings.Contains(err.Error(), "hunter2") {
+		t.Fatalf("error leaks the response body: %v", err)
+	}
+}
+
+func TestWebhookSendPrivateAddress(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		hits.Add(1)
+	}))
+	defer srv.Close()
+
+	// 建立连接时检查 保存后域名改为解析到内网地址时同样拒绝
+	s := newWebhookSender(publicIP, log.DefaultLogger)
+	_, err := s.Send(context.Background(), &model.StoreWebhook{URL: srv.URL, Secret: "x"}, testDelivery())
+	if !errors.Is(err, errWebhookAddr) {
+		t.Fatalf("Send() = %v, want errWebhookAddr", err)
+	}
+	if n := hits.Load(); n != 0 {
+		t.Fatalf("private address hit %d times", n)
+	}
+}
+
+func TestWebhookCheckURL(t *testing.T) {
+	s := newWebhookSender(publicIP, log.DefaultLogger)
+	tests := []struct {
+		url string
+		ok  bool
+	}{
+		{"http://127.0.0.1:8088/", false},
+		{"http://localhost/hook", false},
+		{"http://[::1]/", false},
+		{"http://10.0.0.1/", false},
+		{"http://172.16.5.4/", false},
+		{"http://192.168.1.1/", false},
+		{"http://169.254.169.254/latest/meta-data/", false},
+		{"http://100.64.0.1/", false},
+		{"http://0.0.0.0/", false},
+		{"http://[::ffff:127.0.0.1]/", false},
+		{"http://[fe80::1]/", false},
+		{"http://[fd00::1]/", false},
+		{"https://8.8.8.8/hook", true},
+		{"https://[2001:4860:4860::8888]/hook", true},
+	}
+	for _, tt := range tests {
+		err := s.CheckURL(context.Background(), tt.url)
+		if (err == nil) != tt.ok {
+			t.Errorf("CheckURL(%s) = %v, want ok=%v", tt.url, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"review-service/internal/conf"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// 日志格式
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// RedactedKeys 需要脱敏的日志字段 这些字段的值在输出前被替换成 ***
+// 评价内容、媒体URL和用户ID都属于用户数据，打日志时统一使用这些key
+var RedactedKeys = []string{
+	"content",
+	"pic_info",
+	"video_info",
+	"user_id",
+}
+
+// NewLogger 根据配置创建日志对象
+// 日志级别低于配置级别的不输出，RedactedKeys 中的字段做脱敏处理
+func NewLogger(c *conf.Log, w io.Writer) log.Logger {
+	var logger log.Logger
+	switch strings.ToLower(c.GetFormat()) {
+	case FormatJSON:
+		logger = newJSONLogger(w)
+	default:
+		logger = log.NewStdLogger(w)
+	}
+	level := log.LevelInfo
+	if c.GetLevel() != "" {
+		level = log.ParseLevel(c.GetLevel())
+	}
+	return log.NewFilter(logger,
+		log.FilterLevel(level),
+		log.FilterKey(RedactedKeys...),
+	)
+}
+
+// jsonLogger 每条日志输出一行JSON
+type jsonLogger struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func newJSONLogger(w io.Writer) log.Logger {
+	return &jsonLogger{enc: json.NewEncoder(w)}
+}
+
+func (l *jsonLogger) Log(level log.Level, keyvals ...interface{}) error {
+	if len(keyvals)%2 != 0 {
+		keyvals = append(keyvals, "KEYVALS UNPAIRED")
+	}
+	m := make(map[string]interface{}, len(keyvals)/2+1)
+	m[log.LevelKey] = level.String()
+	for i := 0; i < len(keyvals); i += 2 {
+		m[fmt.Sprint(keyvals[i])] = jsonValue(keyvals[i+1])
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.enc.Encode(m)
+}
+
+// jsonValue 基础类型原样输出，其它类型(error、结构体等)转成字符串
+func jsonValue(v interface{}) interface{} {
+	switch v.(type) {
+	case nil, string, bool,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Server 服务端的访问日志中间件 每个请求输出一行日志
+// 只记录接口、耗时和错误原因，不记录请求和响应的内容，避免泄露用户数据
+func Server(logger log.Logger) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			var (
+				kind      string
+				operation string
+				code      int32
+				reason    string
+			)
+			startTime := time.Now()
+			if info, ok := transport.FromServerContext(ctx); ok {
+				kind = info.Kind().String()
+				operation = info.Operation()
+			}
+			reply, err := handler(ctx, req)
+			level := log.LevelInfo
+			if se := errors.FromError(err); se != nil {
+				code = se.Code
+				reason = se.Reason
+				level = log.LevelWarn
+				if code >= 500 {
+					level = log.LevelError
+				}
+			}
+			_ = log.WithContext(ctx, logger).Log(level,
+				"kind", "server",
+				"component", kind,
+				"operation", operation,
+				"code", code,
+				"reason", reason,
+				"latency", time.Since(startTime).Seconds(),
+			)
+			return reply, err
+		}
+	}
+}
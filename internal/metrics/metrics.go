@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// 服务的Prometheus指标 通过HTTP服务的 /metrics 暴露
+// 比率类的指标(缓存命中率、singleflight合并率)不单独上报，在Prometheus中用计数器计算，例如:
+// sum(rate(review_cache_requests_total{result="hit"}[5m])) / sum(rate(review_cache_requests_total[5m]))
+
+const namespace = "review"
+
+var (
+	// ServerRequests 接口请求数 由kratos的metrics中间件上报
+	ServerRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "server",
+		Name:      "requests_code_total",
+		Help:      "The total number of processed requests",
+	}, []string{"kind", "operation", "code", "reason"})
+
+	// ServerSeconds 接口耗时 由kratos的metrics中间件上报
+	ServerSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "server",
+		Name:      "requests_seconds",
+		Help:      "Requests duration(sec).",
+		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
+	}, []string{"kind", "operation"})
+
+	// ReviewCreated 创建的评价数
+	ReviewCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "created_total",
+		Help:      "The total number of created reviews",
+	})
+
+	// ReviewAudited 运营审核评价数 按审核结果状态区分
+	ReviewAudited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "audited_total",
+		Help:      "The total number of audited reviews by status",
+	}, []string{"status"})
+
+	// AppealAudited 运营审核申诉数 按审核结果状态区分
+	AppealAudited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "appeal_audited_total",
+		Help:      "The total number of audited appeals by status",
+	}, []string{"status"})
+
+	// CacheRequests 商家评价列表的缓存查询数 result: hit/miss/error
+	CacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "The total number of store review list cache lookups by result",
+	}, []string{"result"})
+
+	// SingleflightRequests 经过singleflight的请求数 shared: 是否复用了其它请求的结果
+	SingleflightRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "singleflight_requests_total",
+		Help:      "The total number of singleflight calls by whether the result was shared",
+	}, []string{"shared"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		ServerRequests,
+		ServerSeconds,
+		ReviewCreated,
+		ReviewAudited,
+		AppealAudited,
+		CacheRequests,
+		SingleflightRequests,
+	)
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// kratos的metrics中间件只依赖 metrics.Counter / metrics.Observer 接口
+// 这里把Prometheus的指标适配成kratos的接口
+
+var (
+	_ metrics.Counter  = (*counter)(nil)
+	_ metrics.Observer = (*histogram)(nil)
+)
+
+type counter struct {
+	cv  *prometheus.CounterVec
+	lvs []string
+}
+
+// NewCounter Prometheus CounterVec --> metrics.Counter
+func NewCounter(cv *prometheus.CounterVec) metrics.Counter {
+	return &counter{cv: cv}
+}
+
+func (c *counter) With(lvs ...string) metrics.Counter {
+	return &counter{cv: c.cv, lvs: lvs}
+}
+
+func (c *counter) Inc() {
+	c.cv.WithLabelValues(c.lvs...).Inc()
+}
+
+func (c *counter) Add(delta float64) {
+	c.cv.WithLabelValues(c.lvs...).Add(delta)
+}
+
+type histogram struct {
+	hv  *prometheus.HistogramVec
+	lvs []string
+}
+
+// NewHistogram Prometheus HistogramVec --> metrics.Observer
+func NewHistogram(hv *prometheus.HistogramVec) metrics.Observer {
+	return &histogram{hv: hv}
+}
+
+func (h *histogram) With(lvs ...string) metrics.Observer {
+	return &histogram{hv: h.hv, lvs: lvs}
+}
+
+func (h *histogram) Observe(value float64) {
+	h.hv.WithLabelValues(h.lvs...).Observe(value)
+}
@@ -2,23 +2,43 @@ package server
 
 import (
 	"review-service/internal/conf"
+	"review-service/internal/data"
+	"review-service/internal/logging"
+	reviewmetrics "review-service/internal/metrics"
 	"review-service/internal/service"
 
 	v1 "review-service/api/review/v1"
 
 	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware/metadata"
+	"github.com/go-kratos/kratos/v2/middleware/metrics"
 	"github.com/go-kratos/kratos/v2/middleware/recovery"
+	"github.com/go-kratos/kratos/v2/middleware/tracing"
 	"github.com/go-kratos/kratos/v2/middleware/validate"
 	"github.com/go-kratos/kratos/v2/transport/grpc"
 )
 
 // NewGRPCServer new a gRPC server.
-func NewGRPCServer(c *conf.Server, reviewer *service.ReviewService, logger log.Logger) *grpc.Server {
+func NewGRPCServer(c *conf.Server, reviewer *service.ReviewService, auth *service.CallerAuth, logger log.Logger) *grpc.Server {
 	var opts = []grpc.ServerOption{
 		grpc.Middleware(
 			recovery.Recovery(),
+			tracing.Server(),
+			logging.Server(logger),
+			metrics.Server(
+				metrics.WithRequests(reviewmetrics.NewCounter(reviewmetrics.ServerRequests)),
+				metrics.WithSeconds(reviewmetrics.NewHistogram(reviewmetrics.ServerSeconds)),
+			),
+			service.ErrorTranslator(logger),
+			metadata.Server(),
+			auth.Middleware(),
+			data.ReadYourWrites(),
 			validate.Validator(),
 		),
+		// kratos的中间件不作用于流式接口 调用方身份由拦截器解析
+		grpc.StreamInterceptor(auth.StreamInterceptor()),
+		// 健康检查由 HealthServer 根据依赖的状态维护
+		grpc.CustomHealth(),
 	}
 	if c.Grpc.Network != "" {
 		opts = append(opts, grpc.Network(c.Grpc.Network))
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	nethttp "net/http"
+	"time"
+
+	v1 "review-service/api/review/v1"
+	"review-service/internal/biz"
+
+	"github.com/go-kratos/kratos/v2"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"github.com/hashicorp/consul/api"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// healthCheckInterval 定时检查依赖的间隔
+const healthCheckInterval = 10 * time.Second
+
+// HealthServer 健康检查
+// HTTP: /healthz 存活检查，进程在就返回200，响应中带上各依赖的状态
+//
+//	/readyz  就绪检查，MySQL、Redis、ES 任何一个不可用时返回503
+//
+// gRPC: 标准的 grpc.health.v1.Health 服务，定时检查依赖并更新服务状态
+// consul: 依赖不可用时把服务实例置为维护状态，调用方不再发现该实例，恢复后取消维护状态
+type HealthServer struct {
+	uc     *biz.HealthUsecase
+	health *health.Server
+	consul *api.Client
+	log    *log.Helper
+	stop   chan struct{}
+
+	maintenance bool // consul中的实例当前是否处于维护状态
+}
+
+// NewHealthServer new a health server.
+// 健康检查的接口注册到已有的HTTP和gRPC服务上
+func NewHealthServer(uc *biz.HealthUsecase, hs *http.Server, gs *grpc.Server, client *api.Client, logger log.Logger) *HealthServer {
+	s := &HealthServer{
+		uc:     uc,
+		health: health.NewServer(),
+		consul: client,
+		log:    log.NewHelper(logger),
+		stop:   make(chan struct{}),
+	}
+	hs.HandleFunc("/healthz", s.healthz)
+	hs.HandleFunc("/readyz", s.readyz)
+	grpc_health_v1.RegisterHealthServer(gs, s.health)
+	return s
+}
+
+// Start 启动定时检查 阻塞直到Stop被调用
+func (s *HealthServer) Start(ctx context.Context) error {
+	var serviceID string
+	if app, ok := kratos.FromContext(ctx); ok {
+		serviceID = app.ID()
+	}
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+	for {
+		s.check(ctx, serviceID)
+		select {
+		case <-ticker.C:
+		case <-s.stop:
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Stop 停止定时检查 gRPC健康状态置为 NOT_SERVING
+func (s *HealthServer) Stop(context.Context) error {
+	s.health.Shutdown()
+	close(s.stop)
+	return nil
+}
+
+// check 检查依赖并同步到gRPC健康状态和consul
+func (s *HealthServer) check(ctx context.Context, serviceID string) {
+	status := s.uc.Check(ctx)
+	servingStatus := grpc_health_v1.HealthCheckResponse_SERVING
+	if !status.Ready {
+		servingStatus = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+		s.log.WithContext(ctx).Warnw("msg", "dependencies not ready", "dependencies", status.Dependencies)
+	}
+	s.health.SetServingStatus("", servingStatus)
+	s.health.SetServingStatus(v1.Review_ServiceDesc.ServiceName, servingStatus)
+
+	if serviceID == "" || status.Ready != s.maintenance {
+		return
+	}
+	// 服务还没注册到consul时会失败，下一轮再试
+	var err error
+	if status.Ready {
+		err = s.consul.Agent().DisableServiceMaintenance(serviceID)
+	} else {
+		err = s.consul.Agent().EnableServiceMaintenance(serviceID, "dependencies not ready")
+	}
+	if err != nil {
+		s.log.WithContext(ctx).Errorw("msg", "update consul maintenance failed", "err", err)
+		return
+	}
+	s.maintenance = !status.Ready
+}
+
+func (s *HealthServer) healthz(w nethttp.ResponseWriter, r *nethttp.Request) {
+	s.writeStatus(w, nethttp.StatusOK, s.uc.Check(r.Context()))
+}
+
+func (s *HealthServer) readyz(w nethttp.ResponseWriter, r *nethttp.Request) {
+	status := s.uc.Check(r.Context())
+	code := nethttp.StatusOK
+	if !status.Ready {
+		code = nethttp.StatusServiceUnavailable
+	}
+	s.writeStatus(w, code, status)
+}
+
+func (s *HealthServer) writeStatus(w nethttp.ResponseWriter, code int, status *biz.HealthStatus) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(status)
+}
@@ -3,19 +3,36 @@ package server
 import (
 	v1 "review-service/api/review/v1"
 	"review-service/internal/conf"
+	"review-service/internal/data"
+	"review-service/internal/logging"
+	reviewmetrics "review-service/internal/metrics"
 	"review-service/internal/service"
 
 	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware/metadata"
+	"github.com/go-kratos/kratos/v2/middleware/metrics"
 	"github.com/go-kratos/kratos/v2/middleware/recovery"
+	"github.com/go-kratos/kratos/v2/middleware/tracing"
 	"github.com/go-kratos/kratos/v2/middleware/validate"
 	"github.com/go-kratos/kratos/v2/transport/http"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // NewHTTPServer new an HTTP server.
-func NewHTTPServer(c *conf.Server, reviewer *service.ReviewService, logger log.Logger) *http.Server {
+func NewHTTPServer(c *conf.Server, ec *conf.Export, reviewer *service.ReviewService, gql *service.GraphQLServer, auth *service.CallerAuth, logger log.Logger) *http.Server {
 	var opts = []http.ServerOption{
 		http.Middleware(
 			recovery.Recovery(),
+			tracing.Server(),
+			logging.Server(logger),
+			metrics.Server(
+				metrics.WithRequests(reviewmetrics.NewCounter(reviewmetrics.ServerRequests)),
+				metrics.WithSeconds(reviewmetrics.NewHistogram(reviewmetrics.ServerSeconds)),
+			),
+			service.ErrorTranslator(logger),
+			metadata.Server(),
+			auth.Middleware(),
+			data.ReadYourWrites(),
 			validate.Validator(),
 		),
 	}
@@ -29,6 +46,16 @@ func NewHTTPServer(c *conf.Server, reviewer *service.ReviewService, logger log.L
 		opts = append(opts, http.Timeout(c.Http.Timeout.AsDuration()))
 	}
 	srv := http.NewServer(opts...)
+	// Prometheus指标
+	srv.Handle("/metrics", promhttp.Handler())
+	// 导出文件的下载 文件名带随机串，下载地址只通过 GetExportJob 返回给有权限的调用方
+	if prefix, h := data.ExportFileServer(ec); h != nil {
+		srv.HandlePrefix(prefix, h)
+	}
 	v1.RegisterReviewHTTPServer(srv, reviewer)
+	// GraphQL聚合查询 经过上面的中间件
+	if gql.Enabled() {
+		srv.Route("/").POST(service.GraphQLPath, gql.Handle)
+	}
 	return srv
 }
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"review-service/internal/biz"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// voteFlushInterval 投票计数刷回数据库的间隔
+const voteFlushInterval = 30 * time.Second
+
+// exportPollInterval 检查待执行和中断的导出任务的间隔
+const exportPollInterval = time.Minute
+
+// eventCleanupInterval 清理过期变更事件的间隔
+const eventCleanupInterval = time.Hour
+
+// JobServer 后台定时任务
+// 实现了 transport.Server 接口，随 kratos App 一起启动和停止
+type JobServer struct {
+	uc       *biz.ReviewUsecase
+	webhook  *biz.WebhookUsecase
+	export   *biz.ExportUsecase
+	watch    *biz.WatchUsecase
+	analysis *biz.AnalysisUsecase
+	log      *log.Helper
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewJobServer new a job server.
+func NewJobServer(uc *biz.ReviewUsecase, webhook *biz.WebhookUsecase, export *biz.ExportUsecase, watch *biz.WatchUsecase, analysis *biz.AnalysisUsecase, logger log.Logger) *JobServer {
+	return &JobServer{
+		uc:       uc,
+		webhook:  webhook,
+		export:   export,
+		watch:    watch,
+		analysis: analysis,
+		log:      log.NewHelper(logger),
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start 启动定时任务 阻塞直到Stop被调用
+func (s *JobServer) Start(ctx context.Context) error {
+	ticker := time.NewTicker(voteFlushInterval)
+	defer ticker.Stop()
+	exportTicker := time.NewTicker(exportPollInterval)
+	defer exportTicker.Stop()
+	cleanupTicker := time.NewTicker(eventCleanupInterval)
+	defer cleanupTicker.Stop()
+	// 退出前把剩余的计数刷回去 App停止时会同时取消ctx和调用Stop，两种退出都要刷
+	defer s.flushVoteCount(context.Background())
+	for {
+		select {
+		case <-ticker.C:
+			s.flushVoteCount(ctx)
+		case <-exportTicker.C:
+			s.runPendingExports(ctx)
+		case <-cleanupTicker.C:
+			s.cleanupEvents(ctx)
+		case <-s.stop:
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Stop 停止定时任务 等待进行中的webhook推送、导出任务和评价内容分析结束
+// 同时结束所有变更订阅，否则gRPC服务优雅退出时会一直等待订阅的流
+// 可以重复调用 只有第一次生效
+func (s *JobServer) Stop(ctx context.Context) error {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+		s.watch.Stop()
+		s.webhook.Stop(ctx)
+		s.export.Stop(ctx)
+		s.analysis.Stop(ctx)
+	})
+	return nil
+}
+
+func (s *JobServer) flushVoteCount(ctx context.Context) {
+	if err := s.uc.FlushVoteCount(ctx); err != nil {
+		s.log.WithContext(ctx).Errorf("FlushVoteCount failed,err:%v", err)
+	}
+}
+
+func (s *JobServer) runPendingExports(ctx context.Context) {
+	if err := s.export.RunPendingExports(ctx); err != nil {
+		s.log.WithContext(ctx).Errorf("RunPendingExports failed,err:%v", err)
+	}
+}
+
+func (s *JobServer) cleanupEvents(ctx context.Context) {
+	if err := s.watch.CleanupEvents(ctx); err != nil {
+		s.log.WithContext(ctx).Errorf("CleanupEvents failed,err:%v", err)
+	}
+}
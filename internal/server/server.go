@@ -10,16 +10,17 @@ import (
 )
 
 // ProviderSet is server providers.
-var ProviderSet = wire.NewSet(NewRegistrar, NewGRPCServer, NewHTTPServer)
+var ProviderSet = wire.NewSet(NewConsulClient, NewRegistrar, NewGRPCServer, NewHTTPServer, NewJobServer, NewHealthServer)
 
-func NewRegistrar(conf *conf.Registry) registry.Registrar {
+// NewConsulClient consul客户端 服务注册和健康检查共用
+func NewConsulClient(conf *conf.Registry) (*api.Client, error) {
 	c := api.DefaultConfig()
 	c.Address = conf.Consul.Address
 	c.Scheme = conf.Consul.Scheme
-	client, err := api.NewClient(c)
-	if err != nil {
-		panic(err)
-	}
+	return api.NewClient(c)
+}
+
+func NewRegistrar(client *api.Client) registry.Registrar {
 	reg := consul.New(client, consul.WithHealthCheck(true))
 	return reg
 }
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"review-service/internal/biz"
+	"review-service/internal/conf"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	kgrpc "github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+)
+
+// 调用方身份认证
+// 网关校验终端用户的登录态后，用与本服务共享的密钥签发短期的JWT，放在请求头 Authorization: Bearer {token} 中
+// 本服务只信任签名有效的JWT，调用方自己传入的角色和ID一律忽略；没有JWT的请求按未登录处理，只能看到公开的信息
+// JWT无效或过期时返回 NEED_LOGIN
+
+const (
+	authHeader  = "Authorization"
+	bearerScope = "Bearer "
+)
+
+// callerClaims JWT的载荷 sub为调用方的ID
+type callerClaims struct {
+	Role string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+type callerKey struct{}
+
+// CallerAuth 从请求的JWT中解析调用方身份
+type CallerAuth struct {
+	secret []byte
+	log    *log.Helper
+}
+
+// NewCallerAuth 没有配置密钥时只有开发模式可以启动
+func NewCallerAuth(c *conf.Server, logger log.Logger) (*CallerAuth, error) {
+	a := &CallerAuth{
+		secret: []byte(c.GetAuth().GetCallerSecret()),
+		log:    log.NewHelper(logger),
+	}
+	if len(a.secret) == 0 {
+		if !c.GetAuth().GetDevMode() {
+			return nil, errors.New("server.auth.caller_secret is required unless server.auth.dev_mode is enabled")
+		}
+		a.log.Warnw("msg", "[service] caller_secret is not configured, all requests are treated as anonymous")
+	}
+	return a, nil
+}
+
+// Middleware 解析一元请求的调用方 放在 ErrorTranslator 之后，认证失败的错误由它转换
+func (a *CallerAuth) Middleware() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			ctx, err := a.authenticate(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// StreamInterceptor 解析流式请求的调用方 kratos的中间件不作用于gRPC流
+func (a *CallerAuth) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticate(ss.Context())
+		if err != nil {
+			return translateError(ss.Context(), a.log, err)
+		}
+		return handler(srv, kgrpc.NewWrappedStream(ctx, ss))
+	}
+}
+
+// authenticate 校验请求头中的JWT 把调用方放入ctx
+// 没有配置密钥或请求没有带JWT时返回原来的ctx，调用方为未登录
+func (a *CallerAuth) authenticate(ctx context.Context) (context.Context, error) {
+	tr, ok := transport.FromServerContext(ctx)
+	if !ok || len(a.secret) == 0 {
+		return ctx, nil
+	}
+	header := tr.RequestHeader().Get(authHeader)
+	if header == "" {
+		return ctx, nil
+	}
+	token, ok := strings.CutPrefix(header, bearerScope)
+	if !ok {
+		return nil, biz.ErrNeedLogin
+	}
+	claims := &callerClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return a.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		a.log.WithContext(ctx).Warnw("msg", "[service] invalid caller token", "err", err)
+		return nil, biz.ErrNeedLogin
+	}
+	id, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil || id <= 0 {
+		return nil, biz.ErrNeedLogin
+	}
+	switch claims.Role {
+	case RoleUser, RoleStore, RoleOperator:
+	default:
+		return nil, biz.ErrNeedLogin
+	}
+	return context.WithValue(ctx, callerKey{}, caller{Role: claims.Role, ID: id}), nil
+}
+
+// callerFromContext 认证后的调用方 未登录时为零值
+func callerFromContext(ctx context.Context) caller {
+	c, _ := ctx.Value(callerKey{}).(caller)
+	return c
+}
+
+// userID 用户写操作的用户id 以JWT中的调用方为准，请求中的用户id只用来校验
+// 未登录返回 ErrNeedLogin；调用方不是用户，或请求中的用户id不是调用方时返回 ErrForbidden
+func (c caller) userID(reqID int64) (int64, error) {
+	return c.idAs(RoleUser, reqID)
+}
+
+// storeID 商家写操作的店铺id 规则同 userID
+func (c caller) storeID(reqID int64) (int64, error) {
+	return c.idAs(RoleStore, reqID)
+}
+
+// operator 运营后台接口只允许运营调用
+func (c caller) operator() error {
+	if c.Role == "" {
+		return biz.ErrNeedLogin
+	}
+	if c.Role != RoleOperator {
+		return biz.ErrForbidden
+	}
+	return nil
+}
+
+// storeScope 商家后台接口操作的店铺 商家只能操作自己的店铺，运营可以操作任意店铺
+// 只有运营可以传0操作全部店铺，商家传0时为自己的店铺
+func (c caller) storeScope(reqID int64) (int64, error) {
+	if c.Role == RoleOperator {
+		return reqID, nil
+	}
+	return c.storeID(reqID)
+}
+
+func (c caller) idAs(role string, reqID int64) (int64, error) {
+	if c.Role == "" {
+		return 0, biz.ErrNeedLogin
+	}
+	if c.Role != role || (reqID != 0 && reqID != c.ID) {
+		return 0, biz.ErrForbidden
+	}
+	return c.ID, nil
+}
+
+// author 回复对话中作者的类型、店铺id和用户id 商家和用户只能以自己的身份发言
+func (c caller) author(authorType int32, storeID, userID int64) (int32, int64, int64, error) {
+	switch c.Role {
+	case "":
+		return 0, 0, 0, biz.ErrNeedLogin
+	case RoleStore:
+		if authorType != 0 && authorType != biz.ReplyAuthorStore {
+			return 0, 0, 0, biz.ErrForbidden
+		}
+		id, err := c.storeID(storeID)
+		return biz.ReplyAuthorStore, id, 0, err
+	case RoleUser:
+		if authorType != 0 && authorType != biz.ReplyAuthorUser {
+			return 0, 0, 0, biz.ErrForbidden
+		}
+		id, err := c.userID(userID)
+		return biz.ReplyAuthorUser, 0, id, err
+	default:
+		return 0, 0, 0, biz.ErrForbidden
+	}
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"review-service/internal/biz"
+	"review-service/internal/conf"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testSecret = "test-secret"
+
+func newTestAuth(t *testing.T) *CallerAuth {
+	t.Helper()
+	a, err := NewCallerAuth(&conf.Server{Auth: &conf.Server_Auth{CallerSecret: testSecret}}, log.DefaultLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+// signToken 按网关的方式签发调用方的JWT
+func signToken(t *testing.T, method jwt.SigningMethod, secret, role, sub string, exp time.Time) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, callerClaims{
+		Role:             role,
+		RegisteredClaims: jwt.RegisteredClaims{Subject: sub, ExpiresAt: jwt.NewNumericDate(exp)},
+	}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestNewCallerAuth(t *testing.T) {
+	tests := []struct {
+		name string
+		auth *conf.Server_Auth
+		ok   bool
+	}{
+		{name: "secret", auth: &conf.Server_Auth{CallerSecret: testSecret}, ok: true},
+		{name: "no secret", auth: &conf.Server_Auth{}, ok: false},
+		{name: "no auth config", auth: nil, ok: false},
+		{name: "no secret in dev mode", auth: &conf.Server_Auth{DevMode: true}, ok: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCallerAuth(&conf.Server{Auth: tt.auth}, log.DefaultLogger)
+			if (err == nil) != tt.ok {
+				t.Fatalf("NewCallerAuth() = %v, want ok=%v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	future := time.Now().Add(time.Minute)
+	tests := []struct {
+		name   string
+		header string
+		want   caller
+		err    error
+	}{
+		{name: "no token", header: "", want: caller{}},
+		{name: "operator", header: bearerScope + signToken(t, jwt.SigningMethodHS256, testSecret, RoleOperator, "3", future), want: caller{Role: RoleOperator, ID: 3}},
+		{name: "store", header: bearerScope + signToken(t, jwt.SigningMethodHS256, testSecret, RoleStore, "10", future), want: caller{Role: RoleStore, ID: 10}},
+		{name: "not bearer", header: "Basic abc", err: biz.ErrNeedLogin},
+		{name: "wrong secret", header: bearerScope + signToken(t, jwt.SigningMethodHS256, "known-default", RoleOperator, "3", future), err: biz.ErrNeedLogin},
+		{name: "wrong method", header: bearerScope + signToken(t, jwt.SigningMethodHS512, testSecret, RoleOperator, "3", future), err: biz.ErrNeedLogin},
+		{name: "expired", header: bearerScope + signToken(t, jwt.SigningMethodHS256, testSecret, RoleUser, "1", time.Now().Add(-time.Minute)), err: biz.ErrNeedLogin},
+		{name: "unknown role", header: bearerScope + signToken(t, jwt.SigningMethodHS256, testSecret, "admin", "1", future), err: biz.ErrNeedLogin},
+		{name: "invalid subject", header: bearerScope + signToken(t, jwt.SigningMethodHS256, testSecret, RoleUser, "abc", future), err: biz.ErrNeedLogin},
+	}
+	a := newTestAuth(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := a.authenticate(contextWithHeader(headerCarrier{authHeader: tt.header}))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("authenticate() = %v, want %v", err, tt.err)
+			}
+			if err == nil && callerFromContext(ctx) != tt.want {
+				t.Fatalf("caller = %+v, want %+v", callerFromContext(ctx), tt.want)
+			}
+		})
+	}
+	if c := callerFromContext(context.Background()); c != (caller{}) {
+		t.Fatalf("caller without auth = %+v", c)
+	}
+}
+
+func TestCallerIdentity(t *testing.T) {
+	user := caller{Role: RoleUser, ID: 1}
+	store := caller{Role: RoleStore, ID: 10}
+	operator := caller{Role: RoleOperator, ID: 3}
+	tests := []struct {
+		name  string
+		c     caller
+		store bool
+		reqID int64
+		want  int64
+		err   error
+	}{
+		{name: "user from token", c: user, want: 1},
+		{name: "user matches request", c: user, reqID: 1, want: 1},
+		{name: "user impersonates another user", c: user, reqID: 2, err: biz.ErrForbidden},
+		{name: "store acts as user", c: store, reqID: 10, err: biz.ErrForbidden},
+		{name: "operator acts as user", c: operator, reqID: 1, err: biz.ErrForbidden},
+		{name: "anonymous user", c: caller{}, reqID: 1, err: biz.ErrNeedLogin},
+		{name: "store from token", c: store, store: true, want: 10},
+		{name: "store matches request", c: store, store: true, reqID: 10, want: 10},
+		{name: "store acts for a competitor", c: store, store: true, reqID: 11, err: biz.ErrForbidden},
+		{name: "user acts as store", c: user, store: true, reqID: 1, err: biz.ErrForbidden},
+		{name: "anonymous store", c: caller{}, store: true, reqID: 10, err: biz.ErrNeedLogin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := tt.c.userID(tt.reqID)
+			if tt.store {
+				id, err = tt.c.storeID(tt.reqID)
+			}
+			if !errors.Is(err, tt.err) || id != tt.want {
+				t.Fatalf("id = %d, %v, want %d, %v", id, err, tt.want, tt.err)
+			}
+		})
+	}
+}
+
+func TestCallerAuthor(t *testing.T) {
+	tests := []struct {
+		name       string
+		c          caller
+		authorType int32
+		storeID    int64
+		userID     int64
+		want       [3]int64
+		err        error
+	}{
+		{name: "store", c: caller{Role: RoleStore, ID: 10}, authorType: biz.ReplyAuthorStore, storeID: 10, want: [3]int64{int64(biz.ReplyAuthorStore), 10, 0}},
+		{name: "store without author type", c: caller{Role: RoleStore, ID: 10}, want: [3]int64{int64(biz.ReplyAuthorStore), 10, 0}},
+		{name: "store ignores user id", c: caller{Role: RoleStore, ID: 10}, userID: 1, want: [3]int64{int64(biz.ReplyAuthorStore), 10, 0}},
+		{name: "user", c: caller{Role: RoleUser, ID: 1}, authorType: biz.ReplyAuthorUser, userID: 1, want: [3]int64{int64(biz.ReplyAuthorUser), 0, 1}},
+		{name: "user claims store author", c: caller{Role: RoleUser, ID: 1}, authorType: biz.ReplyAuthorStore, storeID: 10, err: biz.ErrForbidden},
+		{name: "store claims user author", c: caller{Role: RoleStore, ID: 10}, authorType: biz.ReplyAuthorUser, userID: 1, err: biz.ErrForbidden},
+		{name: "store for a competitor", c: caller{Role: RoleStore, ID: 10}, authorType: biz.ReplyAuthorStore, storeID: 11, err: biz.ErrForbidden},
+		{name: "operator", c: caller{Role: RoleOperator, ID: 3}, authorType: biz.ReplyAuthorStore, storeID: 10, err: biz.ErrForbidden},
+		{name: "anonymous", c: caller{}, authorType: biz.ReplyAuthorUser, userID: 1, err: biz.ErrNeedLogin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorType, storeID, userID, err := tt.c.author(tt.authorType, tt.storeID, tt.userID)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("author() err = %v, want %v", err, tt.err)
+			}
+			if err == nil && [3]int64{int64(authorType), storeID, userID} != tt.want {
+				t.Fatalf("author() = %d, %d, %d, want %v", authorType, storeID, userID, tt.want)
+			}
+		})
+	}
+}
+
+func TestCallerStoreScope(t *testing.T) {
+	tests := []struct {
+		name  string
+		c     caller
+		reqID int64
+		want  int64
+		err   error
+	}{
+		{name: "own store", c: caller{Role: RoleStore, ID: 10}, reqID: 10, want: 10},
+		{name: "store defaults to own store", c: caller{Role: RoleStore, ID: 10}, want: 10},
+		{name: "competitor store", c: caller{Role: RoleStore, ID: 10}, reqID: 11, err: biz.ErrForbidden},
+		{name: "operator any store", c: caller{Role: RoleOperator, ID: 3}, reqID: 11, want: 11},
+		{name: "operator all stores", c: caller{Role: RoleOperator, ID: 3}, want: 0},
+		{name: "user", c: caller{Role: RoleUser, ID: 1}, reqID: 10, err: biz.ErrForbidden},
+		{name: "anonymous", c: caller{}, reqID: 10, err: biz.ErrNeedLogin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := tt.c.storeScope(tt.reqID)
+			if !errors.Is(err, tt.err) || id != tt.want {
+				t.Fatalf("storeScope() = %d, %v, want %d, %v", id, err, tt.want, tt.err)
+			}
+		})
+	}
+}
+
+func TestCallerOperator(t *testing.T) {
+	tests := []struct {
+		name string
+		c    caller
+		err  error
+	}{
+		{name: "operator", c: caller{Role: RoleOperator, ID: 3}},
+		{name: "store", c: caller{Role: RoleStore, ID: 10}, err: biz.ErrForbidden},
+		{name: "user", c: caller{Role: RoleUser, ID: 1}, err: biz.ErrForbidden},
+		{name: "anonymous", c: caller{}, err: biz.ErrNeedLogin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.c.operator(); !errors.Is(err, tt.err) {
+				t.Fatalf("operator() = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,332 @@
+package convert
+
+import (
+	"strings"
+	"time"
+
+	pb "review-service/api/review/v1"
+	"review-service/internal/biz"
+	"review-service/internal/data/model"
+)
+
+// 数据库和ES中的评价数据 --> API中的评价数据
+// 所有对外返回评价的接口都通过这里转换，新增字段时只需要修改这一处
+
+// Review 评价 --> pb.ReviewInfo
+// reply 和 appeal 可以为nil
+func Review(r *model.ReviewInfo, reply *model.ReviewReplyInfo, appeal *model.ReviewAppealInfo) *pb.ReviewInfo {
+	if r == nil {
+		return nil
+	}
+	ext := biz.ParseReviewExt(r)
+	return &pb.ReviewInfo{
+		ReviewID:        r.ReviewID,
+		UserID:          r.UserID,
+		OrderID:         r.OrderID,
+		Score:           r.Score,
+		ServiceScore:    r.ServiceScore,
+		ExpressScore:    r.ExpressScore,
+		Content:         r.Content,
+		PicInfo:         trim(r.PicInfo),
+		VideoInfo:       trim(r.VideoInfo),
+		Status:          r.Status,
+		HelpfulCount:    r.HelpfulCount,
+		UnhelpfulCount:  r.UnhelpfulCount,
+		Anonymous:       r.Anonymous == 1,
+		StoreID:         r.StoreID,
+		SkuID:           r.SkuID,
+		SpuID:           r.SpuID,
+		HasMedia:        r.HasMedia == 1,
+		HasReply:        r.HasReply == 1,
+		Tags:            trim(r.Tags),
+		IsDefault:       r.IsDefault == 1,
+		CreateAt:        formatTime(r.CreateAt),
+		UpdateAt:        formatTime(r.UpdateAt),
+		OpReason:        trim(r.OpReason),
+		OpRemarks:       trim(r.OpRemarks),
+		OpUser:          trim(r.OpUser),
+		Reply:           Reply(reply),
+		Appeal:          Appeal(appeal),
+		CategoryID:      ext.CategoryID,
+		DimensionScores: ext.DimensionScores,
+		Sentiment:       ext.Sentiment,
+		Keywords:        ext.Keywords,
+	}
+}
+
+// Reviews 评价列表 --> []*pb.ReviewInfo
+// rel 中没有的评价不带回复和申诉
+func Reviews(list []*model.ReviewInfo, rel *biz.ReviewRelation) []*pb.ReviewInfo {
+	ret := make([]*pb.ReviewInfo, 0, len(list))
+	for _, r := range list {
+		var (
+			reply  *model.ReviewReplyInfo
+			appeal *model.ReviewAppealInfo
+		)
+		if rel != nil {
+			reply, appeal = rel.Replies[r.ReviewID], rel.Appeals[r.ReviewID]
+		}
+		ret = append(ret, Review(r, reply, appeal))
+	}
+	return ret
+}
+
+// ReviewFromES ES中的评价文档 --> 评价
+// ES文档中的数字字段是字符串，由 biz.MyReviewInfo 反序列化后覆盖了内嵌的同名字段，这里还原成完整的评价
+func ReviewFromES(r *biz.MyReviewInfo) *model.ReviewInfo {
+	if r == nil {
+		return nil
+	}
+	ret := &model.ReviewInfo{}
+	if r.ReviewInfo != nil {
+		*ret = *r.ReviewInfo
+	}
+	ret.ID = r.ID
+	ret.Version = r.Version
+	ret.ReviewID = r.ReviewID
+	ret.OrderID = r.OrderID
+	ret.SkuID = r.SkuID
+	ret.SpuID = r.SpuID
+	ret.StoreID = r.StoreID
+	ret.UserID = r.UserID
+	ret.Anonymous = r.Anonymous
+	ret.Score = r.Score
+	ret.ServiceScore = r.ServiceScore
+	ret.ExpressScore = r.ExpressScore
+	ret.HasMedia = r.HasMedia
+	ret.Status = r.Status
+	ret.IsDefault = r.IsDefault
+	ret.HasReply = r.HasReply
+	ret.HelpfulCount = r.HelpfulCount
+	ret.UnhelpfulCount = r.UnhelpfulCount
+	ret.CreateAt = time.Time(r.CreateAt)
+	ret.UpdateAt = time.Time(r.UpdateAt)
+	return ret
+}
+
+// ReviewsFromES ES中的评价文档列表 --> 评价列表
+func ReviewsFromES(list []*biz.MyReviewInfo) []*model.ReviewInfo {
+	ret := make([]*model.ReviewInfo, 0, len(list))
+	for _, r := range list {
+		ret = append(ret, ReviewFromES(r))
+	}
+	return ret
+}
+
+// Reply 回复 --> pb.ReplyInfo
+func Reply(r *model.ReviewReplyInfo) *pb.ReplyInfo {
+	if r == nil {
+		return nil
+	}
+	return &pb.ReplyInfo{
+		ReplyID:    r.ReplyID,
+		ReviewID:   r.ReviewID,
+		ParentID:   r.ParentID,
+		AuthorType: r.AuthorType,
+		StoreID:    r.StoreID,
+		UserID:     r.UserID,
+		Seq:        r.Seq,
+		Content:    r.Content,
+		PicInfo:    trim(r.PicInfo),
+		VideoInfo:  trim(r.VideoInfo),
+		CreateAt:   formatTime(r.CreateAt),
+		UpdateAt:   formatTime(r.UpdateAt),
+	}
+}
+
+// Replies 回复列表 --> []*pb.ReplyInfo
+func Replies(list []*model.ReviewReplyInfo) []*pb.ReplyInfo {
+	ret := make([]*pb.ReplyInfo, 0, len(list))
+	for _, r := range list {
+		ret = append(ret, Reply(r))
+	}
+	return ret
+}
+
+// Appeal 申诉 --> pb.AppealSummary
+func Appeal(a *model.ReviewAppealInfo) *pb.AppealSummary {
+	if a == nil {
+		return nil
+	}
+	return &pb.AppealSummary{
+		AppealID: a.AppealID,
+		Status:   a.Status,
+		Reason:   a.Reason,
+		Content:  a.Content,
+		CreateAt: formatTime(a.CreateAt),
+		UpdateAt: formatTime(a.UpdateAt),
+	}
+}
+
+// Reports 举报列表 --> []*pb.ReportInfo
+func Reports(list []*model.ReviewReportInfo) []*pb.ReportInfo {
+	ret := make([]*pb.ReportInfo, 0, len(list))
+	for _, r := range list {
+		ret = append(ret, &pb.ReportInfo{
+			ReportID: r.ReportID,
+			ReviewID: r.ReviewID,
+			UserID:   r.UserID,
+			Reason:   r.Reason,
+			Content:  trim(r.Content),
+			Status:   r.Status,
+			CreateAt: formatTime(r.CreateAt),
+		})
+	}
+	return ret
+}
+
+// Dimensions 评分维度 --> []*pb.RatingDimension
+func Dimensions(list []*model.ReviewDimension) []*pb.RatingDimension {
+	ret := make([]*pb.RatingDimension, 0, len(list))
+	for _, d := range list {
+		ret = append(ret, &pb.RatingDimension{
+			Code:     d.Code,
+			Name:     d.Name,
+			Required: d.Required == 1,
+			Sort:     d.Sort,
+		})
+	}
+	return ret
+}
+
+// Stats 评分统计 --> pb.GetReviewStatsReply
+func Stats(s *biz.ReviewStats) *pb.GetReviewStatsReply {
+	ret := &pb.GetReviewStatsReply{
+		Total:               s.Total,
+		AverageScore:        s.AverageScore,
+		AverageServiceScore: s.AverageServiceScore,
+		AverageExpressScore: s.AverageExpressScore,
+		Dimensions:          make([]*pb.DimensionStats, 0, len(s.Dimensions)),
+	}
+	for _, d := range s.Dimensions {
+		ret.Dimensions = append(ret.Dimensions, &pb.DimensionStats{
+			Code:    d.Code,
+			Name:    d.Name,
+			Average: d.Average,
+			Count:   d.Count,
+		})
+	}
+	return ret
+}
+
+// Dashboard 看板数据 --> pb.GetStoreReviewDashboardReply
+func Dashboard(points []*biz.DashboardPoint) *pb.GetStoreReviewDashboardReply {
+	ret := &pb.GetStoreReviewDashboardReply{Points: make([]*pb.DashboardPoint, 0, len(points))}
+	for _, p := range points {
+		ret.Points = append(ret.Points, &pb.DashboardPoint{
+			Date:                p.Date,
+			ReviewCount:         p.ReviewCount,
+			AverageScore:        p.AverageScore,
+			NegativeCount:       p.NegativeCount,
+			ReplyCount:          p.ReplyCount,
+			ReplyRate:           p.ReplyRate,
+			AverageReplySeconds: p.AverageReplySeconds,
+		})
+	}
+	return ret
+}
+
+// Keywords 关键词统计 --> pb.GetStoreComplaintKeywordsReply
+func Keywords(list []*biz.KeywordCount) *pb.GetStoreComplaintKeywordsReply {
+	ret := &pb.GetStoreComplaintKeywordsReply{Keywords: make([]*pb.KeywordCount, 0, len(list))}
+	for _, k := range list {
+		ret.Keywords = append(ret.Keywords, &pb.KeywordCount{
+			Keyword: k.Keyword,
+			Count:   k.Count,
+		})
+	}
+	return ret
+}
+
+// Webhook 店铺的webhook --> pb.StoreWebhook 不返回签名密钥
+func Webhook(h *model.StoreWebhook) *pb.StoreWebhook {
+	return &pb.StoreWebhook{
+		Id:       h.ID,
+		StoreID:  h.StoreID,
+		Url:      h.URL,
+		MaxScore: h.MaxScore,
+		Disabled: h.Enabled != 1,
+		CreateAt: formatTime(h.CreateAt),
+		UpdateAt: formatTime(h.UpdateAt),
+	}
+}
+
+// ExportJob 导出任务 --> pb.GetExportJobReply
+func ExportJob(j *biz.ExportJob) *pb.GetExportJobReply {
+	return &pb.GetExportJobReply{
+		JobID:       j.JobID,
+		StoreID:     j.StoreID,
+		Format:      j.Format,
+		Status:      j.Status,
+		RowCount:    j.RowCount,
+		DownloadURL: j.DownloadURL,
+		Error:       j.ErrMsg,
+		CreateAt:    formatTime(j.CreateAt),
+		UpdateAt:    formatTime(j.UpdateAt),
+	}
+}
+
+// MaxImportRows 导入结果最多返回的行数
+const MaxImportRows = 1000
+
+// ImportReport 导入结果 --> pb.ImportReviewsReply msg把每行的错误转换成调用方语言的消息
+func ImportReport(r *biz.ImportReport, msg func(error) string) *pb.ImportReviewsReply {
+	rows := r.Rows
+	truncated := len(rows) > MaxImportRows
+	if truncated {
+		rows = rows[:MaxImportRows]
+	}
+	ret := &pb.ImportReviewsReply{
+		Total:      int32(r.Total),
+		Imported:   int32(r.Imported),
+		Duplicated: int32(r.Duplicated),
+		Failed:     int32(r.Failed),
+		Rows:       make([]*pb.ImportRowResult, 0, len(rows)),
+		Truncated:  truncated,
+	}
+	for _, row := range rows {
+		ret.Rows = append(ret.Rows, &pb.ImportRowResult{
+			Line:            int32(row.Line),
+			ExternalOrderID: row.ExternalOrderID,
+			Result:          row.Result,
+			Error:           msg(row.Err),
+		})
+	}
+	return ret
+}
+
+// Webhooks 店铺的webhook列表 --> []*pb.StoreWebhook
+func Webhooks(list []*model.StoreWebhook) []*pb.StoreWebhook {
+	ret := make([]*pb.StoreWebhook, 0, len(list))
+	for _, h := range list {
+		ret = append(ret, Webhook(h))
+	}
+	return ret
+}
+
+// Event 变更事件 --> pb.ReviewEvent
+func Event(e *model.ReviewEvent) *pb.ReviewEvent {
+	return &pb.ReviewEvent{
+		Seq:      e.ID,
+		Type:     e.EventType,
+		ReviewID: e.ReviewID,
+		StoreID:  e.StoreID,
+		UserID:   e.UserID,
+		TargetID: e.TargetID,
+		Payload:  e.Payload,
+		CreateAt: formatTime(e.CreateAt),
+	}
+}
+
+// formatTime 零值时间返回空字符串
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.DateTime)
+}
+
+// trim 表中字符串字段的默认值是一个空格，返回前去掉
+func trim(s string) string {
+	return strings.TrimSpace(s)
+}
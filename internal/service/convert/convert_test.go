@@ -0,0 +1,390 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	pb "review-service/api/review/v1"
+	"review-service/internal/biz"
+	"review-service/internal/data/model"
+
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	createAt = time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	updateAt = time.Date(2024, 5, 2, 8, 0, 15, 0, time.UTC)
+)
+
+// 模型中不对外返回的字段 新增的字段不在这里也不在API中时测试失败，避免漏掉转换
+var (
+	reviewInternal = []string{"ID", "CreateBy", "UpdateBy", "DeleteAt", "Version", "GoodsSnapshot", "ExtJSON", "CtrlJSON"}
+	replyInternal  = []string{"ID", "CreateBy", "UpdateBy", "Version", "IsDel", "Status", "ExtJSON", "CtrlJSON"}
+	appealInternal = []string{"ID", "CreateBy", "UpdateBy", "DeleteAt", "Version", "ReviewID", "StoreID", "PicInfo", "VideoInfo", "OpRemarks", "OpUser", "ExtJSON", "CtrlJSON"}
+	// API中由 service 层填充的字段
+	reviewPresented = []string{"Nickname", "Avatar"}
+)
+
+func newReview() *model.ReviewInfo {
+	return &model.ReviewInfo{
+		ID:             1,
+		CreateBy:       "creator",
+		UpdateBy:       "updater",
+		CreateAt:       createAt,
+		UpdateAt:       updateAt,
+		Version:        3,
+		ReviewID:       1001,
+		Content:        "味道不错",
+		Score:          5,
+		ServiceScore:   4,
+		ExpressScore:   3,
+		HasMedia:       1,
+		OrderID:        2001,
+		SkuID:          3001,
+		SpuID:          4001,
+		StoreID:        5001,
+		UserID:         6001,
+		Anonymous:      1,
+		Tags:           `["好吃"]`,
+		PicInfo:        `["a.jpg"]`,
+		VideoInfo:      `["a.mp4"]`,
+		Status:         20,
+		IsDefault:      1,
+		HasReply:       1,
+		HelpfulCount:   7,
+		UnhelpfulCount: 2,
+		OpReason:       "reason",
+		OpRemarks:      "remarks",
+		OpUser:         "op",
+		GoodsSnapshot:  `{"name":"goods"}`,
+		ExtJSON:        `{"category_id":9,"dimension_scores":{"taste":5},"sentiment":"positive","keywords":["味道"]}`,
+		CtrlJSON:       `{"ctrl":1}`,
+	}
+}
+
+func newReply() *model.ReviewReplyInfo {
+	return &model.ReviewReplyInfo{
+		ID:         1,
+		CreateBy:   "creator",
+		UpdateBy:   "updater",
+		CreateAt:   createAt,
+		UpdateAt:   updateAt,
+		Version:    2,
+		ReplyID:    7001,
+		ReviewID:   1001,
+		ParentID:   7000,
+		StoreID:    5001,
+		UserID:     6001,
+		AuthorType: biz.ReplyAuthorStore,
+		Seq:        2,
+		Status:     10,
+		Content:    "谢谢惠顾",
+		PicInfo:    `["r.jpg"]`,
+		VideoInfo:  `["r.mp4"]`,
+		ExtJSON:    `{}`,
+		CtrlJSON:   `{}`,
+	}
+}
+
+func newAppeal() *model.ReviewAppealInfo {
+	return &model.ReviewAppealInfo{
+		ID:        1,
+		CreateBy:  "creator",
+		UpdateBy:  "updater",
+		CreateAt:  createAt,
+		UpdateAt:  updateAt,
+		Version:   1,
+		AppealID:  8001,
+		ReviewID:  1001,
+		StoreID:   5001,
+		Status:    10,
+		Reason:    "malicious",
+		Content:   "恶意差评",
+		PicInfo:   `["p.jpg"]`,
+		VideoInfo: `["p.mp4"]`,
+		OpRemarks: "remarks",
+		OpUser:    "op",
+		ExtJSON:   `{}`,
+		CtrlJSON:  `{}`,
+	}
+}
+
+func wantReply() *pb.ReplyInfo {
+	return &pb.ReplyInfo{
+		ReplyID:    7001,
+		ReviewID:   1001,
+		ParentID:   7000,
+		AuthorType: biz.ReplyAuthorStore,
+		StoreID:    5001,
+		UserID:     6001,
+		Seq:        2,
+		Content:    "谢谢惠顾",
+		PicInfo:    `["r.jpg"]`,
+		VideoInfo:  `["r.mp4"]`,
+		CreateAt:   "2024-05-01 12:30:00",
+		UpdateAt:   "2024-05-02 08:00:15",
+	}
+}
+
+func wantAppeal() *pb.AppealSummary {
+	return &pb.AppealSummary{
+		AppealID: 8001,
+		Status:   10,
+		Reason:   "malicious",
+		Content:  "恶意差评",
+		CreateAt: "2024-05-01 12:30:00",
+		UpdateAt: "2024-05-02 08:00:15",
+	}
+}
+
+func wantReview() *pb.ReviewInfo {
+	return &pb.ReviewInfo{
+		ReviewID:        1001,
+		UserID:          6001,
+		OrderID:         2001,
+		Score:           5,
+		ServiceScore:    4,
+		ExpressScore:    3,
+		Content:         "味道不错",
+		PicInfo:         `["a.jpg"]`,
+		VideoInfo:       `["a.mp4"]`,
+		Status:          20,
+		HelpfulCount:    7,
+		UnhelpfulCount:  2,
+		Anonymous:       true,
+		StoreID:         5001,
+		SkuID:           3001,
+		SpuID:           4001,
+		HasMedia:        true,
+		HasReply:        true,
+		Tags:            `["好吃"]`,
+		IsDefault:       true,
+		CreateAt:        "2024-05-01 12:30:00",
+		UpdateAt:        "2024-05-02 08:00:15",
+		OpReason:        "reason",
+		OpRemarks:       "remarks",
+		OpUser:          "op",
+		Reply:           wantReply(),
+		Appeal:          wantAppeal(),
+		CategoryID:      9,
+		DimensionScores: map[string]int32{"taste": 5},
+		Sentiment:       "positive",
+		Keywords:        []string{"味道"},
+	}
+}
+
+// assertAllSet 结构体中除skip之外的导出字段都不是零值
+func assertAllSet(t *testing.T, v interface{}, skip ...string) {
+	t.Helper()
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Type().Field(i)
+		if !f.IsExported() || contains(skip, f.Name) {
+			continue
+		}
+		if rv.Field(i).IsZero() {
+			t.Errorf("%s.%s is not converted", rv.Type().Name(), f.Name)
+		}
+	}
+}
+
+// assertCovered 模型的字段要么在API中有同名字段，要么明确列为不对外返回
+func assertCovered(t *testing.T, m, api interface{}, internal []string) {
+	t.Helper()
+	mt, at := reflect.TypeOf(m).Elem(), reflect.TypeOf(api).Elem()
+	for i := 0; i < mt.NumField(); i++ {
+		name := mt.Field(i).Name
+		if _, ok := at.FieldByName(name); !ok && !contains(internal, name) {
+			t.Errorf("%s.%s is neither returned by %s nor listed as internal", mt.Name(), name, at.Name())
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestReview(t *testing.T) {
+	assertCovered(t, &model.ReviewInfo{}, &pb.ReviewInfo{}, reviewInternal)
+
+	blank := newReview()
+	blank.PicInfo, blank.VideoInfo, blank.Tags, blank.OpReason, blank.OpRemarks, blank.OpUser, blank.ExtJSON = " ", " ", " ", " ", " ", " ", " "
+	blank.Anonymous, blank.HasMedia, blank.HasReply, blank.IsDefault = 0, 0, 0, 0
+	blank.CreateAt, blank.UpdateAt = time.Time{}, time.Time{}
+	wantBlank := wantReview()
+	wantBlank.PicInfo, wantBlank.VideoInfo, wantBlank.Tags, wantBlank.OpReason, wantBlank.OpRemarks, wantBlank.OpUser = "", "", "", "", "", ""
+	wantBlank.Anonymous, wantBlank.HasMedia, wantBlank.HasReply, wantBlank.IsDefault = false, false, false, false
+	wantBlank.CreateAt, wantBlank.UpdateAt = "", ""
+	wantBlank.CategoryID, wantBlank.DimensionScores, wantBlank.Sentiment, wantBlank.Keywords = 0, nil, "", nil
+	wantBlank.Reply, wantBlank.Appeal = nil, nil
+
+	tests := []struct {
+		name   string
+		review *model.ReviewInfo
+		reply  *model.ReviewReplyInfo
+		appeal *model.ReviewAppealInfo
+		want   *pb.ReviewInfo
+	}{
+		{name: "all fields", review: newReview(), reply: newReply(), appeal: newAppeal(), want: wantReview()},
+		// 表中字符串字段的默认值是一个空格
+		{name: "default values", review: blank, want: wantBlank},
+		{name: "nil", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Review(tt.review, tt.reply, tt.appeal)
+			if !proto.Equal(got, tt.want) {
+				t.Fatalf("Review() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	assertAllSet(t, Review(newReview(), newReply(), newAppeal()), reviewPresented...)
+}
+
+// esDocument 同步到ES的评价文档 数字字段是字符串，时间格式为 2006-01-02 15:04:05
+func esDocument(t *testing.T, r *model.ReviewInfo) []byte {
+	t.Helper()
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range doc {
+		if n, ok := v.(json.Number); ok {
+			doc[k] = n.String()
+		}
+	}
+	doc["create_at"] = r.CreateAt.Format(time.DateTime)
+	doc["update_at"] = r.UpdateAt.Format(time.DateTime)
+	b, err = json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestReviewFromES(t *testing.T) {
+	tests := []struct {
+		name   string
+		review *model.ReviewInfo
+	}{
+		{name: "all fields", review: newReview()},
+		{name: "max ids", review: func() *model.ReviewInfo {
+			r := newReview()
+			// 超过float64精度的ID不能丢失精度
+			r.ReviewID, r.OrderID, r.UserID = 1<<62+1, 1<<62+3, 1<<62+5
+			return r
+		}()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc biz.MyReviewInfo
+			if err := json.Unmarshal(esDocument(t, tt.review), &doc); err != nil {
+				t.Fatal(err)
+			}
+			got := ReviewFromES(&doc)
+			if !reflect.DeepEqual(got, tt.review) {
+				t.Fatalf("ReviewFromES() = %+v, want %+v", got, tt.review)
+			}
+			assertAllSet(t, got, "DeleteAt")
+		})
+	}
+	if ReviewFromES(nil) != nil {
+		t.Fatal("ReviewFromES(nil) should be nil")
+	}
+}
+
+func TestReply(t *testing.T) {
+	assertCovered(t, &model.ReviewReplyInfo{}, &pb.ReplyInfo{}, replyInternal)
+	tests := []struct {
+		name  string
+		reply *model.ReviewReplyInfo
+		want  *pb.ReplyInfo
+	}{
+		{name: "all fields", reply: newReply(), want: wantReply()},
+		{name: "nil", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Reply(tt.reply); !proto.Equal(got, tt.want) {
+				t.Fatalf("Reply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	assertAllSet(t, Reply(newReply()))
+}
+
+func TestAppeal(t *testing.T) {
+	assertCovered(t, &model.ReviewAppealInfo{}, &pb.AppealSummary{}, appealInternal)
+	tests := []struct {
+		name   string
+		appeal *model.ReviewAppealInfo
+		want   *pb.AppealSummary
+	}{
+		{name: "all fields", appeal: newAppeal(), want: wantAppeal()},
+		{name: "nil", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Appeal(tt.appeal); !proto.Equal(got, tt.want) {
+				t.Fatalf("Appeal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	assertAllSet(t, Appeal(newAppeal()))
+}
+
+func TestReviews(t *testing.T) {
+	first, second := newReview(), newReview()
+	second.ReviewID = 1002
+	wantFirst, wantSecond := wantReview(), wantReview()
+	wantSecond.ReviewID = 1002
+	wantSecond.Reply, wantSecond.Appeal = nil, nil
+	noRel := wantReview()
+	noRel.Reply, noRel.Appeal = nil, nil
+
+	tests := []struct {
+		name string
+		list []*model.ReviewInfo
+		rel  *biz.ReviewRelation
+		want []*pb.ReviewInfo
+	}{
+		{
+			name: "with relation",
+			list: []*model.ReviewInfo{first, second},
+			rel: &biz.ReviewRelation{
+				Replies: map[int64]*model.ReviewReplyInfo{1001: newReply()},
+				Appeals: map[int64]*model.ReviewAppealInfo{1001: newAppeal()},
+			},
+			want: []*pb.ReviewInfo{wantFirst, wantSecond},
+		},
+		{name: "nil relation", list: []*model.ReviewInfo{first}, want: []*pb.ReviewInfo{noRel}},
+		{name: "empty", want: []*pb.ReviewInfo{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Reviews(tt.list, tt.rel)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Reviews() returned %d reviews, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if !proto.Equal(got[i], tt.want[i]) {
+					t.Errorf("Reviews()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	pb "review-service/api/review/v1"
+	"review-service/internal/biz"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// 错误消息支持的语言 根据请求头 Accept-Language 选择，默认中文
+const (
+	langZh = "zh"
+	langEn = "en"
+)
+
+// errorMessages 业务错误消息表 key为 biz.Error 的 Key
+var errorMessages = map[string]map[string]string{
+	"db_failed":             {langZh: "查询数据库失败", langEn: "database query failed"},
+	"internal_error":        {langZh: "服务内部错误", langEn: "internal server error"},
+	"gen_id_failed":         {langZh: "生成ID失败", langEn: "failed to generate id"},
+	"review_not_found":      {langZh: "评价不存在", langEn: "review not found"},
+	"reply_not_found":       {langZh: "回复不存在", langEn: "reply not found"},
+	"webhook_not_found":     {langZh: "webhook不存在", langEn: "webhook not found"},
+	"export_not_found":      {langZh: "导出任务不存在", langEn: "export job not found"},
+	"order_reviewed":        {langZh: "订单%d已评价", langEn: "order %d has already been reviewed"},
+	"review_replied":        {langZh: "评价已回复", langEn: "review has already been replied"},
+	"import_duplicate":      {langZh: "来源订单%s的评价已导入", langEn: "review of external order %s has already been imported"},
+	"need_login":            {langZh: "调用方身份无效或已过期", langEn: "caller token is invalid or expired"},
+	"forbidden":             {langZh: "水平越权", langEn: "permission denied"},
+	"vote_self":             {langZh: "不能给自己的评价投票", langEn: "cannot vote on your own review"},
+	"report_self":           {langZh: "不能举报自己的评价", langEn: "cannot report your own review"},
+	"review_not_replied":    {langZh: "商家尚未回复该评价", langEn: "the store has not replied to this review yet"},
+	"reply_self":            {langZh: "不能回复自己的回复", langEn: "cannot reply to your own reply"},
+	"thread_full":           {langZh: "回复对话已达楼层上限", langEn: "reply thread has reached its maximum length"},
+	"thread_root_delete":    {langZh: "商家首条回复不能在对话中删除", langEn: "the first store reply cannot be deleted from the thread"},
+	"reply_edit_expired":    {langZh: "回复已超过可修改时间", langEn: "reply can no longer be edited"},
+	"appeal_audited":        {langZh: "该评价已有审核过的申诉记录", langEn: "an appeal for this review has already been audited"},
+	"feature_disabled":      {langZh: "该功能暂未开放", langEn: "this feature is currently disabled"},
+	"watch_seq_expired":     {langZh: "序号%d之后的事件已过期，请重新同步后从最新位置订阅", langEn: "events after sequence %d have expired, resync and watch from now"},
+	"reply_modified":        {langZh: "回复已被修改，请刷新后重试", langEn: "reply has been modified, please refresh and retry"},
+	"review_reported":       {langZh: "已经举报过该评价", langEn: "you have already reported this review"},
+	"rate_limited":          {langZh: "请求过于频繁，请稍后重试", langEn: "too many requests, please retry later"},
+	"invalid_author_type":   {langZh: "无效的回复作者类型", langEn: "invalid reply author type"},
+	"invalid_vote":          {langZh: "无效的投票类型", langEn: "invalid vote type"},
+	"invalid_score":         {langZh: "%s必须在%d到%d之间", langEn: "%s must be between %d and %d"},
+	"content_empty":         {langZh: "内容不能为空", langEn: "content must not be empty"},
+	"content_too_long":      {langZh: "内容不能超过%d个字", langEn: "content must not exceed %d characters"},
+	"media_too_long":        {langZh: "媒体信息不能超过%d个字符", langEn: "media info must not exceed %d characters"},
+	"too_many_pics":         {langZh: "图片不能超过%d张", langEn: "no more than %d pictures are allowed"},
+	"too_many_videos":       {langZh: "视频不能超过%d个", langEn: "no more than %d videos are allowed"},
+	"invalid_appeal_reason": {langZh: "无效的申诉原因:%s", langEn: "invalid appeal reason: %s"},
+	"unknown_dimension":     {langZh: "该类目没有评分维度:%s", langEn: "unknown rating dimension for this category: %s"},
+	"dimension_required":    {langZh: "请为%s评分", langEn: "rating dimension %s is required"},
+	"invalid_dimension":     {langZh: "无效的评分维度:%s", langEn: "invalid rating dimension: %s"},
+	"too_many_dimensions":   {langZh: "评分维度不能超过%d个", langEn: "no more than %d rating dimensions are allowed"},
+	"invalid_date_range":    {langZh: "日期范围无效，结束日期不能早于开始日期且最多统计%d天", langEn: "invalid date range, the end date must not be before the start date and the range must not exceed %d days"},
+	"invalid_webhook_url":   {langZh: "推送地址必须是http或https地址", langEn: "webhook url must be an http or https url"},
+	"too_many_webhooks":     {langZh: "每个店铺最多配置%d个webhook", langEn: "no more than %d webhooks are allowed per store"},
+	"invalid_export_filter": {langZh: "无效的导出条件:%s", langEn: "invalid export filter: %s"},
+	"invalid_import_file":   {langZh: "无效的导入文件:%s", langEn: "invalid import file: %s"},
+	"invalid_import_row":    {langZh: "无效的字段或格式:%s", langEn: "invalid field or format: %s"},
+	"invalid_query_arg":     {langZh: "无效的查询参数:%s", langEn: "invalid query argument: %s"},
+	"query_too_deep":        {langZh: "查询的嵌套深度不能超过%d", langEn: "query depth must not exceed %d"},
+	"query_too_complex":     {langZh: "查询的复杂度%d超过上限%d", langEn: "query complexity %d exceeds the limit of %d"},
+}
+
+// reasonErrors 错误原因 --> API错误 HTTP状态码在 review_error.proto 中定义，gRPC状态码由kratos根据HTTP状态码转换
+var reasonErrors = map[pb.ErrorReason]func(format string, args ...interface{}) *kerrors.Error{
+	pb.ErrorReason_NEED_LOGIN:       pb.ErrorNeedLogin,
+	pb.ErrorReason_DB_FAILED:        pb.ErrorDbFailed,
+	pb.ErrorReason_INTERNAL_ERROR:   pb.ErrorInternalError,
+	pb.ErrorReason_ORDER_REVIEWED:   pb.ErrorOrderReviewed,
+	pb.ErrorReason_NOT_FOUND:        pb.ErrorNotFound,
+	pb.ErrorReason_ALREADY_REPLIED:  pb.ErrorAlreadyReplied,
+	pb.ErrorReason_FORBIDDEN:        pb.ErrorForbidden,
+	pb.ErrorReason_INVALID_STATE:    pb.ErrorInvalidState,
+	pb.ErrorReason_CONFLICT:         pb.ErrorConflict,
+	pb.ErrorReason_RATE_LIMITED:     pb.ErrorRateLimited,
+	pb.ErrorReason_INVALID_ARGUMENT: pb.ErrorInvalidArgument,
+}
+
+// ErrorTranslator 把handler返回的错误统一转换成API错误
+// 业务错误按错误原因转换并返回调用方语言的消息；kratos错误(如参数校验失败)原样返回；
+// 其它未知错误不把内部细节暴露给调用方，统一返回服务内部错误
+func ErrorTranslator(logger log.Logger) middleware.Middleware {
+	helper := log.NewHelper(logger)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			reply, err := handler(ctx, req)
+			if err != nil {
+				err = translateError(ctx, helper, err)
+			}
+			return reply, err
+		}
+	}
+}
+
+// translateError 把错误转换成API错误
+func translateError(ctx context.Context, helper *log.Helper, err error) error {
+	lang := langFromContext(ctx)
+	var bizErr *biz.Error
+	if errors.As(err, &bizErr) {
+		newErr, ok := reasonErrors[bizErr.Reason]
+		if !ok {
+			newErr = pb.ErrorInternalError
+		}
+		return newErr("%s", message(bizErr.Key, lang, bizErr.Args...))
+	}
+	var kratosErr *kerrors.Error
+	if errors.As(err, &kratosErr) {
+		return err
+	}
+	helper.WithContext(ctx).Errorw("msg", "[service] internal error", "err", err)
+	return pb.ErrorInternalError("%s", message("internal_error", lang))
+}
+
+// ErrorMessage 错误的消息 lang为zh或en，业务错误返回对应语言的消息，其它错误返回服务内部错误
+// 用于导入结果这类不直接作为API错误返回的场景
+func ErrorMessage(err error, lang string) string {
+	var bizErr *biz.Error
+	if errors.As(err, &bizErr) {
+		return message(bizErr.Key, lang, bizErr.Args...)
+	}
+	return message("internal_error", lang)
+}
+
+// message 获取指定语言的错误消息
+func message(key, lang string, args ...interface{}) string {
+	msgs, ok := errorMessages[key]
+	if !ok {
+		return key
+	}
+	msg, ok := msgs[lang]
+	if !ok {
+		msg = msgs[langZh]
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// langFromContext 根据请求头 Accept-Language 选择错误消息的语言
+// HTTP请求取请求头，gRPC请求取元数据 accept-language
+func langFromContext(ctx context.Context) string {
+	tr, ok := transport.FromServerContext(ctx)
+	if !ok {
+		return langZh
+	}
+	for _, tag := range strings.Split(tr.RequestHeader().Get("Accept-Language"), ",") {
+		tag = strings.ToLower(strings.TrimSpace(strings.SplitN(tag, ";", 2)[0]))
+		switch {
+		case strings.HasPrefix(tag, langZh):
+			return langZh
+		case strings.HasPrefix(tag, langEn):
+			return langEn
+		}
+	}
+	return langZh
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	pb "review-service/api/review/v1"
+	"review-service/internal/biz"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+	"google.golang.org/grpc/codes"
+)
+
+// headerCarrier 测试用的请求头
+type headerCarrier map[string]string
+
+func (h headerCarrier) Get(key string) string      { return h[key] }
+func (h headerCarrier) Set(key, value string)      { h[key] = value }
+func (h headerCarrier) Add(key, value string)      { h[key] = value }
+func (h headerCarrier) Keys() []string             { return nil }
+func (h headerCarrier) Values(key string) []string { return []string{h[key]} }
+
+// testTransport 测试用的服务端transport 只提供请求头
+type testTransport struct {
+	header headerCarrier
+}
+
+func (t testTransport) Kind() transport.Kind            { return transport.KindHTTP }
+func (t testTransport) Endpoint() string                { return "" }
+func (t testTransport) Operation() string               { return "" }
+func (t testTransport) RequestHeader() transport.Header { return t.header }
+func (t testTransport) ReplyHeader() transport.Header   { return headerCarrier{} }
+
+func contextWithHeader(header headerCarrier) context.Context {
+	return transport.NewServerContext(context.Background(), testTransport{header: header})
+}
+
+var testLog = log.NewHelper(log.DefaultLogger)
+
+func TestTranslateErrorReason(t *testing.T) {
+	tests := []struct {
+		err    *biz.Error
+		reason pb.ErrorReason
+		http   int
+		grpc   codes.Code
+	}{
+		{biz.ErrNeedLogin, pb.ErrorReason_NEED_LOGIN, http.StatusUnauthorized, codes.Unauthenticated},
+		{biz.ErrDBFailed, pb.ErrorReason_DB_FAILED, http.StatusInternalServerError, codes.Internal},
+		{biz.ErrGenID, pb.ErrorReason_INTERNAL_ERROR, http.StatusInternalServerError, codes.Internal},
+		{biz.ErrInternal, pb.ErrorReason_INTERNAL_ERROR, http.StatusInternalServerError, codes.Internal},
+		{biz.ErrReviewNotFound, pb.ErrorReason_NOT_FOUND, http.StatusNotFound, codes.NotFound},
+		{biz.ErrReplyNotFound, pb.ErrorReason_NOT_FOUND, http.StatusNotFound, codes.NotFound},
+		{biz.ErrWebhookNotFound, pb.ErrorReason_NOT_FOUND, http.StatusNotFound, codes.NotFound},
+		{biz.ErrExportNotFound, pb.ErrorReason_NOT_FOUND, http.StatusNotFound, codes.NotFound},
+		{biz.ErrOrderReviewed.WithArgs(int64(1)), pb.ErrorReason_ORDER_REVIEWED, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrReviewReplied, pb.ErrorReason_ALREADY_REPLIED, http.StatusConflict, codes.Aborted},
+		{biz.ErrImportDuplicate.WithArgs("x"), pb.ErrorReason_CONFLICT, http.StatusConflict, codes.Aborted},
+		{biz.ErrForbidden, pb.ErrorReason_FORBIDDEN, http.StatusForbidden, codes.PermissionDenied},
+		{biz.ErrVoteSelf, pb.ErrorReason_FORBIDDEN, http.StatusForbidden, codes.PermissionDenied},
+		{biz.ErrReportSelf, pb.ErrorReason_FORBIDDEN, http.StatusForbidden, codes.PermissionDenied},
+		{biz.ErrReviewNotReplied, pb.ErrorReason_INVALID_STATE, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrReplySelf, pb.ErrorReason_INVALID_STATE, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrThreadFull, pb.ErrorReason_INVALID_STATE, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrThreadRootDelete, pb.ErrorReason_INVALID_STATE, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrReplyEditExpired, pb.ErrorReason_INVALID_STATE, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrAppealAudited, pb.ErrorReason_INVALID_STATE, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrFeatureDisabled, pb.ErrorReason_INVALID_STATE, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrWatchSeqExpired.WithArgs(int64(1)), pb.ErrorReason_INVALID_STATE, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrReplyModified, pb.ErrorReason_CONFLICT, http.StatusConflict, codes.Aborted},
+		{biz.ErrReviewReported, pb.ErrorReason_CONFLICT, http.StatusConflict, codes.Aborted},
+		{biz.ErrRateLimited, pb.ErrorReason_RATE_LIMITED, http.StatusTooManyRequests, codes.ResourceExhausted},
+		{biz.ErrInvalidAuthorType, pb.ErrorReason_INVALID_ARGUMENT, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrInvalidVote, pb.ErrorReason_INVALID_ARGUMENT, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrInvalidScore.WithArgs("score", 1, 5), pb.ErrorReason_INVALID_ARGUMENT, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrContentEmpty, pb.ErrorReason_INVALID_ARGUMENT, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrContentTooLong.WithArgs(1), pb.ErrorReason_INVALID_ARGUMENT, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrMediaTooLong.WithArgs(1), pb.ErrorReason_INVALID_ARGUMENT, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrTooManyPics.WithArgs(1), pb.ErrorReason_INVALID_ARGUMENT, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrTooManyVideos.WithArgs(1), pb.ErrorReason_INVALID_ARGUMENT, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrInvalidAppealReason.WithArgs("x"), pb.ErrorReason_INVALID_ARGUMENT, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrUnknownDimension.WithArgs("x"), pb.ErrorReason_INVALID_ARGUMENT, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrDimensionRequired.WithArgs("x"), pb.ErrorReason_INVALID_ARGUMENT, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrInvalidDimension.WithArgs("x"), pb.ErrorReason_INVALID_ARGUMENT, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrTooManyDimensions.WithArgs(1), pb.ErrorReason_INVALID_ARGUMENT, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrInvalidDateRange.WithArgs(1), pb.ErrorReason_INVALID_ARGUMENT, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrInvalidWebhookURL, pb.ErrorReason_INVALID_ARGUMENT, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrTooManyWebhooks.WithArgs(1), pb.ErrorReason_INVALID_ARGUMENT, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrInvalidExportFilter.WithArgs("x"), pb.ErrorReason_INVALID_ARGUMENT, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrInvalidImportFile.WithArgs("x"), pb.ErrorReason_INVALID_ARGUMENT, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrInvalidImportRow.WithArgs("x"), pb.ErrorReason_INVALID_ARGUMENT, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrInvalidQueryArg.WithArgs("x"), pb.ErrorReason_INVALID_ARGUMENT, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrQueryTooDeep.WithArgs(1), pb.ErrorReason_INVALID_ARGUMENT, http.StatusBadRequest, codes.InvalidArgument},
+		{biz.ErrQueryTooComplex.WithArgs(2, 1), pb.ErrorReason_INVALID_ARGUMENT, http.StatusBadRequest, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.err.Key, func(t *testing.T) {
+			if _, ok := errorMessages[tt.err.Key]; !ok {
+				t.Fatalf("no message for %s", tt.err.Key)
+			}
+			// 经过包装的业务错误同样按错误原因转换
+			err := translateError(context.Background(), testLog, fmt.Errorf("wrapped: %w", tt.err))
+			e := kerrors.FromError(err)
+			if e.Reason != tt.reason.String() {
+				t.Errorf("reason = %s, want %s", e.Reason, tt.reason)
+			}
+			if int(e.Code) != tt.http {
+				t.Errorf("http code = %d, want %d", e.Code, tt.http)
+			}
+			if c := e.GRPCStatus().Code(); c != tt.grpc {
+				t.Errorf("grpc code = %s, want %s", c, tt.grpc)
+			}
+		})
+	}
+}
+
+func TestErrorMessagesComplete(t *testing.T) {
+	for key, msgs := range errorMessages {
+		for _, lang := range []string{langZh, langEn} {
+			if msgs[lang] == "" {
+				t.Errorf("%s has no %s message", key, lang)
+			}
+		}
+	}
+}
+
+func TestTranslateErrorLanguage(t *testing.T) {
+	tests := []struct {
+		name   string
+		header headerCarrier
+		err    error
+		want   string
+	}{
+		{name: "default zh", header: headerCarrier{}, err: biz.ErrReviewNotFound, want: "评价不存在"},
+		{name: "zh", header: headerCarrier{"Accept-Language": "zh-CN,zh;q=0.9"}, err: biz.ErrReviewNotFound, want: "评价不存在"},
+		{name: "en", header: headerCarrier{"Accept-Language": "en-US,en;q=0.9"}, err: biz.ErrReviewNotFound, want: "review not found"},
+		{name: "first supported", header: headerCarrier{"Accept-Language": "fr-FR, en;q=0.8, zh;q=0.5"}, err: biz.ErrReviewNotFound, want: "review not found"},
+		{name: "unsupported", header: headerCarrier{"Accept-Language": "fr-FR"}, err: biz.ErrReviewNotFound, want: "评价不存在"},
+		{name: "args zh", header: headerCarrier{}, err: biz.ErrOrderReviewed.WithArgs(int64(42)), want: "订单42已评价"},
+		{name: "args en", header: headerCarrier{"Accept-Language": "en"}, err: biz.ErrOrderReviewed.WithArgs(int64(42)), want: "order 42 has already been reviewed"},
+		{name: "unknown en", header: headerCarrier{"Accept-Language": "en"}, err: errors.New("dial tcp: connection refused"), want: "internal server error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := translateError(contextWithHeader(tt.header), testLog, tt.err)
+			if msg := kerrors.FromError(err).Message; msg != tt.want {
+				t.Errorf("message = %q, want %q", msg, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranslateErrorFallback(t *testing.T) {
+	// 未知错误不暴露内部细节 统一返回服务内部错误
+	err := translateError(context.Background(), testLog, errors.New("dial tcp 10.0.0.1:3306: connection refused"))
+	e := kerrors.FromError(err)
+	if e.Reason != pb.ErrorReason_INTERNAL_ERROR.String() || e.Code != http.StatusInternalServerError {
+		t.Fatalf("unknown error = %v, want INTERNAL_ERROR", e)
+	}
+	if e.Message != "服务内部错误" {
+		t.Fatalf("unknown error leaks message %q", e.Message)
+	}
+
+	// 没有对应API错误的错误原因同样返回服务内部错误
+	err = translateError(context.Background(), testLog, &biz.Error{Reason: pb.ErrorReason(999), Key: "review_not_found"})
+	if e := kerrors.FromError(err); e.Reason != pb.ErrorReason_INTERNAL_ERROR.String() {
+		t.Fatalf("unmapped reason = %s, want INTERNAL_ERROR", e.Reason)
+	}
+
+	// kratos错误(如参数校验失败)原样返回
+	validate := kerrors.BadRequest("VALIDATOR", "invalid field")
+	if err := translateError(context.Background(), testLog, validate); err != validate {
+		t.Fatalf("kratos error = %v, want %v", err, validate)
+	}
+}
+
+func TestErrorTranslator(t *testing.T) {
+	handler := ErrorTranslator(log.DefaultLogger)(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, biz.ErrReviewNotFound
+	})
+	_, err := handler(contextWithHeader(headerCarrier{"Accept-Language": "en"}), nil)
+	if !pb.IsNotFound(err) {
+		t.Fatalf("ErrorTranslator() = %v, want NOT_FOUND", err)
+	}
+	if msg := kerrors.FromError(err).Message; msg != "review not found" {
+		t.Fatalf("message = %q", msg)
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	if msg := ErrorMessage(biz.ErrInvalidImportRow.WithArgs("score"), langEn); msg != "invalid field or format: score" {
+		t.Fatalf("ErrorMessage() = %q", msg)
+	}
+	if msg := ErrorMessage(errors.New("boom"), langZh); msg != "服务内部错误" {
+		t.Fatalf("ErrorMessage() = %q", msg)
+	}
+}
@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	pb "review-service/api/review/v1"
+	"review-service/internal/biz"
+	"review-service/internal/conf"
+	"review-service/internal/data/model"
+	"review-service/internal/service/convert"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/location"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// GraphQL 聚合查询
+// 前端一次请求获取评价、商家回复、申诉、回复对话和店铺评分统计，不需要逐个调用REST接口
+// 请求经过HTTP服务的中间件，调用方身份、语言和超时与REST接口一致，返回的评价同样按调用方角色隐藏字段
+// 嵌套字段不逐条查询：列表中的评价ID先收集起来，第一次访问嵌套字段时一次批量查询所有评价
+
+const (
+	GraphQLPath      = "/v1/graphql"
+	OperationGraphQL = "/review.v1.Review/GraphQL"
+)
+
+const (
+	defaultGraphQLMaxDepth      = 6
+	defaultGraphQLMaxComplexity = 1000
+	maxGraphQLQueryLen          = 16 << 10 // 查询语句的最大长度
+)
+
+// graphQLRequest GraphQL请求 POST application/json
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// GraphQLServer GraphQL查询 只读，底层调用评价服务的用例
+type GraphQLServer struct {
+	svc           *ReviewService
+	schema        graphql.Schema
+	enabled       bool
+	maxDepth      int
+	maxComplexity int
+	log           *log.Helper
+}
+
+// NewGraphQLServer 构建GraphQL schema 未开启时HTTP服务不挂载
+func NewGraphQLServer(svc *ReviewService, c *conf.Server, logger log.Logger) (*GraphQLServer, error) {
+	g := &GraphQLServer{
+		svc:           svc,
+		enabled:       c.GetGraphql().GetEnabled(),
+		maxDepth:      int(c.GetGraphql().GetMaxDepth()),
+		maxComplexity: int(c.GetGraphql().GetMaxComplexity()),
+		log:           log.NewHelper(logger),
+	}
+	if g.maxDepth == 0 {
+		g.maxDepth = defaultGraphQLMaxDepth
+	}
+	if g.maxComplexity == 0 {
+		g.maxComplexity = defaultGraphQLMaxComplexity
+	}
+	schema, err := g.newSchema()
+	if err != nil {
+		return nil, err
+	}
+	g.schema = schema
+	return g, nil
+}
+
+// Enabled 是否开启GraphQL查询
+func (g *GraphQLServer) Enabled() bool {
+	return g.enabled
+}
+
+// Handle POST /v1/graphql 查询错误放在返回值的errors中，HTTP状态码为200
+func (g *GraphQLServer) Handle(ctx khttp.Context) error {
+	var req graphQLRequest
+	if err := ctx.Bind(&req); err != nil {
+		return err
+	}
+	khttp.SetOperation(ctx, OperationGraphQL)
+	h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return g.exec(ctx, req.(*graphQLRequest)), nil
+	})
+	out, err := h(ctx, &req)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+// exec 解析、校验、检查深度和复杂度后执行查询
+func (g *GraphQLServer) exec(ctx context.Context, req *graphQLRequest) *graphql.Result {
+	g.log.WithContext(ctx).Debugw("msg", "[service] GraphQL", "operation", req.OperationName, "query_len", len(req.Query))
+	if req.Query == "" || len(req.Query) > maxGraphQLQueryLen {
+		return g.errorResult(ctx, biz.ErrInvalidQueryArg.WithArgs("query"))
+	}
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{
+		Body: []byte(req.Query),
+		Name: "GraphQL request",
+	})})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	if vr := graphql.ValidateDocument(&g.schema, doc, nil); !vr.IsValid {
+		return &graphql.Result{Errors: vr.Errors}
+	}
+	if err := g.checkLimits(doc, req.OperationName, req.Variables); err != nil {
+		return g.errorResult(ctx, err)
+	}
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        g.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       context.WithValue(ctx, graphQLLoaderKey{}, newGraphQLLoader(g.svc)),
+	})
+}
+
+func (g *GraphQLServer) errorResult(ctx context.Context, err error) *graphql.Result {
+	e := g.translate(ctx, err)
+	return &graphql.Result{Errors: []gqlerrors.FormattedError{{
+		Message:    e.Error(),
+		Locations:  []location.SourceLocation{},
+		Extensions: e.Extensions(),
+	}}}
+}
+
+// translate 与REST接口一样转换成API错误 错误原因和状态码放在extensions中
+func (g *GraphQLServer) translate(ctx context.Context, err error) *graphQLError {
+	var e *kerrors.Error
+	if !errors.As(translateError(ctx, g.log, err), &e) {
+		e = pb.ErrorInternalError("%s", message("internal_error", langFromContext(ctx)))
+	}
+	return &graphQLError{e: e}
+}
+
+// graphQLError 实现 gqlerrors.ExtendedError
+type graphQLError struct {
+	e *kerrors.Error
+}
+
+func (e *graphQLError) Error() string {
+	return e.e.Message
+}
+
+func (e *graphQLError) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":   e.e.Code,
+		"reason": e.e.Reason,
+	}
+}
+
+type graphQLLoaderKey struct{}
+
+// graphQLLoader 每个请求一个 收集评价ID，第一次访问嵌套字段时批量查询，结果和错误按评价缓存
+// 查询按字段逐个执行，加锁只是为了防止以后改为并发执行时出错
+type graphQLLoader struct {
+	svc *ReviewService
+	mu  sync.Mutex
+
+	relation idBatch // 商家首条回复和申诉
+	replies  map[int64]*model.ReviewReplyInfo
+	appeals  map[int64]*model.ReviewAppealInfo
+
+	thread  idBatch // 回复对话
+	threads map[int64][]*model.ReviewReplyInfo
+
+	stats map[[2]int64]*graphQLStats // key为店铺和类目
+}
+
+type graphQLStats struct {
+	reply *pb.GetReviewStatsReply
+	err   error
+}
+
+func newGraphQLLoader(svc *ReviewService) *graphQLLoader {
+	return &graphQLLoader{
+		svc:     svc,
+		replies: make(map[int64]*model.ReviewReplyInfo),
+		appeals: make(map[int64]*model.ReviewAppealInfo),
+		threads: make(map[int64][]*model.ReviewReplyInfo),
+		stats:   make(map[[2]int64]*graphQLStats),
+	}
+}
+
+func loaderFromContext(ctx context.Context) *graphQLLoader {
+	return ctx.Value(graphQLLoaderKey{}).(*graphQLLoader)
+}
+
+// add 收集列表中的评价ID
+func (l *graphQLLoader) add(list []*pb.ReviewInfo) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, r := range list {
+		l.relation.add(r.ReviewID)
+		l.thread.add(r.ReviewID)
+	}
+}
+
+// reviewRelation 评价的商家首条回复和申诉
+func (l *graphQLLoader) reviewRelation(ctx context.Context, reviewID int64) (*model.ReviewReplyInfo, *model.ReviewAppealInfo, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if ids := l.relation.take(reviewID); len(ids) > 0 {
+		rel, err := l.svc.uc.GetReviewRelation(ctx, ids)
+		if err == nil {
+			for id, r := range rel.Replies {
+				l.replies[id] = r
+			}
+			for id, a := range rel.Appeals {
+				l.appeals[id] = a
+			}
+		}
+		l.relation.done(ids, err)
+	}
+	if err := l.relation.err(reviewID); err != nil {
+		return nil, nil, err
+	}
+	return l.replies[reviewID], l.appeals[reviewID], nil
+}
+
+// replyThread 评价的回复对话
+func (l *graphQLLoader) replyThread(ctx context.Context, reviewID int64) ([]*model.ReviewReplyInfo, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if ids := l.thread.take(reviewID); len(ids) > 0 {
+		threads, err := l.svc.uc.BatchListReplyThread(ctx, ids)
+		for id, list := range threads {
+			l.threads[id] = list
+		}
+		l.thread.done(ids, err)
+	}
+	if err := l.thread.err(reviewID); err != nil {
+		return nil, err
+	}
+	return l.threads[reviewID], nil
+}
+
+// reviewStats 店铺的评分统计 同一个店铺和类目只查询一次
+func (l *graphQLLoader) reviewStats(ctx context.Context, storeID, categoryID int64) (*pb.GetReviewStatsReply, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	key := [2]int64{storeID, categoryID}
+	s, ok := l.stats[key]
+	if !ok {
+		stats, err := l.svc.uc.GetReviewStats(ctx, storeID, categoryID)
+		s = &graphQLStats{err: err}
+		if err == nil {
+			s.reply = convert.Stats(stats)
+		}
+		l.stats[key] = s
+	}
+	return s.reply, s.err
+}
+
+// idBatch 待批量查询的ID
+type idBatch struct {
+	pending []int64
+	loaded  map[int64]error // 已查询的ID和查询的错误
+}
+
+func (b *idBatch) add(id int64) {
+	if _, ok := b.loaded[id]; !ok {
+		b.pending = append(b.pending, id)
+	}
+}
+
+// take 需要查询的ID id已查询过时返回nil，否则返回id和所有等待查询的ID
+func (b *idBatch) take(id int64) []int64 {
+	if _, ok := b.loaded[id]; ok {
+		return nil
+	}
+	seen := make(map[int64]struct{}, len(b.pending)+1)
+	ids := make([]int64, 0, len(b.pending)+1)
+	for _, v := range append(b.pending, id) {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		if _, ok := b.loaded[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		ids = append(ids, v)
+	}
+	b.pending = nil
+	return ids
+}
+
+func (b *idBatch) done(ids []int64, err error) {
+	if b.loaded == nil {
+		b.loaded = make(map[int64]error, len(ids))
+	}
+	for _, id := range ids {
+		b.loaded[id] = err
+	}
+}
+
+func (b *idBatch) err(id int64) error {
+	return b.loaded[id]
+}
+
+// operationDefinition 要执行的操作 只有一个操作时可以不指定名称，校验已经保证名称唯一
+func operationDefinition(doc *ast.Document, name string) *ast.OperationDefinition {
+	var found *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if name == "" {
+			if found != nil {
+				return nil
+			}
+			found = op
+			continue
+		}
+		if op.Name != nil && op.Name.Value == name {
+			return op
+		}
+	}
+	return found
+}
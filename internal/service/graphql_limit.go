@@ -0,0 +1,152 @@
+package service
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"review-service/internal/biz"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// 查询的深度和复杂度限制
+// 深度: 顶层字段为1，每嵌套一层加1
+// 复杂度: 每个字段计1，列表字段的子字段按返回的条数累乘，
+// 如 reviewsByStore(size: 20) { content reply { content } } 的复杂度为 1 + 20*(1+1+1) = 61
+// 内省查询(__schema、__type)只读取schema，不计入
+
+// graphQLListSize 没有size参数的列表字段返回的最大条数
+var graphQLListSize = map[string]func(*ReviewService) int{
+	"thread": func(s *ReviewService) int { return s.uc.RuntimeConfig().MaxThreadLength },
+}
+
+type queryLimiter struct {
+	svc       *ReviewService
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// checkLimits 执行前检查要执行的操作 超过限制时返回 ErrQueryTooDeep 或 ErrQueryTooComplex
+func (g *GraphQLServer) checkLimits(doc *ast.Document, operationName string, variables map[string]interface{}) error {
+	op := operationDefinition(doc, operationName)
+	if op == nil {
+		return biz.ErrInvalidQueryArg.WithArgs("operationName")
+	}
+	l := &queryLimiter{
+		svc:       g.svc,
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: make(map[string]interface{}, len(variables)),
+	}
+	// 没有传入的变量使用定义中的默认值
+	for _, v := range op.VariableDefinitions {
+		if d, ok := v.DefaultValue.(*ast.IntValue); ok {
+			if n, err := strconv.Atoi(d.Value); err == nil {
+				l.variables[v.Variable.Name.Value] = n
+			}
+		}
+	}
+	for k, v := range variables {
+		l.variables[k] = v
+	}
+	for _, def := range doc.Definitions {
+		if f, ok := def.(*ast.FragmentDefinition); ok {
+			l.fragments[f.Name.Value] = f
+		}
+	}
+	complexity, depth := l.cost(op.SelectionSet, 1, g.maxDepth)
+	if depth > g.maxDepth {
+		return biz.ErrQueryTooDeep.WithArgs(g.maxDepth)
+	}
+	if complexity > g.maxComplexity {
+		return biz.ErrQueryTooComplex.WithArgs(complexity, g.maxComplexity)
+	}
+	return nil
+}
+
+// cost 选择集的复杂度和最大深度 超过最大深度后不再继续展开
+func (l *queryLimiter) cost(set *ast.SelectionSet, depth, maxDepth int) (int, int) {
+	if set == nil {
+		return 0, depth - 1
+	}
+	if depth > maxDepth {
+		return 0, depth
+	}
+	complexity, deepest := 0, depth-1
+	add := func(c, d int) {
+		complexity = saturatingAdd(complexity, c)
+		if d > deepest {
+			deepest = d
+		}
+	}
+	for _, sel := range set.Selections {
+		switch s := sel.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name.Value, "__") {
+				continue
+			}
+			c, d := l.cost(s.SelectionSet, depth+1, maxDepth)
+			add(saturatingAdd(1, saturatingMul(c, l.listSize(s))), d)
+		case *ast.InlineFragment:
+			add(l.cost(s.SelectionSet, depth, maxDepth))
+		case *ast.FragmentSpread:
+			if f, ok := l.fragments[s.Name.Value]; ok {
+				add(l.cost(f.SelectionSet, depth, maxDepth))
+			}
+		}
+	}
+	return complexity, deepest
+}
+
+// listSize 列表字段返回的最大条数 普通字段为1
+func (l *queryLimiter) listSize(f *ast.Field) int {
+	for _, arg := range f.Arguments {
+		if arg.Name.Value != "size" {
+			continue
+		}
+		if n, ok := l.intValue(arg.Value); ok && n > 0 {
+			if n > maxGraphQLPageSize {
+				return maxGraphQLPageSize
+			}
+			return n
+		}
+	}
+	if _, ok := graphQLPageFields[f.Name.Value]; ok {
+		return defaultGraphQLPageSize
+	}
+	if size, ok := graphQLListSize[f.Name.Value]; ok {
+		return size(l.svc)
+	}
+	return 1
+}
+
+// intValue 参数的值 可以是常量或变量
+func (l *queryLimiter) intValue(v ast.Value) (int, bool) {
+	switch v := v.(type) {
+	case *ast.IntValue:
+		n, err := strconv.Atoi(v.Value)
+		return n, err == nil
+	case *ast.Variable:
+		switch n := l.variables[v.Name.Value].(type) {
+		case float64:
+			return int(n), true
+		case int:
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+func saturatingAdd(a, b int) int {
+	if a > math.MaxInt32-b {
+		return math.MaxInt32
+	}
+	return a + b
+}
+
+func saturatingMul(a, b int) int {
+	if b != 0 && a > math.MaxInt32/b {
+		return math.MaxInt32
+	}
+	return a * b
+}
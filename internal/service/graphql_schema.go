@@ -0,0 +1,363 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	pb "review-service/api/review/v1"
+	"review-service/internal/biz"
+	"review-service/internal/data/model"
+	"review-service/internal/service/convert"
+
+	"github.com/graphql-go/graphql"
+)
+
+// GraphQL schema
+// ID类型的字段在JSON中是字符串，避免前端解析64位ID时丢失精度
+//
+//	type Query {
+//	  review(reviewID: ID!): Review
+//	  reviewsByUser(userID: ID!, page: Int = 1, size: Int = 10): [Review!]!
+//	  reviewsByStore(storeID: ID!, page: Int = 1, size: Int = 10, sort: String = "", sentiment: String = ""): [Review!]!
+//	  reviewStats(storeID: ID!, categoryID: ID = 0): ReviewStats!
+//	}
+//	type Review { reviewID ... reply: Reply appeal: Appeal thread: [Reply!]! storeStats(categoryID: ID = 0): ReviewStats! }
+
+const (
+	defaultGraphQLPageSize = 10
+	maxGraphQLPageSize     = 50
+)
+
+// graphQLPageFields 分页查询的列表字段
+var graphQLPageFields = map[string]struct{}{
+	"reviewsByUser":  {},
+	"reviewsByStore": {},
+}
+
+func (g *GraphQLServer) newSchema() (graphql.Schema, error) {
+	id := graphql.NewNonNull(graphql.ID)
+	str := graphql.NewNonNull(graphql.String)
+	num := graphql.NewNonNull(graphql.Int)
+	float := graphql.NewNonNull(graphql.Float)
+	boolean := graphql.NewNonNull(graphql.Boolean)
+
+	replyType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Reply",
+		Description: "回复",
+		Fields: graphql.Fields{
+			"replyID":    {Type: id},
+			"reviewID":   {Type: id},
+			"parentID":   {Type: id, Description: "被回复的回复 0表示商家首条回复"},
+			"authorType": {Type: num, Description: "1商家 2用户"},
+			"storeID":    {Type: id},
+			"userID":     {Type: id},
+			"seq":        {Type: num, Description: "楼层"},
+			"content":    {Type: str},
+			"picInfo":    {Type: str},
+			"videoInfo":  {Type: str},
+			"createAt":   {Type: str},
+			"updateAt":   {Type: str},
+		},
+	})
+	appealType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Appeal",
+		Description: "商家申诉 只对商家和运营展示",
+		Fields: graphql.Fields{
+			"appealID": {Type: id},
+			"status":   {Type: num, Description: "10待审核 20申诉通过 30申诉驳回"},
+			"reason":   {Type: str},
+			"content":  {Type: str},
+			"createAt": {Type: str},
+			"updateAt": {Type: str},
+		},
+	})
+	dimensionStatsType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "DimensionStats",
+		Fields: graphql.Fields{
+			"code":    {Type: str},
+			"name":    {Type: str},
+			"average": {Type: float},
+			"count":   {Type: num},
+		},
+	})
+	statsType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "ReviewStats",
+		Description: "店铺的评分统计",
+		Fields: graphql.Fields{
+			"total":               {Type: num},
+			"averageScore":        {Type: float},
+			"averageServiceScore": {Type: float},
+			"averageExpressScore": {Type: float},
+			"dimensions":          {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(dimensionStatsType)))},
+		},
+	})
+	dimensionScoreType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "DimensionScore",
+		Fields: graphql.Fields{
+			"code":  {Type: str},
+			"score": {Type: num},
+		},
+	})
+	reviewType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Review",
+		Description: "评价 匿名评价的用户身份、运营信息按调用方角色隐藏",
+		Fields: graphql.Fields{
+			"reviewID":       {Type: id},
+			"userID":         {Type: id},
+			"orderID":        {Type: id},
+			"storeID":        {Type: id},
+			"skuID":          {Type: id},
+			"spuID":          {Type: id},
+			"categoryID":     {Type: id},
+			"score":          {Type: num},
+			"serviceScore":   {Type: num},
+			"expressScore":   {Type: num},
+			"content":        {Type: str},
+			"picInfo":        {Type: str},
+			"videoInfo":      {Type: str},
+			"tags":           {Type: str},
+			"status":         {Type: num},
+			"helpfulCount":   {Type: num},
+			"unhelpfulCount": {Type: num},
+			"anonymous":      {Type: boolean},
+			"nickname":       {Type: str},
+			"avatar":         {Type: str},
+			"hasMedia":       {Type: boolean},
+			"hasReply":       {Type: boolean},
+			"isDefault":      {Type: boolean},
+			"opReason":       {Type: str},
+			"opRemarks":      {Type: str},
+			"opUser":         {Type: str},
+			"createAt":       {Type: str},
+			"updateAt":       {Type: str},
+			"sentiment":      {Type: str, Description: "内容的情感倾向 positive、neutral、negative，分析完成前为空"},
+			"keywords": {
+				Type:        graphql.NewNonNull(graphql.NewList(str)),
+				Description: "内容的关键词",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if kw := p.Source.(*pb.ReviewInfo).Keywords; kw != nil {
+						return kw, nil
+					}
+					return []string{}, nil
+				},
+			},
+			"dimensionScores": {
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(dimensionScoreType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return dimensionScores(p.Source.(*pb.ReviewInfo).DimensionScores), nil
+				},
+			},
+			"reply": {
+				Type:        replyType,
+				Description: "商家的首条回复",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					reply, _, err := loaderFromContext(p.Context).reviewRelation(p.Context, p.Source.(*pb.ReviewInfo).ReviewID)
+					if err != nil {
+						return nil, g.translate(p.Context, err)
+					}
+					return convert.Reply(reply), nil
+				},
+			},
+			"appeal": {
+				Type: appealType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					review := p.Source.(*pb.ReviewInfo)
+					if !callerFromContext(p.Context).canSeeAppeal(review.StoreID) {
+						return nil, nil
+					}
+					_, appeal, err := loaderFromContext(p.Context).reviewRelation(p.Context, review.ReviewID)
+					if err != nil {
+						return nil, g.translate(p.Context, err)
+					}
+					return convert.Appeal(appeal), nil
+				},
+			},
+			"thread": {
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(replyType))),
+				Description: "回复对话 按楼层排序",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					review := p.Source.(*pb.ReviewInfo)
+					replies, err := loaderFromContext(p.Context).replyThread(p.Context, review.ReviewID)
+					if err != nil {
+						return nil, g.translate(p.Context, err)
+					}
+					list := convert.Replies(replies)
+					presentReplies(p.Context, review, list)
+					return list, nil
+				},
+			},
+			"storeStats": {
+				Type:        graphql.NewNonNull(statsType),
+				Description: "评价所属店铺的评分统计",
+				Args: graphql.FieldConfigArgument{
+					"categoryID": {Type: graphql.ID, DefaultValue: "0"},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					categoryID, err := idArg(p, "categoryID")
+					if err != nil {
+						return nil, g.translate(p.Context, err)
+					}
+					stats, err := loaderFromContext(p.Context).reviewStats(p.Context, p.Source.(*pb.ReviewInfo).StoreID, categoryID)
+					if err != nil {
+						return nil, g.translate(p.Context, err)
+					}
+					return stats, nil
+				},
+			},
+		},
+	})
+
+	reviewList := graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(reviewType)))
+	pageArgs := func(extra graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+		extra["page"] = &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1}
+		extra["size"] = &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultGraphQLPageSize}
+		return extra
+	}
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"review": {
+				Type: reviewType,
+				Args: graphql.FieldConfigArgument{
+					"reviewID": {Type: id},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					reviewID, err := idArg(p, "reviewID")
+					if err != nil {
+						return nil, g.translate(p.Context, err)
+					}
+					review, err := g.svc.uc.GetReview(p.Context, reviewID)
+					if err != nil {
+						return nil, g.translate(p.Context, err)
+					}
+					return g.reviews(p.Context, []*model.ReviewInfo{review})[0], nil
+				},
+			},
+			"reviewsByUser": {
+				Type:        reviewList,
+				Description: "用户的评价列表",
+				Args: pageArgs(graphql.FieldConfigArgument{
+					"userID": {Type: id},
+				}),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					userID, err := idArg(p, "userID")
+					if err != nil {
+						return nil, g.translate(p.Context, err)
+					}
+					page, size, err := pageArg(p)
+					if err != nil {
+						return nil, g.translate(p.Context, err)
+					}
+					reviews, err := g.svc.uc.ListReviewByUserID(p.Context, &biz.ListReviewParam{
+						UserID: userID,
+						Offset: (page - 1) * size,
+						Size:   size,
+					})
+					if err != nil {
+						return nil, g.translate(p.Context, err)
+					}
+					return g.reviews(p.Context, reviews), nil
+				},
+			},
+			"reviewsByStore": {
+				Type:        reviewList,
+				Description: "店铺的评价列表 sort为helpful时按有用数倒序，sentiment按内容的情感倾向筛选",
+				Args: pageArgs(graphql.FieldConfigArgument{
+					"storeID":   {Type: id},
+					"sort":      {Type: graphql.String, DefaultValue: ""},
+					"sentiment": {Type: graphql.String, DefaultValue: ""},
+				}),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					storeID, err := idArg(p, "storeID")
+					if err != nil {
+						return nil, g.translate(p.Context, err)
+					}
+					page, size, err := pageArg(p)
+					if err != nil {
+						return nil, g.translate(p.Context, err)
+					}
+					order, _ := p.Args["sort"].(string)
+					if order != "" && order != "helpful" {
+						return nil, g.translate(p.Context, biz.ErrInvalidQueryArg.WithArgs("sort"))
+					}
+					sentiment, _ := p.Args["sentiment"].(string)
+					reviews, err := g.svc.uc.ListReviewByStoreID(p.Context, storeID, page, size, order, sentiment)
+					if err != nil {
+						return nil, g.translate(p.Context, err)
+					}
+					return g.reviews(p.Context, convert.ReviewsFromES(reviews)), nil
+				},
+			},
+			"reviewStats": {
+				Type:        graphql.NewNonNull(statsType),
+				Description: "店铺的评分统计 categoryID为0时统计全部类目",
+				Args: graphql.FieldConfigArgument{
+					"storeID":    {Type: id},
+					"categoryID": {Type: graphql.ID, DefaultValue: "0"},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					storeID, err := idArg(p, "storeID")
+					if err != nil {
+						return nil, g.translate(p.Context, err)
+					}
+					categoryID, err := idArg(p, "categoryID")
+					if err != nil {
+						return nil, g.translate(p.Context, err)
+					}
+					stats, err := loaderFromContext(p.Context).reviewStats(p.Context, storeID, categoryID)
+					if err != nil {
+						return nil, g.translate(p.Context, err)
+					}
+					return stats, nil
+				},
+			},
+		},
+	})
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// reviews 评价转换成返回值并按调用方角色隐藏字段 收集评价ID用于批量查询嵌套字段
+func (g *GraphQLServer) reviews(ctx context.Context, reviews []*model.ReviewInfo) []*pb.ReviewInfo {
+	list := convert.Reviews(reviews, nil)
+	g.svc.presentReviews(ctx, list)
+	loaderFromContext(ctx).add(list)
+	return list
+}
+
+// idArg ID类型的参数 必须是非负整数
+func idArg(p graphql.ResolveParams, name string) (int64, error) {
+	s, _ := p.Args[name].(string)
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id < 0 {
+		return 0, biz.ErrInvalidQueryArg.WithArgs(name)
+	}
+	return id, nil
+}
+
+// pageArg 分页参数 size不能超过 maxGraphQLPageSize
+func pageArg(p graphql.ResolveParams) (int, int, error) {
+	page, _ := p.Args["page"].(int)
+	size, _ := p.Args["size"].(int)
+	if page < 1 {
+		return 0, 0, biz.ErrInvalidQueryArg.WithArgs("page")
+	}
+	if size < 1 || size > maxGraphQLPageSize {
+		return 0, 0, biz.ErrInvalidQueryArg.WithArgs("size")
+	}
+	return page, size, nil
+}
+
+// dimensionScores 各维度的评分 按维度编码排序
+func dimensionScores(m map[string]int32) []map[string]interface{} {
+	codes := make([]string, 0, len(m))
+	for code := range m {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	ret := make([]map[string]interface{}, 0, len(codes))
+	for _, code := range codes {
+		ret = append(ret, map[string]interface{}{"code": code, "score": m[code]})
+	}
+	return ret
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"review-service/internal/biz"
+	"review-service/internal/conf"
+	"review-service/internal/data/model"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// fakeGraphQLRepo 记录批量查询的调用 只实现GraphQL查询用到的方法
+type fakeGraphQLRepo struct {
+	biz.ReviewRepo
+	reviews []*model.ReviewInfo
+	err     error // 批量查询返回的错误
+
+	replyCalls  [][]int64
+	appealCalls [][]int64
+	threadCalls [][]int64
+}
+
+func (r *fakeGraphQLRepo) ListReviewByUserID(ctx context.Context, userID int64, offset, size int) ([]*model.ReviewInfo, error) {
+	return r.reviews, nil
+}
+
+func (r *fakeGraphQLRepo) BatchGetReply(ctx context.Context, reviewIDs []int64) ([]*model.ReviewReplyInfo, error) {
+	r.replyCalls = append(r.replyCalls, reviewIDs)
+	if r.err != nil {
+		return nil, r.err
+	}
+	list := make([]*model.ReviewReplyInfo, 0, len(reviewIDs))
+	for _, id := range reviewIDs {
+		list = append(list, &model.ReviewReplyInfo{ReplyID: id * 10, ReviewID: id, StoreID: 10, Content: "reply"})
+	}
+	return list, nil
+}
+
+func (r *fakeGraphQLRepo) BatchGetAppeal(ctx context.Context, reviewIDs []int64) ([]*model.ReviewAppealInfo, error) {
+	r.appealCalls = append(r.appealCalls, reviewIDs)
+	return nil, nil
+}
+
+func (r *fakeGraphQLRepo) BatchListThreadReply(ctx context.Context, reviewIDs []int64) ([]*model.ReviewReplyInfo, error) {
+	r.threadCalls = append(r.threadCalls, reviewIDs)
+	if r.err != nil {
+		return nil, r.err
+	}
+	return nil, nil
+}
+
+type fakeUserRepo struct{}
+
+func (fakeUserRepo) BatchGetUser(ctx context.Context, userIDs []int64) (map[int64]*biz.UserInfo, error) {
+	return map[int64]*biz.UserInfo{}, nil
+}
+
+func newTestGraphQLServer(t *testing.T, repo biz.ReviewRepo, c *conf.Server) *GraphQLServer {
+	t.Helper()
+	uc := biz.NewReviewUsecase(repo, fakeUserRepo{}, nil, nil, nil, nil, nil, nil, biz.NewRuntime(), log.DefaultLogger)
+	svc := NewReviewService(uc, nil, nil, nil, nil, log.DefaultLogger)
+	g, err := NewGraphQLServer(svc, c, log.DefaultLogger)
+	if err != nil {
+		t.Fatalf("NewGraphQLServer: %v", err)
+	}
+	return g
+}
+
+func TestGraphQLBatching(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		reviews int
+		reply   int // BatchGetReply的调用次数
+		appeal  int
+		thread  int
+	}{
+		{name: "no nested field", query: `{ reviewsByUser(userID: "1") { reviewID } }`, reviews: 3},
+		{name: "reply", query: `{ reviewsByUser(userID: "1") { reviewID reply { content } } }`, reviews: 3, reply: 1, appeal: 1},
+		{name: "reply and appeal share one batch", query: `{ reviewsByUser(userID: "1") { reply { content } appeal { content } } }`, reviews: 3, reply: 1, appeal: 1},
+		{name: "thread", query: `{ reviewsByUser(userID: "1") { thread { content } } }`, reviews: 3, thread: 1},
+		{name: "all nested fields", query: `{ reviewsByUser(userID: "1") { reply { content } appeal { content } thread { content } } }`, reviews: 5, reply: 1, appeal: 1, thread: 1},
+		{name: "empty list", query: `{ reviewsByUser(userID: "1") { reply { content } thread { content } } }`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeGraphQLRepo{}
+			want := make([]int64, 0, tt.reviews)
+			for i := 1; i <= tt.reviews; i++ {
+				repo.reviews = append(repo.reviews, &model.ReviewInfo{ReviewID: int64(i), UserID: 1, StoreID: 10})
+				want = append(want, int64(i))
+			}
+			g := newTestGraphQLServer(t, repo, &conf.Server{})
+			ctx := context.WithValue(context.Background(), callerKey{}, caller{Role: RoleStore, ID: 10})
+			res := g.exec(ctx, &graphQLRequest{Query: tt.query})
+			if len(res.Errors) > 0 {
+				t.Fatalf("errors = %v", res.Errors)
+			}
+			for _, c := range []struct {
+				name  string
+				calls [][]int64
+				want  int
+			}{
+				{"BatchGetReply", repo.replyCalls, tt.reply},
+				{"BatchGetAppeal", repo.appealCalls, tt.appeal},
+				{"BatchListThreadReply", repo.threadCalls, tt.thread},
+			} {
+				if len(c.calls) != c.want {
+					t.Fatalf("%s called %d times, want %d", c.name, len(c.calls), c.want)
+				}
+				if c.want > 0 && !reflect.DeepEqual(c.calls[0], want) {
+					t.Fatalf("%s ids = %v, want %v", c.name, c.calls[0], want)
+				}
+			}
+		})
+	}
+}
+
+func TestGraphQLBatchReply(t *testing.T) {
+	repo := &fakeGraphQLRepo{reviews: []*model.ReviewInfo{
+		{ReviewID: 1, UserID: 1, StoreID: 10},
+		{ReviewID: 2, UserID: 1, StoreID: 10},
+	}}
+	g := newTestGraphQLServer(t, repo, &conf.Server{})
+	res := g.exec(context.Background(), &graphQLRequest{Query: `{ reviewsByUser(userID: "1") { reviewID reply { replyID } } }`})
+	if len(res.Errors) > 0 {
+		t.Fatalf("errors = %v", res.Errors)
+	}
+	list := res.Data.(map[string]interface{})["reviewsByUser"].([]interface{})
+	for _, v := range list {
+		r := v.(map[string]interface{})
+		reply := r["reply"].(map[string]interface{})
+		// 每条评价拿到自己的回复
+		if r["reviewID"].(string)+"0" != reply["replyID"] {
+			t.Fatalf("review %v got reply %v", r["reviewID"], reply["replyID"])
+		}
+	}
+}
+
+func TestGraphQLBatchError(t *testing.T) {
+	repo := &fakeGraphQLRepo{
+		reviews: []*model.ReviewInfo{
+			{ReviewID: 1, UserID: 1, StoreID: 10},
+			{ReviewID: 2, UserID: 1, StoreID: 10},
+			{ReviewID: 3, UserID: 1, StoreID: 10},
+		},
+		err: errors.New("db down"),
+	}
+	g := newTestGraphQLServer(t, repo, &conf.Server{})
+	res := g.exec(context.Background(), &graphQLRequest{Query: `{ reviewsByUser(userID: "1") { reply { content } } }`})
+	// 批量查询失败只查询一次，错误返回给每条评价
+	if len(repo.replyCalls) != 1 {
+		t.Fatalf("BatchGetReply called %d times, want 1", len(repo.replyCalls))
+	}
+	if len(res.Errors) != len(repo.reviews) {
+		t.Fatalf("got %d errors, want %d", len(res.Errors), len(repo.reviews))
+	}
+}
+
+func TestIDBatch(t *testing.T) {
+	var b idBatch
+	b.add(1)
+	b.add(2)
+	b.add(1)
+	if ids := b.take(3); !reflect.DeepEqual(ids, []int64{1, 2, 3}) {
+		t.Fatalf("take(3) = %v, want [1 2 3]", ids)
+	}
+	b.done([]int64{1, 2, 3}, nil)
+	if ids := b.take(2); ids != nil {
+		t.Fatalf("take(2) after done = %v, want nil", ids)
+	}
+	// 已查询过的ID不再加入
+	b.add(2)
+	b.add(4)
+	if ids := b.take(5); !reflect.DeepEqual(ids, []int64{4, 5}) {
+		t.Fatalf("take(5) = %v, want [4 5]", ids)
+	}
+}
+
+func TestGraphQLLimits(t *testing.T) {
+	tests := []struct {
+		name  string
+		c     *conf.Server
+		query string
+		err   *biz.Error
+	}{
+		{
+			name:  "within limits",
+			c:     &conf.Server{},
+			query: `{ reviewsByUser(userID: "1", size: 20) { content reply { content } } }`,
+		},
+		{
+			name:  "too deep",
+			c:     &conf.Server{Graphql: &conf.Server_GraphQL{MaxDepth: 2}},
+			query: `{ reviewsByUser(userID: "1") { reply { content } } }`,
+			err:   biz.ErrQueryTooDeep,
+		},
+		{
+			name:  "too complex",
+			c:     &conf.Server{Graphql: &conf.Server_GraphQL{MaxComplexity: 60}},
+			query: `{ reviewsByUser(userID: "1", size: 20) { content reply { content } } }`,
+			err:   biz.ErrQueryTooComplex,
+		},
+		{
+			name:  "complexity at limit",
+			c:     &conf.Server{Graphql: &conf.Server_GraphQL{MaxComplexity: 61}},
+			query: `{ reviewsByUser(userID: "1", size: 20) { content reply { content } } }`,
+		},
+		{
+			name:  "thread uses max thread length",
+			c:     &conf.Server{Graphql: &conf.Server_GraphQL{MaxComplexity: 20}},
+			query: `{ review(reviewID: "1") { thread { content } } }`,
+			err:   biz.ErrQueryTooComplex,
+		},
+		{
+			name:  "fragment is expanded",
+			c:     &conf.Server{Graphql: &conf.Server_GraphQL{MaxDepth: 2}},
+			query: `{ reviewsByUser(userID: "1") { ...f } } fragment f on Review { reply { content } }`,
+			err:   biz.ErrQueryTooDeep,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGraphQLServer(t, &fakeGraphQLRepo{}, tt.c)
+			doc := mustParseGraphQL(t, tt.query)
+			err := g.checkLimits(doc, "", nil)
+			if tt.err == nil {
+				if err != nil {
+					t.Fatalf("checkLimits: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("checkLimits = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func mustParseGraphQL(t *testing.T, query string) *ast.Document {
+	t.Helper()
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(query)})})
+	if err != nil {
+		t.Fatalf("parse %q: %v", query, err)
+	}
+	return doc
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+
+	pb "review-service/api/review/v1"
+	"review-service/internal/biz"
+	"review-service/internal/data/model"
+	"review-service/internal/service/convert"
+)
+
+// 调用方角色
+const (
+	RoleUser     = "user"     // C端 用户
+	RoleStore    = "store"    // B端 商家
+	RoleOperator = "operator" // O端 运营
+)
+
+// anonymousNickname 匿名评价展示的昵称
+const anonymousNickname = "匿名用户"
+
+// caller 调用方身份 由 CallerAuth 从JWT中解析
+type caller struct {
+	Role string
+	ID   int64
+}
+
+// canSeeUser 匿名评价的用户身份只对评价的作者本人和运营可见
+func (c caller) canSeeUser(userID int64) bool {
+	return c.Role == RoleOperator || (c.Role == RoleUser && c.ID == userID)
+}
+
+// canSeeAppeal 申诉只对评价所属的商家和运营可见
+func (c caller) canSeeAppeal(storeID int64) bool {
+	return c.Role == RoleOperator || (c.Role == RoleStore && c.ID == storeID)
+}
+
+// presentReviews 统一处理返回给调用方的评价
+// 填充用户的昵称和头像，匿名评价按调用方的角色隐藏用户身份和能对应到用户的订单号，运营信息和申诉按角色隐藏
+func (s *ReviewService) presentReviews(ctx context.Context, list []*pb.ReviewInfo) {
+	c := callerFromContext(ctx)
+	for _, r := range list {
+		if c.Role != RoleOperator {
+			r.OpRemarks = ""
+			r.OpUser = ""
+		}
+		if !c.canSeeAppeal(r.StoreID) {
+			r.Appeal = nil
+		}
+	}
+	userIDs := make([]int64, 0, len(list))
+	for _, r := range list {
+		if !r.Anonymous || c.canSeeUser(r.UserID) {
+			userIDs = append(userIDs, r.UserID)
+		}
+	}
+	users, err := s.uc.BatchGetUser(ctx, userIDs)
+	if err != nil {
+		// 昵称头像获取失败不影响评价的展示
+		s.log.WithContext(ctx).Warnw("msg", "[service] BatchGetUser failed", "err", err)
+		users = map[int64]*biz.UserInfo{}
+	}
+	for _, r := range list {
+		if r.Anonymous && !c.canSeeUser(r.UserID) {
+			r.UserID = 0
+			r.OrderID = 0
+			r.Nickname = anonymousNickname
+			r.Avatar = ""
+			continue
+		}
+		if u, ok := users[r.UserID]; ok {
+			r.Nickname = u.Nickname
+			r.Avatar = u.Avatar
+		}
+	}
+}
+
+// presentReplies 匿名评价下，用户在回复对话中的身份同样需要隐藏
+func presentReplies(ctx context.Context, review *pb.ReviewInfo, list []*pb.ReplyInfo) {
+	if !review.Anonymous || callerFromContext(ctx).canSeeUser(review.UserID) {
+		return
+	}
+	for _, r := range list {
+		if r.AuthorType == biz.ReplyAuthorUser {
+			r.UserID = 0
+		}
+	}
+}
+
+// presentEvent 匿名评价的变更事件同样按调用方的角色隐藏用户身份
+func presentEvent(c caller, e *model.ReviewEvent) *pb.ReviewEvent {
+	ev := convert.Event(e)
+	if e.Anonymous == 1 && !c.canSeeUser(e.UserID) {
+		ev.UserID = 0
+	}
+	return ev
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"testing"
+
+	"review-service/internal/data/model"
+)
+
+func TestPresentEvent(t *testing.T) {
+	tests := []struct {
+		name      string
+		c         caller
+		anonymous int32
+		userID    int64
+	}{
+		{name: "public review", c: caller{Role: RoleStore, ID: 10}, userID: 1},
+		{name: "anonymous to store", c: caller{Role: RoleStore, ID: 10}, anonymous: 1, userID: 0},
+		{name: "anonymous to other user", c: caller{Role: RoleUser, ID: 2}, anonymous: 1, userID: 0},
+		{name: "anonymous to author", c: caller{Role: RoleUser, ID: 1}, anonymous: 1, userID: 1},
+		{name: "anonymous to operator", c: caller{Role: RoleOperator, ID: 3}, anonymous: 1, userID: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &model.ReviewEvent{ID: 1, ReviewID: 100, StoreID: 10, UserID: 1, Anonymous: tt.anonymous}
+			if ev := presentEvent(tt.c, e); ev.UserID != tt.userID {
+				t.Fatalf("UserID = %d, want %d", ev.UserID, tt.userID)
+			}
+		})
+	}
+}
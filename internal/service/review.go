@@ -1,35 +1,50 @@
 package service
 
 import (
+	"bytes"
 	"context"
-	"fmt"
 
 	pb "review-service/api/review/v1"
 
 	"review-service/internal/biz"
 	"review-service/internal/data/model"
+	"review-service/internal/service/convert"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
 )
 
 type ReviewService struct {
 	pb.UnimplementedReviewServer
-	uc *biz.ReviewUsecase
+	uc       *biz.ReviewUsecase
+	webhook  *biz.WebhookUsecase
+	export   *biz.ExportUsecase
+	importer *biz.ImportUsecase
+	watch    *biz.WatchUsecase
+	log      *log.Helper
 }
 
-func NewReviewService(uc *biz.ReviewUsecase) *ReviewService {
-	return &ReviewService{uc: uc}
+func NewReviewService(uc *biz.ReviewUsecase, webhook *biz.WebhookUsecase, export *biz.ExportUsecase, importer *biz.ImportUsecase, watch *biz.WatchUsecase, logger log.Logger) *ReviewService {
+	return &ReviewService{uc: uc, webhook: webhook, export: export, importer: importer, watch: watch, log: log.NewHelper(logger)}
 }
 
 // CreateReview 创建评价
+// 写操作的用户和店铺都以JWT中的调用方为准，请求中的id与调用方不一致时返回 ErrForbidden
 func (s *ReviewService) CreateReview(ctx context.Context, req *pb.CreateReviewRequest) (*pb.CreateReviewReply, error) {
-	fmt.Printf("[service] CreateReview req:%#v\n", req)
+	s.log.WithContext(ctx).Debugw("msg", "[service] CreateReview", "order_id", req.GetOrderID(), "user_id", req.GetUserID())
+	userID, err := callerFromContext(ctx).userID(req.GetUserID())
+	if err != nil {
+		return &pb.CreateReviewReply{}, err
+	}
 	// 判是否为匿名评价
 	var anonymous int32
 	if req.Anonymous {
 		anonymous = 1
 	}
-	review, err := s.uc.CreateReview(ctx, &model.ReviewInfo{
-		UserID:       req.UserID,
+	review := &model.ReviewInfo{
+		UserID:       userID,
 		OrderID:      req.OrderID,
+		StoreID:      req.StoreID,
 		Score:        req.Score,
 		ServiceScore: req.ServiceScore,
 		ExpressScore: req.ExpressScore,
@@ -38,9 +53,18 @@ func (s *ReviewService) CreateReview(ctx context.Context, req *pb.CreateReviewRe
 		VideoInfo:    req.VideoInfo,
 		Anonymous:    anonymous,
 		Status:       0,
-	})
+	}
+	// 类目和维度评分保存在ext_json中
+	if req.CategoryID > 0 || len(req.DimensionScores) > 0 {
+		if err := biz.SetReviewExt(review, &biz.ReviewExt{
+			CategoryID:      req.CategoryID,
+			DimensionScores: req.DimensionScores,
+		}); err != nil {
+			return &pb.CreateReviewReply{}, err
+		}
+	}
+	review, err = s.uc.CreateReview(ctx, review)
 	if err != nil {
-		// fmt.Printf("[service] CreateReview:err:%v\n", err)
 		return &pb.CreateReviewReply{}, err
 	}
 	return &pb.CreateReviewReply{ReviewID: review.ReviewID}, nil
@@ -48,54 +72,32 @@ func (s *ReviewService) CreateReview(ctx context.Context, req *pb.CreateReviewRe
 
 // GetReview 获取评价详情
 func (s *ReviewService) GetReview(ctx context.Context, req *pb.GetReviewRequest) (*pb.GetReviewReply, error) {
-	fmt.Printf("[service] GetReview req:%#v\n", req)
+	s.log.WithContext(ctx).Debugw("msg", "[service] GetReview", "review_id", req.GetReviewID())
 	review, err := s.uc.GetReview(ctx, req.GetReviewID())
 	if err != nil {
-		return &pb.GetReviewReply{}, nil
+		return &pb.GetReviewReply{}, err
 	}
-	return &pb.GetReviewReply{
-		Data: &pb.ReviewInfo{
-			ReviewID:     review.ReviewID,
-			UserID:       review.UserID,
-			OrderID:      review.OrderID,
-			Score:        review.Score,
-			ServiceScore: review.ServiceScore,
-			ExpressScore: review.ExpressScore,
-			Content:      review.Content,
-			PicInfo:      review.PicInfo,
-			VideoInfo:    review.VideoInfo,
-			Status:       review.Status,
-		},
-	}, err
+	list, err := s.reviewList(ctx, []*model.ReviewInfo{review})
+	if err != nil {
+		return &pb.GetReviewReply{}, err
+	}
+	return &pb.GetReviewReply{Data: list[0]}, nil
 }
 
 // ListReviewByUserID 获取用户评价列表
 func (s *ReviewService) ListReviewByUserID(ctx context.Context, req *pb.ListReviewByUserIDRequest) (*pb.ListReviewByUserIDReply, error) {
-	fmt.Printf("[service] ListReviewByUserID req:%#v\n", req)
+	s.log.WithContext(ctx).Debugw("msg", "[service] ListReviewByUserID", "user_id", req.GetUserID(), "page", req.GetPage(), "size", req.GetSize())
 	var offset int = (int(req.GetPage()) - 1) * 10
 	reviewInfo, err := s.uc.ListReviewByUserID(ctx, &biz.ListReviewParam{
 		UserID: req.GetUserID(),
 		Offset: offset,
 		Size:   int(req.GetSize()),
 	})
-	var list []*pb.ReviewInfo
-	for _, review := range reviewInfo {
-		data := &pb.ReviewInfo{
-			ReviewID:     review.ReviewID,
-			UserID:       review.UserID,
-			OrderID:      review.OrderID,
-			Score:        review.Score,
-			ServiceScore: review.ServiceScore,
-			ExpressScore: review.ExpressScore,
-			Content:      review.Content,
-			PicInfo:      review.PicInfo,
-			VideoInfo:    review.VideoInfo,
-			Status:       review.Status,
-		}
-		list = append(list, data)
+	if err != nil {
+		return &pb.ListReviewByUserIDReply{}, err
 	}
+	list, err := s.reviewList(ctx, reviewInfo)
 	if err != nil {
-		// fmt.Printf("[srevice] ListReviewByUserID,err:%v\n", err)
 		return &pb.ListReviewByUserIDReply{}, err
 	}
 	return &pb.ListReviewByUserIDReply{
@@ -103,43 +105,121 @@ func (s *ReviewService) ListReviewByUserID(ctx context.Context, req *pb.ListRevi
 	}, nil
 }
 
+// VoteReview 用户给评价投票
+func (s *ReviewService) VoteReview(ctx context.Context, req *pb.VoteReviewRequest) (*pb.VoteReviewReply, error) {
+	s.log.WithContext(ctx).Debugw("msg", "[service] VoteReview", "review_id", req.GetReviewID(), "user_id", req.GetUserID())
+	userID, err := callerFromContext(ctx).userID(req.GetUserID())
+	if err != nil {
+		return &pb.VoteReviewReply{}, err
+	}
+	ret, err := s.uc.VoteReview(ctx, &biz.VoteReviewParam{
+		ReviewID: req.GetReviewID(),
+		UserID:   userID,
+		Vote:     req.GetVote(),
+	})
+	if err != nil {
+		return &pb.VoteReviewReply{}, err
+	}
+	return &pb.VoteReviewReply{
+		Vote:           ret.Vote,
+		HelpfulCount:   ret.HelpfulCount,
+		UnhelpfulCount: ret.UnhelpfulCount,
+	}, nil
+}
+
+// ReportReview 用户举报评价
+func (s *ReviewService) ReportReview(ctx context.Context, req *pb.ReportReviewRequest) (*pb.ReportReviewReply, error) {
+	s.log.WithContext(ctx).Debugw("msg", "[service] ReportReview", "review_id", req.GetReviewID(), "user_id", req.GetUserID())
+	userID, err := callerFromContext(ctx).userID(req.GetUserID())
+	if err != nil {
+		return &pb.ReportReviewReply{}, err
+	}
+	report, err := s.uc.ReportReview(ctx, &biz.ReportReviewParam{
+		ReviewID: req.GetReviewID(),
+		UserID:   userID,
+		Reason:   req.GetReason(),
+		Content:  req.GetContent(),
+	})
+	if err != nil {
+		return &pb.ReportReviewReply{}, err
+	}
+	return &pb.ReportReviewReply{ReportID: report.ReportID}, nil
+}
+
 // review-B 商家端
 // ReplyReview 商家回复评价
 func (s *ReviewService) ReplyReview(ctx context.Context, req *pb.ReplyReviewRequest) (*pb.ReplyReviewReply, error) {
-	// defer func() {
-	// 	if err := recover(); err != nil {
-	// 		fmt.Printf("[service] recover panic,err:%v\n", err)
-	// 	}
-	// }()
-	fmt.Printf("[service] ReplyReview req:%#v\n", req)
+	s.log.WithContext(ctx).Debugw("msg", "[service] ReplyReview", "review_id", req.GetReviewID(), "store_id", req.GetStoreID())
+	storeID, err := callerFromContext(ctx).storeID(req.GetStoreID())
+	if err != nil {
+		return &pb.ReplyReviewReply{}, err
+	}
 	// 掉用biz层
 	reply, err := s.uc.CreateReply(ctx, &biz.ReplyReviewParam{
 		ReviewID:  req.ReviewID,
-		StoreID:   req.StoreID,
+		StoreID:   storeID,
 		Content:   req.Content,
 		PicInfo:   req.PicInfo,
 		VideoInfo: req.VideoInfo,
 	})
 	if err != nil {
-		fmt.Printf("[service] ReplyReview,err:%v\n", err)
 		return &pb.ReplyReviewReply{}, err
 	}
 	return &pb.ReplyReviewReply{RelpyID: reply.ReplyID}, nil
 }
 
+// UpdateReply 商家修改回复
+func (s *ReviewService) UpdateReply(ctx context.Context, req *pb.UpdateReplyRequest) (*pb.UpdateReplyReply, error) {
+	s.log.WithContext(ctx).Debugw("msg", "[service] UpdateReply", "reply_id", req.GetReplyID(), "store_id", req.GetStoreID())
+	storeID, err := callerFromContext(ctx).storeID(req.GetStoreID())
+	if err != nil {
+		return &pb.UpdateReplyReply{}, err
+	}
+	reply, err := s.uc.UpdateReply(ctx, &biz.UpdateReplyParam{
+		ReplyID:   req.GetReplyID(),
+		StoreID:   storeID,
+		Content:   req.GetContent(),
+		PicInfo:   req.GetPicInfo(),
+		VideoInfo: req.GetVideoInfo(),
+	})
+	if err != nil {
+		return &pb.UpdateReplyReply{}, err
+	}
+	return &pb.UpdateReplyReply{ReplyID: reply.ReplyID}, nil
+}
+
+// DeleteReply 商家删除回复
+func (s *ReviewService) DeleteReply(ctx context.Context, req *pb.DeleteReplyRequest) (*pb.DeleteReplyReply, error) {
+	s.log.WithContext(ctx).Debugw("msg", "[service] DeleteReply", "reply_id", req.GetReplyID(), "store_id", req.GetStoreID())
+	storeID, err := callerFromContext(ctx).storeID(req.GetStoreID())
+	if err != nil {
+		return &pb.DeleteReplyReply{}, err
+	}
+	if err := s.uc.DeleteReply(ctx, &biz.DeleteReplyParam{
+		ReplyID: req.GetReplyID(),
+		StoreID: storeID,
+	}); err != nil {
+		return &pb.DeleteReplyReply{}, err
+	}
+	return &pb.DeleteReplyReply{}, nil
+}
+
 // AppealReview 商家申诉评价
 func (s *ReviewService) AppealReview(ctx context.Context, req *pb.AppealReviewRequest) (*pb.AppealReviewReply, error) {
-	fmt.Printf("[service] AppealReview req:%#v\n", req)
+	s.log.WithContext(ctx).Debugw("msg", "[service] AppealReview", "review_id", req.GetReviewID(), "store_id", req.GetStoreID())
+	storeID, err := callerFromContext(ctx).storeID(req.GetStoreID())
+	if err != nil {
+		return &pb.AppealReviewReply{}, err
+	}
 	appeal, err := s.uc.AppealReview(ctx, &biz.AppealReviewParam{
 		ReviewID:  req.GetReviewID(),
-		StoreID:   req.GetStoreID(),
+		StoreID:   storeID,
 		Reason:    req.GetReason(),
 		Content:   req.GetContent(),
 		PicInfo:   req.GetPicInfo(),
 		VideoInfo: req.GetVideoInfo(),
 	})
 	if err != nil {
-		fmt.Printf("[service] AppealReview,err:%v\n", err)
 		return &pb.AppealReviewReply{}, err
 	}
 	return &pb.AppealReviewReply{AppealID: appeal.AppealID}, nil
@@ -148,7 +228,7 @@ func (s *ReviewService) AppealReview(ctx context.Context, req *pb.AppealReviewRe
 // review-C 运营端
 // AuditReview 运营审核用户评价
 func (s *ReviewService) AuditReview(ctx context.Context, req *pb.AuditReviewRequest) (*pb.AuditReviewReply, error) {
-	fmt.Printf("[service] AuditReview req:%#v\n", req)
+	s.log.WithContext(ctx).Debugw("msg", "[service] AuditReview", "review_id", req.GetReviewID(), "status", req.GetStatus())
 	if err := s.uc.AuditReview(ctx, &biz.AuditReviewParam{
 		ReviewID:  req.GetReviewID(),
 		OpUser:    req.GetOpUser(),
@@ -166,7 +246,7 @@ func (s *ReviewService) AuditReview(ctx context.Context, req *pb.AuditReviewRequ
 
 // AuditAppeal 运营审核商家申诉
 func (s *ReviewService) AuditAppeal(ctx context.Context, req *pb.AuditAppealRequest) (*pb.AuditAppealReply, error) {
-	fmt.Printf("[service] AuditAppeal req:%#v\n", req)
+	s.log.WithContext(ctx).Debugw("msg", "[service] AuditAppeal", "appeal_id", req.GetAppealID(), "status", req.GetStatus())
 	if err := s.uc.AuditAppeal(ctx, &biz.AuditAppealParam{
 		AppealID:  req.GetAppealID(),
 		ReviewID:  req.GetReviewID(),
@@ -179,27 +259,339 @@ func (s *ReviewService) AuditAppeal(ctx context.Context, req *pb.AuditAppealRequ
 	return &pb.AuditAppealReply{}, nil
 }
 
+// ListPendingReports 运营查询待处理的举报
+func (s *ReviewService) ListPendingReports(ctx context.Context, req *pb.ListPendingReportsRequest) (*pb.ListPendingReportsReply, error) {
+	s.log.WithContext(ctx).Debugw("msg", "[service] ListPendingReports", "review_id", req.GetReviewID(), "page", req.GetPage(), "size", req.GetSize())
+	if err := callerFromContext(ctx).operator(); err != nil {
+		return &pb.ListPendingReportsReply{}, err
+	}
+	list, err := s.uc.ListPendingReports(ctx, req.GetReviewID(), int(req.GetPage()), int(req.GetSize()))
+	if err != nil {
+		return &pb.ListPendingReportsReply{}, err
+	}
+	return &pb.ListPendingReportsReply{List: convert.Reports(list)}, nil
+}
+
 func (s *ReviewService) ListReviewByStoreID(ctx context.Context, req *pb.ListReviewByStoreIDRequest) (*pb.ListReviewByStoreIDReply, error) {
-	fmt.Printf("[service] ListReviewByStoreID req:%#v\n", req)
-	reviewList, err := s.uc.ListReviewByStoreID(ctx, req.StoreID, int(req.Page), int(req.Size))
+	s.log.WithContext(ctx).Debugw("msg", "[service] ListReviewByStoreID", "store_id", req.GetStoreID(), "page", req.GetPage(), "size", req.GetSize(), "sentiment", req.GetSentiment())
+	reviewList, err := s.uc.ListReviewByStoreID(ctx, req.StoreID, int(req.Page), int(req.Size), req.Sort, req.Sentiment)
 	if err != nil {
 		return &pb.ListReviewByStoreIDReply{}, err
 	}
 	// fromat
-	list := make([]*pb.ReviewInfo, 0, len(reviewList))
-	for _, r := range reviewList {
-		list = append(list, &pb.ReviewInfo{
-			ReviewID:     r.ReviewID,
-			UserID:       r.UserID,
-			OrderID:      r.OrderID,
-			Score:        r.Score,
-			ServiceScore: r.ServiceScore,
-			ExpressScore: r.ExpressScore,
-			Content:      r.Content,
-			PicInfo:      r.PicInfo,
-			VideoInfo:    r.VideoInfo,
-			Status:       r.Status,
-		})
+	list, err := s.reviewList(ctx, convert.ReviewsFromES(reviewList))
+	if err != nil {
+		return &pb.ListReviewByStoreIDReply{}, err
 	}
 	return &pb.ListReviewByStoreIDReply{List: list}, nil
 }
+
+// reviewList 评价列表转换成返回值 带上商家回复和申诉
+func (s *ReviewService) reviewList(ctx context.Context, reviews []*model.ReviewInfo) ([]*pb.ReviewInfo, error) {
+	ids := make([]int64, 0, len(reviews))
+	for _, r := range reviews {
+		ids = append(ids, r.ReviewID)
+	}
+	rel, err := s.uc.GetReviewRelation(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	list := convert.Reviews(reviews, rel)
+	s.presentReviews(ctx, list)
+	return list, nil
+}
+
+// ReplyThread 在评价的回复对话中追加回复
+func (s *ReviewService) ReplyThread(ctx context.Context, req *pb.ReplyThreadRequest) (*pb.ReplyThreadReply, error) {
+	s.log.WithContext(ctx).Debugw("msg", "[service] ReplyThread", "review_id", req.GetReviewID(), "parent_id", req.GetParentID())
+	authorType, storeID, userID, err := callerFromContext(ctx).author(req.GetAuthorType(), req.GetStoreID(), req.GetUserID())
+	if err != nil {
+		return &pb.ReplyThreadReply{}, err
+	}
+	reply, err := s.uc.ReplyThread(ctx, &biz.ThreadReplyParam{
+		ReviewID:   req.GetReviewID(),
+		ParentID:   req.GetParentID(),
+		AuthorType: authorType,
+		StoreID:    storeID,
+		UserID:     userID,
+		Content:    req.GetContent(),
+		PicInfo:    req.GetPicInfo(),
+		VideoInfo:  req.GetVideoInfo(),
+	})
+	if err != nil {
+		return &pb.ReplyThreadReply{}, err
+	}
+	return &pb.ReplyThreadReply{ReplyID: reply.ReplyID, Seq: reply.Seq}, nil
+}
+
+// ListReplyThread 获取评价的回复对话
+func (s *ReviewService) ListReplyThread(ctx context.Context, req *pb.ListReplyThreadRequest) (*pb.ListReplyThreadReply, error) {
+	s.log.WithContext(ctx).Debugw("msg", "[service] ListReplyThread", "review_id", req.GetReviewID())
+	review, err := s.uc.GetReview(ctx, req.GetReviewID())
+	if err != nil {
+		return &pb.ListReplyThreadReply{}, err
+	}
+	replies, err := s.uc.ListReplyThread(ctx, req.GetReviewID())
+	if err != nil {
+		return &pb.ListReplyThreadReply{}, err
+	}
+	list := convert.Replies(replies)
+	presentReplies(ctx, convert.Review(review, nil, nil), list)
+	return &pb.ListReplyThreadReply{List: list}, nil
+}
+
+// DeleteThreadReply 删除自己在对话中的回复
+func (s *ReviewService) DeleteThreadReply(ctx context.Context, req *pb.DeleteThreadReplyRequest) (*pb.DeleteThreadReplyReply, error) {
+	s.log.WithContext(ctx).Debugw("msg", "[service] DeleteThreadReply", "reply_id", req.GetReplyID())
+	authorType, storeID, userID, err := callerFromContext(ctx).author(req.GetAuthorType(), req.GetStoreID(), req.GetUserID())
+	if err != nil {
+		return &pb.DeleteThreadReplyReply{}, err
+	}
+	if err := s.uc.DeleteThreadReply(ctx, &biz.DeleteThreadReplyParam{
+		ReplyID:    req.GetReplyID(),
+		AuthorType: authorType,
+		StoreID:    storeID,
+		UserID:     userID,
+	}); err != nil {
+		return &pb.DeleteThreadReplyReply{}, err
+	}
+	return &pb.DeleteThreadReplyReply{}, nil
+}
+
+// GetRuntimeConfig 运营查看当前生效的运行时配置
+func (s *ReviewService) GetRuntimeConfig(ctx context.Context, req *pb.GetRuntimeConfigRequest) (*pb.GetRuntimeConfigReply, error) {
+	s.log.WithContext(ctx).Debugw("msg", "[service] GetRuntimeConfig")
+	if err := callerFromContext(ctx).operator(); err != nil {
+		return &pb.GetRuntimeConfigReply{}, err
+	}
+	c := s.uc.RuntimeConfig()
+	return &pb.GetRuntimeConfigReply{
+		ListCacheTTL:          c.ListCacheTTL.String(),
+		DashboardCacheTTL:     c.DashboardCacheTTL.String(),
+		CreateReviewPerMinute: int32(c.CreateReviewPerMinute),
+		ReportHideThreshold:   int32(c.ReportHideThreshold),
+		MaxThreadLength:       int32(c.MaxThreadLength),
+		ReplyBlockedWords:     c.ReplyBlockedWords,
+		Features:              c.Features,
+		UpdatedAt:             c.UpdatedAt.Unix(),
+	}, nil
+}
+
+// SaveRatingDimensions 运营设置类目的评分维度
+func (s *ReviewService) SaveRatingDimensions(ctx context.Context, req *pb.SaveRatingDimensionsRequest) (*pb.SaveRatingDimensionsReply, error) {
+	s.log.WithContext(ctx).Debugw("msg", "[service] SaveRatingDimensions", "category_id", req.GetCategoryID(), "op_user", req.GetOpUser())
+	dims := make([]*model.ReviewDimension, 0, len(req.GetDimensions()))
+	for _, d := range req.GetDimensions() {
+		var required int32
+		if d.GetRequired() {
+			required = 1
+		}
+		dims = append(dims, &model.ReviewDimension{
+			Code:     d.GetCode(),
+			Name:     d.GetName(),
+			Required: required,
+			Sort:     d.GetSort(),
+		})
+	}
+	if err := s.uc.SaveRatingDimensions(ctx, req.GetCategoryID(), dims, req.GetOpUser()); err != nil {
+		return &pb.SaveRatingDimensionsReply{}, err
+	}
+	return &pb.SaveRatingDimensionsReply{}, nil
+}
+
+// ListRatingDimensions 查看类目的评分维度
+func (s *ReviewService) ListRatingDimensions(ctx context.Context, req *pb.ListRatingDimensionsRequest) (*pb.ListRatingDimensionsReply, error) {
+	s.log.WithContext(ctx).Debugw("msg", "[service] ListRatingDimensions", "category_id", req.GetCategoryID())
+	dims, err := s.uc.ListRatingDimensions(ctx, req.GetCategoryID())
+	if err != nil {
+		return &pb.ListRatingDimensionsReply{}, err
+	}
+	return &pb.ListRatingDimensionsReply{List: convert.Dimensions(dims)}, nil
+}
+
+// GetReviewStats 店铺的评分统计
+func (s *ReviewService) GetReviewStats(ctx context.Context, req *pb.GetReviewStatsRequest) (*pb.GetReviewStatsReply, error) {
+	s.log.WithContext(ctx).Debugw("msg", "[service] GetReviewStats", "store_id", req.GetStoreID(), "category_id", req.GetCategoryID())
+	stats, err := s.uc.GetReviewStats(ctx, req.GetStoreID(), req.GetCategoryID())
+	if err != nil {
+		return &pb.GetReviewStatsReply{}, err
+	}
+	return convert.Stats(stats), nil
+}
+
+// GetStoreReviewDashboard 店铺评价看板
+func (s *ReviewService) GetStoreReviewDashboard(ctx context.Context, req *pb.GetStoreReviewDashboardRequest) (*pb.GetStoreReviewDashboardReply, error) {
+	s.log.WithContext(ctx).Debugw("msg", "[service] GetStoreReviewDashboard", "store_id", req.GetStoreID(), "start", req.GetStartDate(), "end", req.GetEndDate())
+	points, err := s.uc.GetStoreDashboard(ctx, req.GetStoreID(), req.GetStartDate(), req.GetEndDate(), req.GetInterval())
+	if err != nil {
+		return &pb.GetStoreReviewDashboardReply{}, err
+	}
+	return convert.Dashboard(points), nil
+}
+
+// GetStoreComplaintKeywords 店铺的吐槽关键词
+func (s *ReviewService) GetStoreComplaintKeywords(ctx context.Context, req *pb.GetStoreComplaintKeywordsRequest) (*pb.GetStoreComplaintKeywordsReply, error) {
+	s.log.WithContext(ctx).Debugw("msg", "[service] GetStoreComplaintKeywords", "store_id", req.GetStoreID(), "size", req.GetSize())
+	keywords, err := s.uc.GetComplaintKeywords(ctx, req.GetStoreID(), int(req.GetSize()))
+	if err != nil {
+		return &pb.GetStoreComplaintKeywordsReply{}, err
+	}
+	return convert.Keywords(keywords), nil
+}
+
+// SaveStoreWebhook 新增或修改店铺的webhook
+// webhook接口的店铺以调用方为准，商家只能管理自己店铺的webhook，运营可以管理任意店铺的webhook
+func (s *ReviewService) SaveStoreWebhook(ctx context.Context, req *pb.SaveStoreWebhookRequest) (*pb.SaveStoreWebhookReply, error) {
+	s.log.WithContext(ctx).Debugw("msg", "[service] SaveStoreWebhook", "id", req.GetId(), "store_id", req.GetStoreID())
+	storeID, err := callerFromContext(ctx).storeScope(req.GetStoreID())
+	if err != nil {
+		return &pb.SaveStoreWebhookReply{}, err
+	}
+	var enabled int32 = 1
+	if req.GetDisabled() {
+		enabled = 0
+	}
+	hook, err := s.webhook.SaveWebhook(ctx, &model.StoreWebhook{
+		ID:       req.GetId(),
+		StoreID:  storeID,
+		URL:      req.GetUrl(),
+		Secret:   req.GetSecret(),
+		MaxScore: req.GetMaxScore(),
+		Enabled:  enabled,
+	})
+	if err != nil {
+		return &pb.SaveStoreWebhookReply{}, err
+	}
+	return &pb.SaveStoreWebhookReply{Webhook: convert.Webhook(hook), Secret: hook.Secret}, nil
+}
+
+// ListStoreWebhooks 店铺的webhook
+func (s *ReviewService) ListStoreWebhooks(ctx context.Context, req *pb.ListStoreWebhooksRequest) (*pb.ListStoreWebhooksReply, error) {
+	s.log.WithContext(ctx).Debugw("msg", "[service] ListStoreWebhooks", "store_id", req.GetStoreID())
+	storeID, err := callerFromContext(ctx).storeScope(req.GetStoreID())
+	if err != nil {
+		return &pb.ListStoreWebhooksReply{}, err
+	}
+	hooks, err := s.webhook.ListWebhooks(ctx, storeID)
+	if err != nil {
+		return &pb.ListStoreWebhooksReply{}, err
+	}
+	return &pb.ListStoreWebhooksReply{List: convert.Webhooks(hooks)}, nil
+}
+
+// DeleteStoreWebhook 删除店铺的webhook
+func (s *ReviewService) DeleteStoreWebhook(ctx context.Context, req *pb.DeleteStoreWebhookRequest) (*pb.DeleteStoreWebhookReply, error) {
+	s.log.WithContext(ctx).Debugw("msg", "[service] DeleteStoreWebhook", "id", req.GetId(), "store_id", req.GetStoreID())
+	storeID, err := callerFromContext(ctx).storeScope(req.GetStoreID())
+	if err != nil {
+		return &pb.DeleteStoreWebhookReply{}, err
+	}
+	if err := s.webhook.DeleteWebhook(ctx, req.GetId(), storeID); err != nil {
+		return &pb.DeleteStoreWebhookReply{}, err
+	}
+	return &pb.DeleteStoreWebhookReply{}, nil
+}
+
+// TestStoreWebhook 向webhook推送一次测试事件
+func (s *ReviewService) TestStoreWebhook(ctx context.Context, req *pb.TestStoreWebhookRequest) (*pb.TestStoreWebhookReply, error) {
+	s.log.WithContext(ctx).Debugw("msg", "[service] TestStoreWebhook", "id", req.GetId(), "store_id", req.GetStoreID())
+	storeID, err := callerFromContext(ctx).storeScope(req.GetStoreID())
+	if err != nil {
+		return &pb.TestStoreWebhookReply{}, err
+	}
+	ret, err := s.webhook.TestWebhook(ctx, req.GetId(), storeID)
+	if err != nil {
+		return &pb.TestStoreWebhookReply{}, err
+	}
+	return &pb.TestStoreWebhookReply{
+		Success:    ret.Error == "",
+		StatusCode: int32(ret.StatusCode),
+		Error:      ret.Error,
+	}, nil
+}
+
+// ExportReviews 提交评价导出任务
+// 商家只能导出自己店铺的评价，只有运营可以导出全部店铺
+func (s *ReviewService) ExportReviews(ctx context.Context, req *pb.ExportReviewsRequest) (*pb.ExportReviewsReply, error) {
+	s.log.WithContext(ctx).Debugw("msg", "[service] ExportReviews", "store_id", req.GetStoreID(), "format", req.GetFormat(), "op_user", req.GetOpUser())
+	storeID, err := callerFromContext(ctx).storeScope(req.GetStoreID())
+	if err != nil {
+		return &pb.ExportReviewsReply{}, err
+	}
+	job, err := s.export.SubmitExport(ctx, &biz.ExportFilter{
+		StoreID:   storeID,
+		StartDate: req.GetStartDate(),
+		EndDate:   req.GetEndDate(),
+		Statuses:  req.GetStatuses(),
+		Scores:    req.GetScores(),
+	}, req.GetFormat(), req.GetOpUser())
+	if err != nil {
+		return &pb.ExportReviewsReply{}, err
+	}
+	return &pb.ExportReviewsReply{JobID: job.JobID}, nil
+}
+
+// GetExportJob 查看导出任务 商家只能查看自己店铺的任务
+func (s *ReviewService) GetExportJob(ctx context.Context, req *pb.GetExportJobRequest) (*pb.GetExportJobReply, error) {
+	s.log.WithContext(ctx).Debugw("msg", "[service] GetExportJob", "job_id", req.GetJobID(), "store_id", req.GetStoreID())
+	storeID, err := callerFromContext(ctx).storeScope(req.GetStoreID())
+	if err != nil {
+		return &pb.GetExportJobReply{}, err
+	}
+	job, err := s.export.GetExportJob(ctx, req.GetJobID(), storeID)
+	if err != nil {
+		return &pb.GetExportJobReply{}, err
+	}
+	return convert.ExportJob(job), nil
+}
+
+// ImportReviews 运营从其它平台导入历史评价
+func (s *ReviewService) ImportReviews(ctx context.Context, req *pb.ImportReviewsRequest) (*pb.ImportReviewsReply, error) {
+	s.log.WithContext(ctx).Debugw("msg", "[service] ImportReviews", "source", req.GetSource(), "format", req.GetFormat(), "size", len(req.GetData()), "op_user", req.GetOpUser())
+	if err := callerFromContext(ctx).operator(); err != nil {
+		return &pb.ImportReviewsReply{}, err
+	}
+	report, err := s.importer.ImportReviews(ctx, req.GetFormat(), bytes.NewReader(req.GetData()), &biz.ImportOptions{
+		Source: req.GetSource(),
+		Status: req.GetStatus(),
+		DryRun: req.GetDryRun(),
+		OpUser: req.GetOpUser(),
+	})
+	if err != nil {
+		return &pb.ImportReviewsReply{}, err
+	}
+	lang := langFromContext(ctx)
+	return convert.ImportReport(report, func(err error) string { return ErrorMessage(err, lang) }), nil
+}
+
+// WatchReviews 订阅评价变更事件
+// 流式接口不经过unary中间件，在这里校验参数并转换错误
+// 商家只能订阅自己店铺的事件，只有运营可以订阅全部店铺或按用户订阅，匿名评价的事件按调用方的角色隐藏用户身份
+func (s *ReviewService) WatchReviews(req *pb.WatchReviewsRequest, stream pb.Review_WatchReviewsServer) error {
+	ctx := stream.Context()
+	s.log.WithContext(ctx).Debugw("msg", "[service] WatchReviews", "store_id", req.GetStoreID(), "user_id", req.GetUserID(), "after_seq", req.GetAfterSeq(), "from_now", req.GetFromNow())
+	if err := req.Validate(); err != nil {
+		return kerrors.BadRequest("VALIDATOR", err.Error()).WithCause(err)
+	}
+	c := callerFromContext(ctx)
+	if req.GetUserID() > 0 && c.Role != RoleOperator {
+		return translateError(ctx, s.log, biz.ErrForbidden)
+	}
+	storeID, err := c.storeScope(req.GetStoreID())
+	if err != nil {
+		return translateError(ctx, s.log, err)
+	}
+	err = s.watch.WatchReviews(ctx, &biz.WatchFilter{
+		StoreID:  storeID,
+		UserID:   req.GetUserID(),
+		Types:    req.GetTypes(),
+		AfterSeq: req.GetAfterSeq(),
+		FromNow:  req.GetFromNow(),
+	}, func(e *model.ReviewEvent) error {
+		return stream.Send(presentEvent(c, e))
+	})
+	if err != nil {
+		return translateError(ctx, s.log, err)
+	}
+	return nil
+}
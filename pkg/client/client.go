@@ -0,0 +1,152 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	v1 "review-service/api/review/v1"
+
+	consul "github.com/go-kratos/kratos/contrib/registry/consul/v2"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/recovery"
+	"github.com/go-kratos/kratos/v2/middleware/tracing"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/hashicorp/consul/api"
+	ggrpc "google.golang.org/grpc"
+)
+
+// 评价服务的gRPC客户端 供其它服务调用
+// 通过consul发现服务实例，负载均衡使用kratos默认的wrr(可通过 selector.SetGlobalSelector 替换)
+// 查询类的接口在实例不可用时按退避时间重试，写接口不重试，避免重复创建评价、回复等
+//
+//	c, err := client.New(ctx, client.WithConsul("127.0.0.1:8500"))
+//	if err != nil { ... }
+//	defer c.Close()
+//	reply, err := c.GetReview(ctx, &v1.GetReviewRequest{ReviewID: id})
+//	if client.IsNotFound(err) { ... }
+
+// ServiceName 评价服务在consul中注册的服务名
+const ServiceName = "review-service"
+
+const (
+	defaultTimeout = 3 * time.Second
+	defaultRetries = 2
+	defaultBackoff = 100 * time.Millisecond
+)
+
+type options struct {
+	endpoint   string
+	discovery  registry.Discovery
+	consulAddr string
+	timeout    time.Duration
+	retries    int
+	backoff    time.Duration
+	middleware []middleware.Middleware
+	dialOpts   []ggrpc.DialOption
+}
+
+// Option 客户端选项
+type Option func(*options)
+
+// WithEndpoint 服务地址 默认 discovery:///review-service，也可以是 127.0.0.1:9000 这样的直连地址
+func WithEndpoint(endpoint string) Option {
+	return func(o *options) { o.endpoint = endpoint }
+}
+
+// WithDiscovery 服务发现 设置后忽略 WithConsul
+func WithDiscovery(d registry.Discovery) Option {
+	return func(o *options) { o.discovery = d }
+}
+
+// WithConsul consul的地址 如 127.0.0.1:8500
+func WithConsul(address string) Option {
+	return func(o *options) { o.consulAddr = address }
+}
+
+// WithTimeout 每次调用的超时时间(包含重试) 默认3s，调用方的context有更早的截止时间时以context为准
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.timeout = timeout }
+}
+
+// WithRetry 查询类接口的最大重试次数和首次重试前的等待时间 之后每次等待时间翻倍 retries为0时不重试
+func WithRetry(retries int, backoff time.Duration) Option {
+	return func(o *options) {
+		o.retries = retries
+		o.backoff = backoff
+	}
+}
+
+// WithMiddleware 额外的客户端中间件 在重试之前执行
+func WithMiddleware(m ...middleware.Middleware) Option {
+	return func(o *options) { o.middleware = append(o.middleware, m...) }
+}
+
+// WithDialOptions 额外的grpc连接选项 如TLS
+func WithDialOptions(opts ...ggrpc.DialOption) Option {
+	return func(o *options) { o.dialOpts = append(o.dialOpts, opts...) }
+}
+
+// Client 评价服务的客户端
+type Client struct {
+	v1.ReviewClient
+	conn *ggrpc.ClientConn
+}
+
+// New 创建客户端 使用完后需要调用Close
+func New(ctx context.Context, opts ...Option) (*Client, error) {
+	o := &options{
+		endpoint: "discovery:///" + ServiceName,
+		timeout:  defaultTimeout,
+		retries:  defaultRetries,
+		backoff:  defaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.discovery == nil && o.consulAddr != "" {
+		d, err := NewConsulDiscovery(o.consulAddr)
+		if err != nil {
+			return nil, err
+		}
+		o.discovery = d
+	}
+	ms := append([]middleware.Middleware{
+		recovery.Recovery(),
+		tracing.Client(),
+	}, o.middleware...)
+	ms = append(ms, Retry(o.retries, o.backoff))
+	dialOpts := []grpc.ClientOption{
+		grpc.WithEndpoint(o.endpoint),
+		grpc.WithTimeout(o.timeout),
+		grpc.WithMiddleware(ms...),
+		grpc.WithOptions(o.dialOpts...),
+	}
+	if o.discovery != nil {
+		dialOpts = append(dialOpts, grpc.WithDiscovery(o.discovery))
+	}
+	conn, err := grpc.DialInsecure(ctx, dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		ReviewClient: v1.NewReviewClient(conn),
+		conn:         conn,
+	}, nil
+}
+
+// NewConsulDiscovery 基于consul的服务发现
+func NewConsulDiscovery(address string) (registry.Discovery, error) {
+	c := api.DefaultConfig()
+	c.Address = address
+	client, err := api.NewClient(c)
+	if err != nil {
+		return nil, err
+	}
+	return consul.New(client), nil
+}
+
+// Close 关闭连接
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,282 @@
+package client
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	v1 "review-service/api/review/v1"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/registry"
+	"google.golang.org/grpc"
+)
+
+// fakeReview 按预设的错误依次返回 错误用完后成功
+type fakeReview struct {
+	v1.UnimplementedReviewServer
+	mu    sync.Mutex
+	errs  []error
+	calls atomic.Int32
+}
+
+func (s *fakeReview) next() error {
+	s.calls.Add(1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.errs) == 0 {
+		return nil
+	}
+	err := s.errs[0]
+	s.errs = s.errs[1:]
+	return err
+}
+
+func (s *fakeReview) GetReview(_ context.Context, req *v1.GetReviewRequest) (*v1.GetReviewReply, error) {
+	if err := s.next(); err != nil {
+		return nil, err
+	}
+	return &v1.GetReviewReply{Data: &v1.ReviewInfo{ReviewID: req.ReviewID}}, nil
+}
+
+func (s *fakeReview) CreateReview(context.Context, *v1.CreateReviewRequest) (*v1.CreateReviewReply, error) {
+	if err := s.next(); err != nil {
+		return nil, err
+	}
+	return &v1.CreateReviewReply{ReviewID: 1}, nil
+}
+
+// startServer 在本地端口启动评价服务 返回服务地址
+func startServer(t *testing.T, srv v1.ReviewServer) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	v1.RegisterReviewServer(s, srv)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	return lis.Addr().String()
+}
+
+// fakeDiscovery 固定返回一组实例的服务发现
+type fakeDiscovery struct {
+	instances []*registry.ServiceInstance
+}
+
+func (d *fakeDiscovery) GetService(context.Context, string) ([]*registry.ServiceInstance, error) {
+	return d.instances, nil
+}
+
+func (d *fakeDiscovery) Watch(ctx context.Context, name string) (registry.Watcher, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &fakeWatcher{d: d, name: name, ctx: ctx, cancel: cancel}, nil
+}
+
+// fakeWatcher 第一次返回全部实例 之后阻塞到Stop
+type fakeWatcher struct {
+	d      *fakeDiscovery
+	name   string
+	ctx    context.Context
+	cancel context.CancelFunc
+	sent   bool
+}
+
+func (w *fakeWatcher) Next() ([]*registry.ServiceInstance, error) {
+	if !w.sent {
+		w.sent = true
+		var ret []*registry.ServiceInstance
+		for _, in := range w.d.instances {
+			if in.Name == w.name {
+				ret = append(ret, in)
+			}
+		}
+		return ret, nil
+	}
+	<-w.ctx.Done()
+	return nil, w.ctx.Err()
+}
+
+func (w *fakeWatcher) Stop() error {
+	w.cancel()
+	return nil
+}
+
+func newTestClient(t *testing.T, addr string, opts ...Option) *Client {
+	c, err := New(context.Background(), append([]Option{WithEndpoint(addr)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func TestDiscovery(t *testing.T) {
+	srv := &fakeReview{}
+	addr := startServer(t, srv)
+	d := &fakeDiscovery{instances: []*registry.ServiceInstance{
+		{ID: "1", Name: ServiceName, Endpoints: []string{"grpc://" + addr}},
+		{ID: "2", Name: "other-service", Endpoints: []string{"grpc://127.0.0.1:1"}},
+	}}
+	c, err := New(context.Background(), WithDiscovery(d), WithConsul("127.0.0.1:1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	reply, err := c.GetReview(context.Background(), &v1.GetReviewRequest{ReviewID: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Data.ReviewID != 7 {
+		t.Fatalf("GetReview() = %v", reply)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	unavailable := errors.ServiceUnavailable("UNAVAILABLE", "instance is shutting down")
+	notFound := v1.ErrorNotFound("评价不存在")
+	tests := []struct {
+		name    string
+		write   bool
+		errs    []error
+		retries int
+		calls   int32
+		wantErr func(error) bool
+	}{
+		{name: "read retried until success", errs: []error{unavailable, unavailable}, retries: 2, calls: 3},
+		{name: "read retries exhausted", errs: []error{unavailable, unavailable, unavailable}, retries: 2, calls: 3, wantErr: IsRetryable},
+		{name: "read not retried on business error", errs: []error{notFound}, retries: 2, calls: 1, wantErr: IsNotFound},
+		{name: "read not retried when disabled", errs: []error{unavailable}, retries: 0, calls: 1, wantErr: IsRetryable},
+		{name: "write never retried", write: true, errs: []error{unavailable}, retries: 2, calls: 1, wantErr: IsRetryable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &fakeReview{errs: tt.errs}
+			c := newTestClient(t, startServer(t, srv), WithRetry(tt.retries, time.Millisecond))
+			var err error
+			if tt.write {
+				_, err = c.CreateReview(context.Background(), &v1.CreateReviewRequest{})
+			} else {
+				_, err = c.GetReview(context.Background(), &v1.GetReviewRequest{ReviewID: 1})
+			}
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("err = %v, want nil", err)
+			}
+			if tt.wantErr != nil && !tt.wantErr(err) {
+				t.Fatalf("err = %v", err)
+			}
+			if n := srv.calls.Load(); n != tt.calls {
+				t.Fatalf("calls = %d, want %d", n, tt.calls)
+			}
+		})
+	}
+}
+
+func TestRetryDeadline(t *testing.T) {
+	unavailable := errors.ServiceUnavailable("UNAVAILABLE", "instance is shutting down")
+	srv := &fakeReview{errs: []error{unavailable, unavailable, unavailable, unavailable, unavailable}}
+	// 等待100ms后重试一次，第二次需要等待200ms，超过了剩余的时间，不再重试
+	c := newTestClient(t, startServer(t, srv), WithTimeout(250*time.Millisecond), WithRetry(5, 100*time.Millisecond))
+	start := time.Now()
+	_, err := c.GetReview(context.Background(), &v1.GetReviewRequest{ReviewID: 1})
+	if !IsRetryable(err) {
+		t.Fatalf("err = %v, want the last retryable error", err)
+	}
+	if n := srv.calls.Load(); n != 2 {
+		t.Fatalf("calls = %d, want 2", n)
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Fatalf("took %v, longer than the timeout", elapsed)
+	}
+
+	// 调用方的context截止时间更早时以context为准
+	srv = &fakeReview{errs: []error{unavailable, unavailable}}
+	c = newTestClient(t, startServer(t, srv), WithRetry(2, 100*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.GetReview(ctx, &v1.GetReviewRequest{ReviewID: 1}); err == nil {
+		t.Fatal("err = nil, want unavailable")
+	}
+	if n := srv.calls.Load(); n != 1 {
+		t.Fatalf("calls = %d, want 1", n)
+	}
+}
+
+func TestIdempotent(t *testing.T) {
+	reads := []string{
+		v1.OperationReviewGetReview,
+		v1.OperationReviewListReviewByUserID,
+		v1.OperationReviewListReplyThread,
+		v1.OperationReviewGetRuntimeConfig,
+		v1.OperationReviewListRatingDimensions,
+		v1.OperationReviewGetReviewStats,
+		v1.OperationReviewGetStoreReviewDashboard,
+		v1.OperationReviewGetStoreComplaintKeywords,
+		v1.OperationReviewListStoreWebhooks,
+		v1.OperationReviewGetExportJob,
+	}
+	for _, op := range reads {
+		if !Idempotent(op) {
+			t.Errorf("Idempotent(%s) = false", op)
+		}
+	}
+	writes := []string{
+		v1.OperationReviewCreateReview,
+		v1.OperationReviewVoteReview,
+		v1.OperationReviewReplyReview,
+		v1.OperationReviewAppealReview,
+		v1.OperationReviewSaveStoreWebhook,
+		v1.OperationReviewTestStoreWebhook,
+		v1.OperationReviewExportReviews,
+		v1.OperationReviewImportReviews,
+	}
+	for _, op := range writes {
+		if Idempotent(op) {
+			t.Errorf("Idempotent(%s) = true", op)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	validator := errors.BadRequest("VALIDATOR", "invalid ReviewID")
+	tests := []struct {
+		name   string
+		err    error
+		reason string
+		code   int
+		is     func(error) bool
+	}{
+		{name: "nil", err: nil, code: 200},
+		{name: "not found", err: v1.ErrorNotFound("x"), reason: "NOT_FOUND", code: 404, is: IsNotFound},
+		{name: "forbidden", err: v1.ErrorForbidden("x"), reason: "FORBIDDEN", code: 403, is: IsForbidden},
+		{name: "invalid argument", err: v1.ErrorInvalidArgument("x"), reason: "INVALID_ARGUMENT", code: 400, is: IsInvalidArgument},
+		{name: "validator", err: validator, reason: "VALIDATOR", code: 400, is: IsInvalidArgument},
+		{name: "conflict", err: v1.ErrorConflict("x"), reason: "CONFLICT", code: 409, is: IsConflict},
+		{name: "rate limited", err: v1.ErrorRateLimited("x"), reason: "RATE_LIMITED", code: 429, is: IsRateLimited},
+		{name: "unavailable", err: errors.ServiceUnavailable("UNAVAILABLE", "x"), reason: "UNAVAILABLE", code: 503, is: IsRetryable},
+	}
+	helpers := []func(error) bool{IsNotFound, IsForbidden, IsInvalidArgument, IsConflict, IsRateLimited, IsRetryable}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if r := Reason(tt.err); r != tt.reason {
+				t.Errorf("Reason() = %q, want %q", r, tt.reason)
+			}
+			if c := Code(tt.err); c != tt.code {
+				t.Errorf("Code() = %d, want %d", c, tt.code)
+			}
+			// 只有对应的判断函数返回true
+			matched := 0
+			for _, is := range helpers {
+				if is(tt.err) {
+					matched++
+				}
+			}
+			if tt.is == nil && matched != 0 || tt.is != nil && (!tt.is(tt.err) || matched != 1) {
+				t.Errorf("helpers matched %d, want only the expected one", matched)
+			}
+		})
+	}
+}
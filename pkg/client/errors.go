@@ -0,0 +1,56 @@
+package client
+
+import (
+	"net/http"
+
+	v1 "review-service/api/review/v1"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// 错误判断
+// 评价服务返回的错误带有原因(reason)，调用方按原因判断，不要依赖错误信息的文本
+// 其它原因可以直接使用 v1.IsXxx 判断，如 v1.IsOrderReviewed
+
+// Reason 错误的原因 如 NOT_FOUND，不是评价服务返回的错误时为空
+func Reason(err error) string {
+	return errors.Reason(err)
+}
+
+// Code 错误对应的HTTP状态码 err为nil时为200
+func Code(err error) int {
+	return errors.Code(err)
+}
+
+// IsRetryable 是否是可以重试的错误 服务实例不可用或者连接失败
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.Code(err) == http.StatusServiceUnavailable
+}
+
+// IsNotFound 评价、回复等不存在
+func IsNotFound(err error) bool {
+	return v1.IsNotFound(err)
+}
+
+// IsForbidden 没有权限操作
+func IsForbidden(err error) bool {
+	return v1.IsForbidden(err)
+}
+
+// IsInvalidArgument 参数错误
+func IsInvalidArgument(err error) bool {
+	return v1.IsInvalidArgument(err) || errors.IsBadRequest(err) && Reason(err) == "VALIDATOR"
+}
+
+// IsConflict 并发修改冲突 重新查询后再试
+func IsConflict(err error) bool {
+	return v1.IsConflict(err)
+}
+
+// IsRateLimited 调用过于频繁
+func IsRateLimited(err error) bool {
+	return v1.IsRateLimited(err)
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "review-service/api/review/v1"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// idempotent 可以安全重试的接口 只读不写
+// 由接口的HTTP绑定得到，绑定为GET的都是查询接口，新增查询接口时不需要修改这里
+var idempotent = readOperations(v1.File_review_v1_review_proto)
+
+// readOperations 文件中HTTP绑定为GET的接口 key为gRPC的operation，如 /review.v1.Review/GetReview
+func readOperations(fd protoreflect.FileDescriptor) map[string]bool {
+	ret := make(map[string]bool)
+	services := fd.Services()
+	for i := 0; i < services.Len(); i++ {
+		sd := services.Get(i)
+		methods := sd.Methods()
+		for j := 0; j < methods.Len(); j++ {
+			md := methods.Get(j)
+			rule, ok := proto.GetExtension(md.Options(), annotations.E_Http).(*annotations.HttpRule)
+			if ok && rule.GetGet() != "" {
+				ret[fmt.Sprintf("/%s/%s", sd.FullName(), md.Name())] = true
+			}
+		}
+	}
+	return ret
+}
+
+// Idempotent 接口是否可以安全重试
+func Idempotent(operation string) bool {
+	return idempotent[operation]
+}
+
+// Retry 查询类接口在可重试的错误时按指数退避重试 不会超过调用的截止时间
+func Retry(retries int, backoff time.Duration) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			reply, err := handler(ctx, req)
+			if retries <= 0 || err == nil {
+				return reply, err
+			}
+			tr, ok := transport.FromClientContext(ctx)
+			if !ok || !Idempotent(tr.Operation()) {
+				return reply, err
+			}
+			wait := backoff
+			for i := 0; i < retries && IsRetryable(err); i++ {
+				if !sleep(ctx, wait) {
+					return reply, err
+				}
+				wait *= 2
+				reply, err = handler(ctx, req)
+			}
+			return reply, err
+		}
+	}
+}
+
+// sleep 等待d 等待期间context结束或者剩余时间不够时返回false
+func sleep(ctx context.Context, d time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= d {
+		return false
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
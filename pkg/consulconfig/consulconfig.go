@@ -0,0 +1,108 @@
+package consulconfig
+
+import (
+	"context"
+	"path"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/hashicorp/consul/api"
+)
+
+// consul KV 配置源
+// 读取consul KV中的一个key作为一份配置文件，配置格式由key的扩展名决定，如 review-service/config.yaml
+// 通过阻塞查询监听key的变化，key不存在时视为空配置
+
+// waitTime 一次阻塞查询最长等待的时间
+const waitTime = 5 * time.Minute
+
+type source struct {
+	client *api.Client
+	key    string
+	index  atomic.Uint64 // Load读到的consul索引 监听从这里开始，Load之后的变化不会丢失
+}
+
+// NewSource 创建consul KV配置源
+func NewSource(client *api.Client, key string) config.Source {
+	return &source{client: client, key: strings.TrimPrefix(key, "/")}
+}
+
+func (s *source) Load() ([]*config.KeyValue, error) {
+	kvs, index, err := s.get(context.Background(), 0)
+	if err != nil {
+		return nil, err
+	}
+	s.index.Store(index)
+	return kvs, nil
+}
+
+func (s *source) Watch() (config.Watcher, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &watcher{source: s, ctx: ctx, cancel: cancel, index: s.index.Load()}, nil
+}
+
+// get 读取key 返回consul的索引用于下一次阻塞查询
+func (s *source) get(ctx context.Context, index uint64) ([]*config.KeyValue, uint64, error) {
+	opts := (&api.QueryOptions{WaitIndex: index, WaitTime: waitTime}).WithContext(ctx)
+	pair, meta, err := s.client.KV().Get(s.key, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	if pair == nil {
+		return nil, meta.LastIndex, nil
+	}
+	return []*config.KeyValue{{
+		Key:    s.key,
+		Value:  pair.Value,
+		Format: strings.TrimPrefix(path.Ext(s.key), "."),
+	}}, meta.LastIndex, nil
+}
+
+type watcher struct {
+	source *source
+	ctx    context.Context
+	cancel context.CancelFunc
+	index  uint64
+}
+
+// Next 阻塞到key发生变化 查询失败时稍后重试
+func (w *watcher) Next() ([]*config.KeyValue, error) {
+	for {
+		kvs, index, err := w.source.get(w.ctx, w.index)
+		if w.ctx.Err() != nil {
+			return nil, w.ctx.Err()
+		}
+		if err != nil {
+			if !w.sleep(time.Second) {
+				return nil, w.ctx.Err()
+			}
+			continue
+		}
+		if index == w.index {
+			// 等待超时 没有变化
+			continue
+		}
+		// 没有Load过时第一次查询只记录索引；索引回退说明consul重建过数据，同样视为变化
+		first := w.index == 0
+		w.index = index
+		if !first {
+			return kvs, nil
+		}
+	}
+}
+
+func (w *watcher) sleep(d time.Duration) bool {
+	select {
+	case <-w.ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+func (w *watcher) Stop() error {
+	w.cancel()
+	return nil
+}
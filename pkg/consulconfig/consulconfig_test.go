@@ -0,0 +1,123 @@
+package consulconfig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+)
+
+// fakeKV 只支持读取一个key的consul KV 阻塞查询在索引不变时等待到超时
+type fakeKV struct {
+	mu    sync.Mutex
+	value string
+	index uint64
+}
+
+func (f *fakeKV) set(value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.value = value
+	f.index++
+}
+
+func (f *fakeKV) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	wait, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64)
+	deadline := time.Now().Add(200 * time.Millisecond)
+	for {
+		f.mu.Lock()
+		value, index := f.value, f.index
+		f.mu.Unlock()
+		if index != wait || time.Now().After(deadline) {
+			w.Header().Set("X-Consul-Index", strconv.FormatUint(index, 10))
+			_ = json.NewEncoder(w).Encode([]*api.KVPair{{Key: "review/config.yaml", Value: []byte(value), ModifyIndex: index}})
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func newTestSource(t *testing.T, kv *fakeKV) *source {
+	srv := httptest.NewServer(kv)
+	t.Cleanup(srv.Close)
+	client, err := api.NewClient(&api.Config{Address: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewSource(client, "/review/config.yaml").(*source)
+}
+
+// Load和第一次阻塞查询之间的变化不能丢失
+func TestWatchFromLoadIndex(t *testing.T) {
+	kv := &fakeKV{}
+	kv.set("a: 1")
+	s := newTestSource(t, kv)
+	kvs, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 1 || string(kvs[0].Value) != "a: 1" || kvs[0].Format != "yaml" {
+		t.Fatalf("Load() = %v", kvs)
+	}
+	kv.set("a: 2")
+
+	w, err := s.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		kvs, err = w.Next()
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Next() missed the change made after Load()")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 1 || string(kvs[0].Value) != "a: 2" {
+		t.Fatalf("Next() = %v, want a: 2", kvs)
+	}
+}
+
+// 没有变化时Next一直阻塞 Stop后返回
+func TestWatchStop(t *testing.T) {
+	kv := &fakeKV{}
+	kv.set("a: 1")
+	s := newTestSource(t, kv)
+	if _, err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+	w, err := s.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	errc := make(chan error, 1)
+	go func() {
+		_, err := w.Next()
+		errc <- err
+	}()
+	select {
+	case err := <-errc:
+		t.Fatalf("Next() returned %v without a change", err)
+	case <-time.After(500 * time.Millisecond):
+	}
+	_ = w.Stop()
+	select {
+	case err := <-errc:
+		if err == nil {
+			t.Fatal("Next() after Stop() returned nil error")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Next() did not return after Stop()")
+	}
+}
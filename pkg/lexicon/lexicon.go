@@ -0,0 +1,283 @@
+// Package lexicon 基于词典的评价内容分析 离线运行，不依赖外部服务
+//
+// 分词使用 gse (结巴分词的Go实现) 和它内置的简体中文词典，英文按单词切分并转成小写
+// 情感: 按内置的情感词典给分词结果打分，否定词翻转极性，程度副词调整权重，转折词之后的内容权重更高，
+// 总分归一化到 (-1, 1)，大于0为正面，小于0为负面
+// 关键词: 名词、动词和形容词按 TF-IDF 排序，IDF 用分词词典中的词频估算
+//
+// 词典在第一次分析时加载，耗时约1秒，占用约100MB内存
+package lexicon
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-ego/gse"
+)
+
+const (
+	negationScale  = 0.75 // 否定后的权重 "不好吃"没有"难吃"那么负面
+	contrastBefore = 0.5  // 转折词之前内容的权重
+	contrastAfter  = 1.5  // 转折词之后内容的权重
+	modifierWindow = 3    // 否定词和程度副词最多作用到后面第几个词
+	normalizeAlpha = 15   // 归一化参数 score = sum / sqrt(sum^2 + alpha)
+	minKeywordLen  = 2    // 中文关键词的最少字数
+	minEnglishLen  = 3    // 英文关键词的最少字母数
+)
+
+// n't 在分词时会被拆开，先替换成 not
+var contractionRe = regexp.MustCompile(`(?i)n[’']t\b`)
+
+// Result 分析结果
+type Result struct {
+	Score    float64  // 情感分 (-1, 1)
+	Keywords []string // 按权重从高到低
+}
+
+// Analyzer 内容分析器 并发安全
+type Analyzer struct {
+	once        sync.Once
+	seg         gse.Segmenter
+	err         error
+	maxKeywords int
+}
+
+// New 创建分析器 maxKeywords为每条内容最多提取的关键词数
+func New(maxKeywords int) *Analyzer {
+	return &Analyzer{maxKeywords: maxKeywords}
+}
+
+// load 加载分词词典和停用词
+func (a *Analyzer) load() error {
+	a.once.Do(func() {
+		if a.err = a.seg.LoadDictEmbed("zh_s"); a.err != nil {
+			return
+		}
+		a.err = a.seg.LoadStopEmbed()
+	})
+	return a.err
+}
+
+// Analyze 分析一段内容的情感和关键词
+func (a *Analyzer) Analyze(text string) (*Result, error) {
+	if err := a.load(); err != nil {
+		return nil, err
+	}
+	text = contractionRe.ReplaceAllString(text, " not")
+	var words []gse.SegPos
+	for _, w := range a.seg.Pos(text, false) {
+		w.Text = strings.TrimSpace(w.Text)
+		if w.Text != "" {
+			words = append(words, w)
+		}
+	}
+	return &Result{
+		Score:    normalize(score(words)),
+		Keywords: a.keywords(words),
+	}, nil
+}
+
+// score 情感总分 标点符号结束一个分句，分句结束时否定词和程度副词不再生效
+func score(words []gse.SegPos) float64 {
+	var (
+		total  float64
+		weight = 1.0 // 当前内容的权重 遇到转折词后变大
+		negate bool
+		boost  = 1.0
+		dist   int // 距离上一个否定词或程度副词的词数
+	)
+	reset := func() {
+		negate, boost, dist = false, 1, 0
+	}
+	for _, w := range words {
+		if isPunct(w.Text) {
+			reset()
+			continue
+		}
+		if _, ok := contrasts[w.Text]; ok {
+			total *= contrastBefore
+			weight = contrastAfter
+			reset()
+			continue
+		}
+		if _, ok := negations[w.Text]; ok {
+			negate = !negate
+			dist = 0
+			continue
+		}
+		if b, ok := intensifiers[w.Text]; ok {
+			boost *= b
+			dist = 0
+			continue
+		}
+		p, neg, b := polarity(w.Text)
+		if p == 0 {
+			if dist++; dist >= modifierWindow {
+				reset()
+			}
+			continue
+		}
+		s := p * boost * b
+		if negate != neg {
+			s = -s * negationScale
+		}
+		total += s * weight
+		reset()
+	}
+	return total
+}
+
+// polarity 词的情感权重 正面为正数，负面为负数，不是情感词时返回0
+// 不在词典中的词去掉开头的否定词、程度副词和无关副词后再查，返回去掉的前缀中是否有否定和程度
+func polarity(word string) (p float64, negate bool, boost float64) {
+	boost = 1
+	for word != "" {
+		if v, ok := positiveWords[word]; ok {
+			return v, negate, boost
+		}
+		if v, ok := negativeWords[word]; ok {
+			return -v, negate, boost
+		}
+		rest, neg, b := trimModifier(word)
+		if rest == word {
+			break
+		}
+		word = rest
+		negate = negate != neg
+		boost *= b
+	}
+	return 0, false, 1
+}
+
+// trimModifier 去掉一个开头的修饰词 优先匹配较长的修饰词，如 不太 优先于 不，没有修饰词时原样返回
+func trimModifier(word string) (string, bool, float64) {
+	for i := len(word) - 1; i > 0; i-- {
+		if !utf8.RuneStart(word[i]) {
+			continue
+		}
+		prefix, rest := word[:i], word[i:]
+		if _, ok := negations[prefix]; ok {
+			return rest, true, 1
+		}
+		if b, ok := intensifiers[prefix]; ok {
+			return rest, false, b
+		}
+		for _, f := range fillers {
+			if prefix == f {
+				return rest, false, 1
+			}
+		}
+	}
+	return word, false, 1
+}
+
+// stem 情感词去掉开头的程度副词和无关副词 如 太慢 -> 慢，否定词保留
+func stem(word string) string {
+	for !isSentiment(word) {
+		rest, neg, _ := trimModifier(word)
+		if rest == word || neg {
+			return word
+		}
+		if p, _, _ := polarity(rest); p == 0 {
+			return word
+		}
+		word = rest
+	}
+	return word
+}
+
+func isSentiment(word string) bool {
+	_, pos := positiveWords[word]
+	_, neg := negativeWords[word]
+	return pos || neg
+}
+
+func normalize(sum float64) float64 {
+	return sum / math.Sqrt(sum*sum+normalizeAlpha)
+}
+
+// keywords 按 TF-IDF 取权重最高的关键词
+func (a *Analyzer) keywords(words []gse.SegPos) []string {
+	tf := make(map[string]int)
+	var order []string
+	for _, w := range words {
+		if !a.isKeyword(w) {
+			continue
+		}
+		word := stem(w.Text)
+		if tf[word] == 0 {
+			order = append(order, word)
+		}
+		tf[word]++
+	}
+	total := a.seg.Dict.TotalFreq()
+	weights := make(map[string]float64, len(tf))
+	for word, n := range tf {
+		freq, _, ok := a.seg.Find(word)
+		if !ok || freq < 1 {
+			freq = 1
+		}
+		weights[word] = float64(n) * math.Log(total/freq)
+	}
+	// 权重相同时按出现的先后顺序
+	sort.SliceStable(order, func(i, j int) bool {
+		return weights[order[i]] > weights[order[j]]
+	})
+	if len(order) > a.maxKeywords {
+		order = order[:a.maxKeywords]
+	}
+	return order
+}
+
+// isKeyword 只保留名词、动词、形容词和英文单词 去掉停用词、修饰词和太短的词
+// 情感词不论词性都保留
+func (a *Analyzer) isKeyword(w gse.SegPos) bool {
+	if a.seg.IsStop(w.Text) || isPunct(w.Text) {
+		return false
+	}
+	if _, ok := negations[w.Text]; ok {
+		return false
+	}
+	if _, ok := intensifiers[w.Text]; ok {
+		return false
+	}
+	if isEnglish(w.Text) {
+		_, stop := englishStopWords[w.Text]
+		return !stop && len(w.Text) >= minEnglishLen
+	}
+	// 单字的情感词也保留 如 慢、凉、贵
+	if utf8.RuneCountInString(stem(w.Text)) < minKeywordLen && !isSentiment(stem(w.Text)) {
+		return false
+	}
+	switch {
+	case isSentiment(stem(w.Text)):
+		return true
+	case strings.HasPrefix(w.Pos, "n"), strings.HasPrefix(w.Pos, "v"),
+		strings.HasPrefix(w.Pos, "a"), w.Pos == "l":
+		return true
+	}
+	return false
+}
+
+func isPunct(s string) bool {
+	for _, r := range s {
+		if !unicode.IsPunct(r) && !unicode.IsSymbol(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isEnglish(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII || !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
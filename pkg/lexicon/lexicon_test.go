@@ -0,0 +1,138 @@
+package lexicon
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/go-ego/gse"
+)
+
+// segs 按空格切分的分词结果 用来测试打分规则，不加载词典
+func segs(text string) []gse.SegPos {
+	var words []gse.SegPos
+	for _, w := range strings.Fields(text) {
+		words = append(words, gse.SegPos{Text: w})
+	}
+	return words
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name  string
+		words string
+		want  float64
+	}{
+		{name: "positive", words: "好吃", want: 2},
+		{name: "negative", words: "难吃", want: -2.5},
+		{name: "not sentiment", words: "外卖 米饭", want: 0},
+		{name: "negation", words: "不 好吃", want: -1.5},
+		{name: "negated negative", words: "不 难吃", want: 1.875},
+		{name: "double negation", words: "不 不 好吃", want: 2},
+		{name: "intensifier", words: "很 好吃", want: 3},
+		{name: "weakener", words: "有点 慢", want: -1.05},
+		{name: "prefix in word", words: "太慢", want: -2.7},
+		{name: "negation prefix in word", words: "不新鲜", want: -1.125},
+		{name: "filler prefix in word", words: "都好吃", want: 2},
+		{name: "contrast", words: "好吃 但是 慢", want: 2*contrastBefore - 1.5*contrastAfter},
+		{name: "punctuation ends negation", words: "不 ， 好吃", want: 2},
+		{name: "negation within window", words: "不 是 很 好吃", want: -2.25},
+		{name: "negation out of window", words: "不 外卖 米饭 送餐 好吃", want: 2},
+		{name: "english", words: "not good", want: -0.75},
+		{name: "sum", words: "好吃 ， 满意", want: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := score(segs(tt.words)); math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("score(%q) = %v, want %v", tt.words, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got := normalize(0); got != 0 {
+		t.Fatalf("normalize(0) = %v, want 0", got)
+	}
+	prev := 0.0
+	for _, sum := range []float64{0.5, 1, 2, 5, 10, 100} {
+		got := normalize(sum)
+		if got <= prev || got >= 1 {
+			t.Fatalf("normalize(%v) = %v, want in (%v, 1)", sum, got, prev)
+		}
+		if normalize(-sum) != -got {
+			t.Fatalf("normalize(%v) = %v, want %v", -sum, normalize(-sum), -got)
+		}
+		prev = got
+	}
+}
+
+func TestStem(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"慢", "慢"},
+		{"太慢", "慢"},
+		{"都好吃", "好吃"},
+		{"不新鲜", "不新鲜"}, // 否定词保留
+		{"外卖", "外卖"},
+	}
+	for _, tt := range tests {
+		if got := stem(tt.word); got != tt.want {
+			t.Fatalf("stem(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	if testing.Short() {
+		t.Skip("loads the segment dictionary")
+	}
+	a := New(5)
+	tests := []struct {
+		text    string
+		sign    int
+		keyword string
+	}{
+		{text: "味道很好吃，送餐也很快，非常满意", sign: 1},
+		{text: "送餐太慢了，饭菜都凉了，很失望", sign: -1, keyword: "慢"},
+		{text: "包装还行，但是味道很难吃", sign: -1, keyword: "难吃"},
+		{text: "The food was great and delivery was fast", sign: 1, keyword: "great"},
+		{text: "The food wasn't good", sign: -1},
+		{text: "今天中午点的外卖", sign: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			ret, err := a.Analyze(tt.text)
+			if err != nil {
+				t.Fatalf("Analyze: %v", err)
+			}
+			if sign(ret.Score) != tt.sign {
+				t.Fatalf("Score = %v, want sign %d", ret.Score, tt.sign)
+			}
+			if len(ret.Keywords) > 5 {
+				t.Fatalf("got %d keywords, want at most 5", len(ret.Keywords))
+			}
+			if tt.keyword == "" {
+				return
+			}
+			for _, k := range ret.Keywords {
+				if k == tt.keyword {
+					return
+				}
+			}
+			t.Fatalf("Keywords = %v, want %q", ret.Keywords, tt.keyword)
+		})
+	}
+}
+
+func sign(score float64) int {
+	switch {
+	case score > 0:
+		return 1
+	case score < 0:
+		return -1
+	}
+	return 0
+}
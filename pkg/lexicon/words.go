@@ -0,0 +1,79 @@
+package lexicon
+
+// 内置词典 覆盖外卖、电商评价中常见的表达，词的权重为1~3
+// 分词结果中的词不在词典里时，会去掉开头的否定词、程度副词后再查一次，如 太慢 -> 慢，不新鲜 -> 新鲜
+
+// positiveWords 正面情感词
+var positiveWords = map[string]float64{
+	// 中文
+	"好": 1, "好吃": 2, "美味": 2, "可口": 2, "香": 1, "新鲜": 1.5, "满意": 2, "喜欢": 2, "不错": 1.5,
+	"推荐": 1.5, "赞": 2, "点赞": 2, "棒": 2, "优秀": 2, "完美": 3, "惊喜": 2, "值得": 1.5,
+	"实惠": 1.5, "划算": 1.5, "超值": 2, "便宜": 1, "物美价廉": 2, "性价比高": 2, "干净": 1.5, "卫生": 1,
+	"热情": 1.5, "周到": 1.5, "贴心": 1.5, "耐心": 1.5, "礼貌": 1, "专业": 1, "及时": 1, "准时": 1.5,
+	"快": 1, "迅速": 1.5, "神速": 2, "给力": 2, "舒服": 1.5, "漂亮": 1.5, "好看": 1.5, "精致": 1.5,
+	"方便": 1, "好用": 1.5, "耐用": 1.5, "结实": 1, "正品": 1.5, "放心": 1.5, "好评": 2, "五星": 2,
+	"回购": 1.5, "还会再来": 2, "分量足": 1.5, "量大": 1, "地道": 1.5, "正宗": 1.5, "开心": 1.5, "愉快": 1.5,
+	"感谢": 1, "谢谢": 1, "完好": 1, "完好无损": 1.5, "喜爱": 2, "优质": 1.5, "靠谱": 1.5,
+	// 英文
+	"good": 1, "great": 2, "excellent": 3, "love": 2, "loved": 2, "like": 1, "liked": 1, "nice": 1.5,
+	"fresh": 1.5, "fast": 1, "quick": 1, "recommend": 1.5, "recommended": 1.5, "perfect": 3, "delicious": 2,
+	"tasty": 2, "friendly": 1.5, "clean": 1.5, "happy": 1.5, "satisfied": 2, "awesome": 2.5, "amazing": 2.5,
+	"best": 2, "worth": 1.5, "helpful": 1.5, "polite": 1, "cheap": 1, "fantastic": 2.5, "wonderful": 2.5,
+	"enjoy": 1.5, "enjoyed": 1.5, "thanks": 1, "thank": 1,
+}
+
+// negativeWords 负面情感词
+var negativeWords = map[string]float64{
+	// 中文
+	"差": 2, "差劲": 2.5, "差评": 2.5, "难吃": 2.5, "难喝": 2.5, "失望": 2, "糟糕": 2.5, "垃圾": 3, "恶心": 3,
+	"慢": 1.5, "晚": 1, "迟到": 1.5, "延迟": 1.5, "超时": 1.5, "坏": 1.5, "坏了": 2, "破": 1.5,
+	"破损": 2, "损坏": 2, "漏": 1.5, "洒": 1.5, "撒": 1, "脏": 2, "不卫生": 2.5, "贵": 1, "凉": 1, "冷": 1,
+	"少": 1, "分量少": 1.5, "咸": 1, "淡": 0.5, "油腻": 1.5, "腥": 1.5, "臭": 2, "异味": 2, "发霉": 3,
+	"过期": 3, "变质": 3, "馊": 3, "生": 1, "硬": 1, "缺": 1.5, "少送": 2, "漏送": 2, "送错": 2, "错": 1,
+	"退款": 1.5, "退货": 1.5, "投诉": 2, "骗": 2.5, "骗子": 3, "坑": 2, "后悔": 2, "敷衍": 2, "冷漠": 2,
+	"态度差": 2.5, "不理": 1.5, "不好": 1.5, "一般": 0.5, "一般般": 0.5, "难用": 2, "假货": 3, "掉色": 1.5, "起球": 1.5,
+	"劣质": 2.5, "粗糙": 1.5, "不值": 1.5, "浪费": 1.5, "问题": 1, "毛病": 1.5, "生气": 2, "无语": 2,
+	"离谱": 2, "坑人": 2.5, "再也不": 2, "别买": 2.5, "拉黑": 2.5, "拉肚子": 3, "吃坏": 3,
+	// 英文
+	"bad": 2, "terrible": 3, "awful": 3, "poor": 2, "slow": 1.5, "disappointed": 2, "disappointing": 2,
+	"worst": 3, "dirty": 2, "cold": 1, "late": 1.5, "broken": 2, "damaged": 2, "refund": 1.5, "rude": 2.5,
+	"expensive": 1, "stale": 2, "wrong": 1.5, "missing": 1.5, "hate": 2.5, "horrible": 3, "disgusting": 3,
+	"overpriced": 1.5, "waste": 2, "complaint": 1.5, "problem": 1, "soggy": 1.5, "bland": 1,
+}
+
+// negations 否定词 翻转后面情感词的极性
+var negations = map[string]struct{}{
+	"不": {}, "没": {}, "没有": {}, "无": {}, "未": {}, "别": {}, "非": {}, "并不": {}, "并没有": {},
+	"不太": {}, "不够": {}, "不是": {}, "毫无": {}, "绝不": {},
+	"not": {}, "no": {}, "nor": {}, "never": {}, "hardly": {}, "without": {}, "isnt": {}, "wasnt": {},
+}
+
+// intensifiers 程度副词 放大或减弱后面情感词的权重
+var intensifiers = map[string]float64{
+	"很": 1.5, "非常": 1.8, "太": 1.8, "特别": 1.8, "超": 1.8, "超级": 2, "十分": 1.8, "极其": 2, "极": 2,
+	"真": 1.5, "真的": 1.5, "挺": 1.2, "蛮": 1.2, "最": 2, "巨": 2, "贼": 1.8, "相当": 1.5,
+	"有点": 0.7, "有些": 0.7, "稍微": 0.6, "略": 0.6, "比较": 0.8, "还": 0.8,
+	"very": 1.5, "really": 1.5, "so": 1.5, "extremely": 2, "too": 1.5, "super": 1.8, "quite": 1.2,
+	"totally": 1.8, "absolutely": 2, "slightly": 0.6, "somewhat": 0.7, "bit": 0.7,
+}
+
+// fillers 不影响情感的副词 去掉后再查词典，如 都凉 -> 凉
+var fillers = []string{"都", "也", "就", "又", "已经", "还是"}
+
+// contrasts 转折词 转折后的内容权重更高，之前的内容权重减半
+var contrasts = map[string]struct{}{
+	"但": {}, "但是": {}, "可是": {}, "不过": {}, "然而": {}, "就是": {}, "可惜": {},
+	"but": {}, "however": {}, "although": {},
+}
+
+// englishStopWords 英文停用词 gse的停用词表只有中文
+var englishStopWords = map[string]struct{}{
+	"a": {}, "an": {}, "the": {}, "and": {}, "or": {}, "of": {}, "to": {}, "in": {}, "on": {}, "at": {},
+	"for": {}, "with": {}, "is": {}, "are": {}, "was": {}, "were": {}, "be": {}, "been": {}, "it": {},
+	"its": {}, "this": {}, "that": {}, "these": {}, "those": {}, "i": {}, "we": {}, "you": {}, "they": {},
+	"he": {}, "she": {}, "my": {}, "our": {}, "your": {}, "their": {}, "me": {}, "us": {}, "them": {},
+	"do": {}, "does": {}, "did": {}, "have": {}, "has": {}, "had": {}, "will": {}, "would": {}, "can": {},
+	"could": {}, "should": {}, "again": {}, "just": {}, "also": {}, "there": {}, "here": {}, "from": {},
+	"as": {}, "by": {}, "if": {}, "then": {}, "than": {}, "all": {}, "any": {}, "some": {}, "get": {},
+	"got": {}, "order": {}, "ordered": {}, "one": {}, "wo": {}, "ca": {},
+}
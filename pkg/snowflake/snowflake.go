@@ -2,36 +2,236 @@ package snowflake
 
 import (
 	"errors"
+	"fmt"
+	"sync"
 	"time"
-
-	sf "github.com/bwmarrin/snowflake"
 )
 
 // 雪花算法生成id
 // 本质上是为了获取一个全局唯一的ID
+// ID:     符号位(1) | 时间戳毫秒(41) | 机器ID(10) | 序列号(12-SlotBits) | 0(SlotBits)
+// 分片ID: 符号位(1) | 时间戳毫秒(41) | 机器ID(10) | 序列号(12-SlotBits) | 槽位(SlotBits)
+// 两种ID共用一个序列号，同一毫秒内序列号不同，不会重复；每毫秒每台机器一共可以生成 2^(12-SlotBits) 个ID
+
+const (
+	TimeBits    = 41
+	MachineBits = 10
+	StepBits    = 12
+	// SlotBits 分片ID低位保存的槽位位数
+	SlotBits = 6
+	// SlotCount 槽位数 分表数不能超过槽位数
+	SlotCount = 1 << SlotBits
+	// MaxMachineID 最大的机器ID
+	MaxMachineID = 1<<MachineBits - 1
+
+	timeShift = MachineBits + StepBits
+	maxTime   = 1<<TimeBits - 1
+)
+
 var (
 	InvalidInitParamErr  = errors.New("snowflake初始化失败,无效的startTime或machineID")
 	InvalidTimeFormatErr = errors.New("snowflake初始化失败,无效的startTime格式")
+	// ErrClockBackwards 时钟回拨
+	ErrClockBackwards = errors.New("snowflake: clock moved backwards")
+	// ErrTimeOverflow 时间戳超出41位
+	ErrTimeOverflow = errors.New("snowflake: timestamp overflow")
 )
 
-var node *sf.Node
+// IDGenerator 生成全局唯一的ID
+type IDGenerator interface {
+	// NextID 生成一个ID
+	NextID() (int64, error)
+	// NextSlotID 生成一个低位带有槽位的ID
+	NextSlotID(slot int64) (int64, error)
+}
+
+// ClockPolicy 发现时钟回拨时的处理方式
+type ClockPolicy int
 
-// Init 雪花算法初始化配置
-func Init(startTime string, machineID int64) (err error) {
-	if len(startTime) == 0 || machineID <= 0 {
-		return InvalidInitParamErr
+const (
+	// ClockWait 等待时钟追上 回拨超过最长等待时间时返回错误
+	ClockWait ClockPolicy = iota
+	// ClockFail 直接返回错误
+	ClockFail
+)
+
+// Option 生成器的选项
+type Option func(*Generator)
+
+// WithClockPolicy 时钟回拨的处理方式 maxWait为ClockWait时最长等待的时间
+func WithClockPolicy(policy ClockPolicy, maxWait time.Duration) Option {
+	return func(g *Generator) {
+		g.policy = policy
+		g.maxWait = maxWait
 	}
-	var st time.Time
-	st, err = time.Parse("2006-01-02", startTime)
+}
+
+// WithLastTime 该机器ID上次生成ID的时间 重启后时钟比这个时间早时视为时钟回拨
+func WithLastTime(t time.Time) Option {
+	return func(g *Generator) {
+		last := t.Sub(g.epoch).Milliseconds()
+		// 序列号置为最大值，同一毫秒内的下一个ID会等到下一毫秒再生成
+		g.seq.last, g.seq.step = last, g.seq.mask
+	}
+}
+
+// sequence 毫秒内的序列号
+type sequence struct {
+	last int64 // 上次生成ID的时间戳
+	step int64
+	mask int64
+}
+
+// Generator 雪花算法ID生成器 并发安全
+type Generator struct {
+	mu        sync.Mutex
+	epoch     time.Time
+	machineID int64
+	policy    ClockPolicy
+	maxWait   time.Duration
+	seq       sequence // 普通ID和分片ID共用
+	err       error    // 不为空时不再生成ID，比如机器ID的租约已经失效
+}
+
+// NewGenerator 创建ID生成器 startTime为起始日期 格式 2006-01-02
+func NewGenerator(startTime string, machineID int64, opts ...Option) (*Generator, error) {
+	if len(startTime) == 0 || machineID < 0 || machineID > MaxMachineID {
+		return nil, InvalidInitParamErr
+	}
+	epoch, err := ParseEpoch(startTime)
+	if err != nil {
+		return nil, err
+	}
+	g := &Generator{
+		epoch:     epoch,
+		machineID: machineID,
+		maxWait:   time.Second,
+		seq:       sequence{mask: 1<<(StepBits-SlotBits) - 1},
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g, nil
+}
+
+// ParseEpoch 解析起始日期
+func ParseEpoch(startTime string) (time.Time, error) {
+	st, err := time.Parse("2006-01-02", startTime)
 	if err != nil {
-		return InvalidTimeFormatErr
+		return time.Time{}, InvalidTimeFormatErr
 	}
-	sf.Epoch = st.UnixNano() / 1000000
-	node, err = sf.NewNode(machineID)
-	return
+	return st, nil
+}
+
+// MachineID 生成器的机器ID
+func (g *Generator) MachineID() int64 {
+	return g.machineID
+}
+
+// NextID 生成一个ID 槽位的位置为0
+func (g *Generator) NextID() (int64, error) {
+	return g.NextSlotID(0)
+}
+
+// NextSlotID 生成一个低位带有槽位的ID
+func (g *Generator) NextSlotID(slot int64) (int64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	ts, step, err := g.next()
+	if err != nil {
+		return 0, err
+	}
+	return ts<<timeShift | g.machineID<<StepBits | step<<SlotBits | slot&(SlotCount-1), nil
+}
+
+// LastTime 最后一次生成ID的时间
+func (g *Generator) LastTime() time.Time {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.epoch.Add(time.Duration(g.seq.last) * time.Millisecond)
+}
+
+// Disable 停止生成ID 之后生成ID时返回err
+func (g *Generator) Disable(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.err = err
+}
+
+// Enable 恢复生成ID
+func (g *Generator) Enable() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.err = nil
+}
+
+// next 取下一个时间戳和序列号 调用方持有锁
+// 时钟回拨时按策略等待或返回错误，等待期间持有锁，其它生成ID的调用也一起等待
+func (g *Generator) next() (int64, int64, error) {
+	s := &g.seq
+	if g.err != nil {
+		return 0, 0, g.err
+	}
+	now := g.millis()
+	if now < s.last {
+		backwards := time.Duration(s.last-now) * time.Millisecond
+		if g.policy == ClockFail || backwards > g.maxWait {
+			return 0, 0, fmt.Errorf("%w by %v", ErrClockBackwards, backwards)
+		}
+		time.Sleep(backwards)
+		if now = g.millis(); now < s.last {
+			return 0, 0, fmt.Errorf("%w by %v", ErrClockBackwards, time.Duration(s.last-now)*time.Millisecond)
+		}
+	}
+	if now > maxTime {
+		return 0, 0, ErrTimeOverflow
+	}
+	if now == s.last {
+		s.step = (s.step + 1) & s.mask
+		// 当前毫秒的序列号用完了，等到下一毫秒
+		for s.step == 0 && now <= s.last {
+			time.Sleep(100 * time.Microsecond)
+			now = g.millis()
+		}
+	} else {
+		s.step = 0
+	}
+	s.last = now
+	return now, s.step, nil
+}
+
+func (g *Generator) millis() int64 {
+	return time.Since(g.epoch).Milliseconds()
+}
+
+// ID 解析后的ID 用于排查问题
+type ID struct {
+	Time      time.Time
+	MachineID int64
+	Step      int64 // 低12位 包含槽位
+	Slot      int64 // 低SlotBits位 只对分片ID有意义
+}
+
+func (id ID) String() string {
+	return fmt.Sprintf("time=%s machine=%d step=%d slot=%d", id.Time.Format(time.RFC3339Nano), id.MachineID, id.Step, id.Slot)
+}
+
+// Decode 按起始时间解析ID
+func Decode(id int64, epoch time.Time) ID {
+	return ID{
+		Time:      epoch.Add(time.Duration(id>>timeShift) * time.Millisecond),
+		MachineID: id >> StepBits & MaxMachineID,
+		Step:      id & (1<<StepBits - 1),
+		Slot:      SlotOf(id),
+	}
+}
+
+// Decode 解析ID
+func (g *Generator) Decode(id int64) ID {
+	return Decode(id, g.epoch)
 }
 
-// GenID 生成一个ID
-func GenID() int64 {
-	return node.Generate().Int64()
+// SlotOf 取出ID中的槽位
+func SlotOf(id int64) int64 {
+	return id & (SlotCount - 1)
 }
@@ -0,0 +1,34 @@
+package snowflake
+
+import "testing"
+
+// 普通ID和分片ID交替生成时不能重复，分片ID中的槽位保持不变
+func TestNextIDAndSlotIDUnique(t *testing.T) {
+	g, err := NewGenerator("2024-01-01", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[int64]bool)
+	for i := 0; i < 10000; i++ {
+		var (
+			id   int64
+			slot int64
+		)
+		if i%2 == 0 {
+			id, err = g.NextID()
+		} else {
+			slot = int64(i) % SlotCount
+			id, err = g.NextSlotID(slot)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate id %d (%s)", id, g.Decode(id))
+		}
+		seen[id] = true
+		if d := g.Decode(id); d.MachineID != 3 || d.Slot != slot {
+			t.Fatalf("Decode(%d) = %s, want machine=3 slot=%d", id, d, slot)
+		}
+	}
+}
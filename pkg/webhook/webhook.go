@@ -0,0 +1,101 @@
+// Package webhook 评价服务推送给商家的webhook通知
+// 商家接收通知时用 Verify 校验签名，或者参考 cmd/webhook-receiver 的实现
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 推送请求的HTTP头
+const (
+	HeaderEvent     = "X-Review-Event"     // 事件类型
+	HeaderDelivery  = "X-Review-Delivery"  // 事件id 重试时不变，接收方可以用来去重
+	HeaderTimestamp = "X-Review-Timestamp" // 签名时间 unix秒
+	HeaderSignature = "X-Review-Signature" // 签名 sha256=<hex>
+)
+
+// 事件类型
+const (
+	EventReviewCreated  = "review.created"  // 用户提交了差评
+	EventReviewApproved = "review.approved" // 差评审核通过 对用户可见
+	EventPing           = "ping"            // 测试推送
+)
+
+// DefaultTolerance 签名时间与当前时间允许的最大误差 超过时视为重放的请求
+const DefaultTolerance = 5 * time.Minute
+
+var (
+	ErrMissingSignature = errors.New("webhook: missing signature")
+	ErrInvalidSignature = errors.New("webhook: invalid signature")
+	ErrExpired          = errors.New("webhook: timestamp out of tolerance")
+)
+
+// Event 推送的内容 请求体是 Event 的JSON
+type Event struct {
+	ID        string  `json:"id"`
+	Type      string  `json:"type"`
+	StoreID   int64   `json:"store_id"`
+	CreatedAt string  `json:"created_at"` // 2006-01-02 15:04:05
+	Review    *Review `json:"review,omitempty"`
+}
+
+// Review 事件中的评价信息
+type Review struct {
+	ReviewID     int64  `json:"review_id"`
+	OrderID      int64  `json:"order_id,omitempty"` // 匿名评价不返回
+	UserID       int64  `json:"user_id,omitempty"`  // 匿名评价不返回
+	Score        int32  `json:"score"`
+	ServiceScore int32  `json:"service_score"`
+	ExpressScore int32  `json:"express_score"`
+	Content      string `json:"content"`
+	Status       int32  `json:"status"`
+	CreateAt     string `json:"create_at"`
+}
+
+// Sign 计算签名 HMAC-SHA256(secret, "<timestamp>.<body>")
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SetHeaders 设置推送请求的HTTP头并签名
+func SetHeaders(h http.Header, secret, event, deliveryID string, now time.Time, body []byte) {
+	ts := now.Unix()
+	h.Set("Content-Type", "application/json")
+	h.Set(HeaderEvent, event)
+	h.Set(HeaderDelivery, deliveryID)
+	h.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	h.Set(HeaderSignature, Sign(secret, ts, body))
+}
+
+// Verify 校验推送请求的签名 tolerance为0时使用 DefaultTolerance
+func Verify(secret string, h http.Header, body []byte, tolerance time.Duration) error {
+	sig := h.Get(HeaderSignature)
+	if sig == "" || !strings.HasPrefix(sig, "sha256=") {
+		return ErrMissingSignature
+	}
+	ts, err := strconv.ParseInt(h.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return ErrMissingSignature
+	}
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	if d := time.Since(time.Unix(ts, 0)); d > tolerance || d < -tolerance {
+		return ErrExpired
+	}
+	if !hmac.Equal([]byte(sig), []byte(Sign(secret, ts, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}